@@ -0,0 +1,94 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// accessibleMode 为 true 时界面只用黑白两色，不靠颜色传达信息（--accessible 或配置中的 accessible）
+var accessibleMode bool
+
+// selectedRowMarker 无障碍模式下单词列表选中行前的文字标记，不只靠选中背景色区分
+const selectedRowMarker = "> "
+
+var (
+	markedRow  = -1 // 单词列表中加了选中标记的行
+	markedText string
+)
+
+// monochromeScreen 把所有绘制的样式换成白字黑底，保留粗体、下划线等属性，以获得最高的对比度
+//
+// 界面中用颜色标记的内容本身都带有文字或符号（栏目名、★ ♥ ◇ 等），去掉颜色后信息不丢失；
+// 原本有背景色的地方（选中行、词性徽标、详情中选中的链接）改为反色显示，仍然醒目
+type monochromeScreen struct {
+	tcell.Screen
+}
+
+// monochromeStyle 把样式换成白字黑底，有背景色时反色
+func monochromeStyle(style tcell.Style) tcell.Style {
+	_, bg, attrs := style.Decompose()
+	if bg != tcell.ColorDefault && bg != tcell.ColorBlack {
+		attrs |= tcell.AttrReverse
+	}
+	return tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorBlack).Attributes(attrs)
+}
+
+// SetContent 以黑白样式绘制一个格子
+func (s *monochromeScreen) SetContent(x, y int, primary rune, combining []rune, style tcell.Style) {
+	s.Screen.SetContent(x, y, primary, combining, monochromeStyle(style))
+}
+
+// SetCell 以黑白样式绘制一个格子
+func (s *monochromeScreen) SetCell(x, y int, style tcell.Style, ch ...rune) {
+	s.Screen.SetCell(x, y, monochromeStyle(style), ch...)
+}
+
+// Fill 以黑白样式填充整个屏幕
+func (s *monochromeScreen) Fill(r rune, style tcell.Style) {
+	s.Screen.Fill(r, monochromeStyle(style))
+}
+
+// SetStyle 把默认样式设为黑白
+func (s *monochromeScreen) SetStyle(style tcell.Style) {
+	s.Screen.SetStyle(monochromeStyle(style))
+}
+
+// newAccessibleScreen 创建无障碍模式使用的黑白屏幕
+func newAccessibleScreen() (tcell.Screen, error) {
+	screen, err := tcell.NewScreen()
+	if err != nil {
+		return nil, err
+	}
+	return &monochromeScreen{screen}, nil
+}
+
+// updateSelectedMarker 在单词列表的选中行前加上 selectedRowMarker，并去掉上一个选中行的标记
+//
+// 在每次绘制前调用，列表重建、换段、跳到首尾等各种改变选中项的方式都会更新；
+// 某一行的文本已被其他代码替换（如 showPlainRow）时，说明它已经不带标记，不再重复去除
+func updateSelectedMarker() {
+	if !accessibleMode {
+		return
+	}
+	count := wordList.GetItemCount()
+	current := wordList.GetCurrentItem()
+	if markedRow == current && current < count {
+		if text, _ := wordList.GetItemText(current); text == markedText {
+			return
+		}
+	}
+
+	if markedRow >= 0 && markedRow < count {
+		if text, _ := wordList.GetItemText(markedRow); text == markedText {
+			wordList.SetItemText(markedRow, strings.TrimPrefix(text, selectedRowMarker), "")
+		}
+	}
+	markedRow = -1
+	if current >= 0 && current < count {
+		text, _ := wordList.GetItemText(current)
+		markedText = selectedRowMarker + text
+		wordList.SetItemText(current, markedText, "")
+		markedRow = current
+	}
+}
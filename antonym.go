@@ -0,0 +1,107 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// antonymPrefixes 构成反义词的否定前缀（unhappy、impossible、illegal、irregular、dislike、nonstop）
+var antonymPrefixes = []string{"un", "in", "im", "il", "ir", "dis", "non"}
+
+// antonymNegation 带否定前缀的单词，中文释义中应含有否定含义，用来排除 inform、dismiss 这类只是碰巧以前缀开头的词
+var antonymNegation = regexp.MustCompile(`不|非|无|未|反|失|缺`)
+
+// antonymHint 中文释义中直接给出的反义词提示，如「反义词: cold」
+var antonymHint = regexp.MustCompile(`反义词[:：]?\s*([A-Za-z][A-Za-z-]*)`)
+
+// antonymPairs 无法由前缀推出的常见反义词对，查询时双向使用
+var antonymPairs = [][2]string{
+	{"good", "bad"}, {"hot", "cold"}, {"big", "small"}, {"long", "short"}, {"high", "low"},
+	{"old", "new"}, {"young", "old"}, {"fast", "slow"}, {"early", "late"}, {"rich", "poor"},
+	{"strong", "weak"}, {"hard", "soft"}, {"heavy", "light"}, {"dark", "light"}, {"open", "close"},
+	{"up", "down"}, {"in", "out"}, {"left", "right"}, {"right", "wrong"}, {"true", "false"},
+	{"love", "hate"}, {"win", "lose"}, {"buy", "sell"}, {"give", "take"}, {"come", "go"},
+	{"push", "pull"}, {"begin", "end"}, {"start", "finish"}, {"first", "last"}, {"before", "after"},
+	{"above", "below"}, {"inside", "outside"}, {"full", "empty"}, {"wet", "dry"}, {"clean", "dirty"},
+	{"happy", "sad"}, {"easy", "difficult"}, {"cheap", "expensive"}, {"safe", "dangerous"},
+	{"alive", "dead"}, {"friend", "enemy"}, {"success", "failure"}, {"increase", "decrease"},
+	{"accept", "refuse"}, {"remember", "forget"}, {"thick", "thin"}, {"wide", "narrow"},
+	{"deep", "shallow"}, {"near", "far"}, {"always", "never"}, {"many", "few"}, {"more", "less"},
+	{"maximum", "minimum"}, {"positive", "negative"}, {"victory", "defeat"}, {"arrive", "depart"},
+}
+
+// maxAntonyms 反义词栏目最多显示的单词数
+const maxAntonyms = 6
+
+// findAntonyms 用启发式规则推测单词的反义词：常见反义词对、释义中的反义词提示，
+// 以及加上或去掉否定前缀后仍是单词、且带前缀的一方释义含有否定含义的词（happy ↔ unhappy）
+//
+// 结果只是推测，可能遗漏或出错；查询失败时返回 nil
+func findAntonyms(w Word) []string {
+	word := strings.ToLower(w.Word)
+	if !lowerWordRegex.MatchString(word) {
+		return nil
+	}
+
+	var result []string
+	seen := map[string]bool{word: true}
+	add := func(candidate string) {
+		if !seen[candidate] && len(result) < maxAntonyms {
+			seen[candidate] = true
+			result = append(result, candidate)
+		}
+	}
+
+	for _, pair := range antonymPairs {
+		if pair[0] == word {
+			add(pair[1])
+		} else if pair[1] == word {
+			add(pair[0])
+		}
+	}
+	for _, m := range antonymHint.FindAllStringSubmatch(w.Translation, -1) {
+		add(strings.ToLower(m[1]))
+	}
+
+	// prefixed 记录每个候选词是否是带前缀的一方：带前缀的词需要在数据库中核对释义
+	prefixed := map[string]bool{}
+	var candidates []string
+	for _, p := range antonymPrefixes {
+		if !seen[p+word] {
+			candidates = append(candidates, p+word)
+			prefixed[p+word] = true
+		}
+		if stem := strings.TrimPrefix(word, p); stem != word && len(stem) >= 3 && !seen[stem] {
+			candidates = append(candidates, stem)
+		}
+	}
+	if len(candidates) == 0 {
+		return result
+	}
+	// 去掉前缀的情况下，当前单词就是带前缀的一方
+	selfNegated := antonymNegation.MatchString(w.Translation)
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(candidates)), ",")
+	args := make([]interface{}, len(candidates))
+	for i, c := range candidates {
+		args[i] = c
+	}
+	ctx, cancel := queryContext()
+	defer cancel()
+	rows, err := englishDB.QueryContext(ctx, `SELECT word, COALESCE(translation, '') FROM words WHERE word IN (`+placeholders+`)`, args...)
+	if err != nil {
+		return result
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var candidate, translation string
+		if err := rows.Scan(&candidate, &translation); err != nil {
+			continue
+		}
+		if prefixed[candidate] && antonymNegation.MatchString(translation) ||
+			!prefixed[candidate] && selfNegated {
+			add(candidate)
+		}
+	}
+	return result
+}
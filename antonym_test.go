@@ -0,0 +1,61 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFindAntonyms(t *testing.T) {
+	useFixtureDatabases(t)
+	// 只是碰巧以否定前缀开头的词，释义中没有否定含义
+	if _, err := englishDB.Exec(`INSERT INTO words (word, translation) VALUES ('form', 'n. 形式'), ('inform', 'vt. 通知')`); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		word string
+		want []string
+	}{
+		// 加上或去掉否定前缀，双向都能找到
+		{"happy", []string{"sad", "unhappy"}},
+		{"unhappy", []string{"happy"}},
+		{"possible", []string{"impossible"}},
+		{"impossible", []string{"possible"}},
+		{"honest", []string{"dishonest"}},
+		{"dishonest", []string{"honest"}},
+		{"able", []string{"unable"}},
+		{"unable", []string{"able"}},
+		// 常见反义词对
+		{"give", []string{"take"}},
+		{"up", []string{"down"}},
+		{"in", []string{"out"}},
+		// 前缀一方的释义没有否定含义时不算反义词
+		{"form", nil},
+		{"inform", nil},
+		{"nation", nil},
+		{"apple", nil},
+	}
+	for _, tt := range tests {
+		w, err := lookupEnglishWord(tt.word)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := findAntonyms(w); !slices.Equal(got, tt.want) {
+			t.Errorf("findAntonyms(%s) = %q，应为 %q", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestFindAntonymsHint(t *testing.T) {
+	useFixtureDatabases(t)
+
+	// 释义中直接给出的反义词提示，与反义词对合并时不重复
+	w := Word{Word: "hot", Translation: "a. 热的（反义词: cold）\n反义词：Chilly"}
+	if got, want := findAntonyms(w), []string{"cold", "chilly"}; !slices.Equal(got, want) {
+		t.Errorf("findAntonyms(hot) = %q，应为 %q", got, want)
+	}
+	// 词组不推测
+	if got := findAntonyms(Word{Word: "give up", Translation: "放弃"}); got != nil {
+		t.Errorf("findAntonyms(give up) = %q，应为空", got)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// audioDownloadTimeout 下载单个发音文件的超时时间
+const audioDownloadTimeout = 10 * time.Second
+
+// audioPlayers 没有配置播放器时依次尝试的命令，音频文件路径追加在参数最后
+var audioPlayers = [][]string{
+	{"mpv", "--no-video", "--really-quiet"},
+	{"ffplay", "-nodisp", "-autoexit", "-loglevel", "quiet"},
+	{"afplay"},
+	{"mpg123", "-q"},
+	{"paplay"},
+}
+
+// audioCacheDir 发音文件缓存目录，所有档案共用
+func audioCacheDir() string {
+	return filepath.Join(stateRoot, "audio")
+}
+
+// audioURL 把配置中的地址模板里的 {word} 替换为单词
+func audioURL(word string) string {
+	return strings.ReplaceAll(config.AudioURL, "{word}", url.PathEscape(word))
+}
+
+// audioCachePath 返回单词发音的缓存路径，扩展名取自地址模板，无法判断时使用 .mp3
+func audioCachePath(word string) string {
+	ext := ".mp3"
+	if u, err := url.Parse(config.AudioURL); err == nil {
+		if e := path.Ext(u.Path); e == ".mp3" || e == ".ogg" || e == ".wav" {
+			ext = e
+		}
+	}
+	name := fmt.Sprintf("%x", sha1.Sum([]byte(word)))
+	return filepath.Join(audioCacheDir(), name+ext)
+}
+
+// fetchAudio 返回单词发音文件的本地路径，缓存中没有时先下载
+func fetchAudio(word string) (string, error) {
+	cachePath := audioCachePath(word)
+	if _, err := os.Stat(cachePath); err == nil {
+		return cachePath, nil
+	}
+
+	client := http.Client{Timeout: audioDownloadTimeout}
+	resp, err := client.Get(audioURL(word))
+	if err != nil {
+		return "", fmt.Errorf("下载发音失败（可能处于离线状态）: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("下载发音失败: 服务器返回 %s", resp.Status)
+	}
+
+	if err := os.MkdirAll(audioCacheDir(), 0755); err != nil {
+		return "", fmt.Errorf("无法创建目录 %s: %v", audioCacheDir(), err)
+	}
+
+	// 先写入临时文件，下载中断时不会在缓存中留下不完整的文件
+	tmp, err := os.CreateTemp(audioCacheDir(), "download.*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("无法创建临时文件: %v", err)
+	}
+	tmpName := tmp.Name()
+	if _, err := io.Copy(tmp, resp.Body); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return "", fmt.Errorf("下载发音失败: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return "", fmt.Errorf("无法写入 %s: %v", tmpName, err)
+	}
+	if err := os.Rename(tmpName, cachePath); err != nil {
+		os.Remove(tmpName)
+		return "", fmt.Errorf("无法保存 %s: %v", cachePath, err)
+	}
+	return cachePath, nil
+}
+
+// playAudio 调用外部播放器播放音频文件，等待播放结束
+func playAudio(file string) error {
+	var command []string
+	if config.AudioPlayer != "" {
+		command = strings.Fields(config.AudioPlayer)
+	} else {
+		for _, candidate := range audioPlayers {
+			if _, err := exec.LookPath(candidate[0]); err == nil {
+				command = candidate
+				break
+			}
+		}
+	}
+	if len(command) == 0 {
+		return fmt.Errorf("找不到音频播放器，请安装 mpv 或 ffplay，或在 config.json 中设置 audioPlayer")
+	}
+
+	args := append(append([]string{}, command[1:]...), file)
+	cmd := exec.Command(command[0], args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		msg := strings.TrimSpace(string(output))
+		if msg == "" {
+			msg = err.Error()
+		}
+		return fmt.Errorf("播放发音失败: %s", msg)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// chineseCheckpointSize 生成中文数据库时每写入多少个中文词提交一次事务；中途失败时已提交的部分保留在临时文件中
+const chineseCheckpointSize = 20000
+
+// buildFingerprint 正在生成的词典 CSV 的标识（路径、大小和修改时间），写入中文临时数据库的 build_state 表，
+// 下次生成时标识一致才从断点继续；为空时不记录（如在内存中生成数据库）
+var buildFingerprint string
+
+// csvFingerprint 返回词典 CSV 的标识，文件被替换或修改后标识随之变化
+func csvFingerprint(csvFile string) string {
+	path, err := filepath.Abs(csvFile)
+	if err != nil {
+		path = csvFile
+	}
+	info, err := os.Stat(csvFile)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%s|%d|%d", path, info.Size(), info.ModTime().UnixNano())
+}
+
+// markBuildState 在中文临时数据库中记录正在生成的 CSV 标识，之后提交的检查点都属于这份 CSV
+func markBuildState(db *sql.DB) error {
+	if buildFingerprint == "" {
+		return nil
+	}
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS build_state (fingerprint TEXT NOT NULL);
+		DELETE FROM build_state;`)
+	if err == nil {
+		_, err = db.Exec(`INSERT INTO build_state (fingerprint) VALUES (?)`, buildFingerprint)
+	}
+	if err != nil {
+		return fmt.Errorf("无法记录生成进度: %v", err)
+	}
+	return nil
+}
+
+// clearBuildState 中文数据库生成完成后删除 build_state 表，完成的数据库不会被当作断点
+func clearBuildState(db *sql.DB) error {
+	if _, err := db.Exec(`DROP TABLE IF EXISTS build_state`); err != nil {
+		return fmt.Errorf("无法清除生成进度: %v", err)
+	}
+	return nil
+}
+
+// resumableChineseTmp 返回中文临时数据库是否是以 fingerprint 对应的 CSV 未完成的生成，
+// 是则返回其中已写入的中文词数；文件不存在、已完成或属于其他 CSV 时返回 0
+func resumableChineseTmp(tmpFile, fingerprint string) int {
+	if fingerprint == "" {
+		return 0
+	}
+	if _, err := os.Stat(tmpFile); err != nil {
+		return 0
+	}
+	db, err := sql.Open("sqlite", tmpFile+"?mode=ro")
+	if err != nil {
+		return 0
+	}
+	defer db.Close()
+
+	var saved string
+	if err := db.QueryRow(`SELECT fingerprint FROM build_state`).Scan(&saved); err != nil || saved != fingerprint {
+		return 0
+	}
+	var rows int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM chinese_words`).Scan(&rows); err != nil {
+		return 0
+	}
+	return rows
+}
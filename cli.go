@@ -0,0 +1,552 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// subcommands 可用的子命令，args 为子命令名之后的参数；不带子命令运行时启动交互界面
+var subcommands = map[string]func(args []string) error{
+	"lookup": runLookup,
+	"build":  runBuild,
+	"serve":  runServe,
+	"export": runExport,
+	"random": runRandom,
+}
+
+// subcommandNames 子命令在帮助信息中的显示顺序
+var subcommandNames = []string{"lookup", "build", "serve", "export", "random"}
+
+// subcommandUsage 各子命令的用法说明，显示在 -h 的输出中
+var subcommandUsage = map[string]string{
+	"lookup": "dict lookup [选项] <单词>    查询单词并输出详情",
+	"build":  "dict build [选项]           生成或重新生成数据库",
+	"serve":  "dict serve [选项]           启动 HTTP 查询服务",
+	"export": "dict export [选项]          导出历史记录、收藏等单词列表",
+	"random": "dict random [选项]          随机抽取满足条件的单词",
+}
+
+// runSubcommand 在 args[0] 是子命令时执行它并返回 true，否则返回 false 由调用方启动交互界面
+func runSubcommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	run, ok := subcommands[args[0]]
+	if !ok {
+		return false
+	}
+
+	// 子命令的输出常被重定向或交给其他程序处理，不是终端时使用纯文本
+	plainOutput = config.Plain || !term.IsTerminal(int(os.Stdout.Fd()))
+	crossLanguageFallback = config.CrossLanguageFallback
+	dualSearch = config.DualSearch
+	combinedSearch = config.CombinedSearch
+	chineseSubphrases = config.ChineseSubphrases
+	containsMinLength = config.ContainsMinLength
+	groupFamilies.Store(config.GroupFamilies)
+	if err := run(args[1:]); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			consolePrintf("❌ %v\n", err)
+		}
+		os.Exit(1)
+	}
+	return true
+}
+
+// newSubcommandFlags 创建子命令的参数集合，-h 时输出用法和全部选项
+func newSubcommandFlags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Var(&currentLogLevel, "log-level", "诊断日志的`级别`：debug、info、warn、error，日志输出到标准错误")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "用法: %s\n\n选项:\n", subcommandUsage[name])
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// printSubcommandUsage 在主程序的 -h 输出后列出子命令
+func printSubcommandUsage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "\n子命令（dict <子命令> -h 查看各自的选项）:\n")
+	for _, name := range subcommandNames {
+		fmt.Fprintf(out, "  %s\n", subcommandUsage[name])
+	}
+}
+
+// openExistingDatabases 打开已生成的数据库，数据库不存在时提示先运行 dict build
+func openExistingDatabases() error {
+	for _, file := range []string{englishDBFile, chineseDBFile} {
+		if _, err := os.Stat(file); os.IsNotExist(err) {
+			return fmt.Errorf("找不到数据库 %s，请先运行 dict build", file)
+		}
+	}
+	if err := openDatabases(); err != nil {
+		return err
+	}
+	if oldest, newest, err := schemaVersions(); err == nil {
+		if err := schemaStatus(oldest, newest); err != nil {
+			logWarnf("%v", err)
+		}
+	}
+	return nil
+}
+
+// runLookup 查询单词，输出第一个结果的详情和其余匹配的单词
+func runLookup(args []string) error {
+	fs := newSubcommandFlags("lookup")
+	fs.IntVar(&searchLimit, "limit", config.Limit, "最多列出的匹配结果数")
+	debug := fs.Bool("debug", false, "同 -log-level debug：把每次查询的 SQL 和耗时输出到标准错误")
+	asHTML := fs.Bool("html", false, "把第一个结果的详情输出为独立的 HTML 页面（只支持英文单词）")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if searchLimit <= 0 {
+		return fmt.Errorf("-limit 必须大于 0")
+	}
+	if *debug {
+		currentLogLevel = levelDebug
+	}
+
+	// 词组可以不加引号，例如 dict lookup give up
+	query := normalizeQuery(strings.Join(fs.Args(), " "))
+	if query == "" {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	if err := openExistingDatabases(); err != nil {
+		return err
+	}
+	defer closeDatabases()
+	if *asHTML {
+		return printLookupHTML(query)
+	}
+	return printLookup(query)
+}
+
+// printLookupHTML 搜索 query 并把第一个结果的详情输出为 HTML（需先打开数据库）
+func printLookupHTML(query string) error {
+	results, err := search(query)
+	if err != nil {
+		return fmt.Errorf("搜索失败: %v", err)
+	}
+	if len(results) == 0 {
+		return fmt.Errorf("没有找到 %q", query)
+	}
+	page, err := lookupHTML(results[0].Word)
+	if err != nil {
+		return err
+	}
+	fmt.Print(page)
+	return nil
+}
+
+// printLookup 搜索 query 并输出第一个结果的详情和其余匹配的单词（需先打开数据库）
+func printLookup(query string) error {
+	results, err := search(query)
+	if err != nil {
+		return fmt.Errorf("搜索失败: %v", err)
+	}
+	if len(results) == 0 {
+		return fmt.Errorf("没有找到 %q", query)
+	}
+
+	word := results[0].Word
+	detail, _, err := renderDetail(detailKey{word: word, full: true, firstLookup: firstLookupLine(word)})
+	if err != nil {
+		return err
+	}
+	fmt.Println(plainText(detail))
+
+	if len(results) > 1 {
+		fmt.Println()
+		fmt.Println("其他结果:")
+		for _, r := range results[1:] {
+			if r.Root != "" {
+				fmt.Printf("    %s（%s 的同族词）\n", r.Word, r.Root)
+			} else {
+				label := r.Match.Label()
+				if r.User {
+					label += "，" + userWordSource
+				}
+				if r.Ignored {
+					label += "，已忽略"
+				}
+				fmt.Printf("  %s（%s）\n", r.Word, label)
+			}
+		}
+	}
+	return nil
+}
+
+// runBuild 从词典 CSV 生成数据库，已有数据库时需要 -force 才会覆盖
+func runBuild(args []string) error {
+	fs := newSubcommandFlags("build")
+	csvFile := fs.String("csv", dictCSVFile, "词典 CSV 文件（可以是 .gz），不存在时读取同名的 .gz 文件")
+	fs.StringVar(&csvEncoding, "encoding", config.Encoding, "词典 CSV 的字符编码：auto、utf-8、gbk、gb18030、big5")
+	fs.BoolVar(&lowPowerMode, "low-power", config.LowPower, "低功耗模式：单协程写入、较小的批次并限制为单核，较慢但发热更低")
+	fs.BoolVar(&vacuumAfterBuild, "vacuum", config.Vacuum, "生成后执行 VACUUM 回收空闲页，缩小数据库文件，需要额外的时间")
+	force := fs.Bool("force", false, "数据库已存在时重新生成，新数据库生成成功后才替换旧文件")
+	dryRun := fs.Bool("dry-run", false, "只读取并检查词典 CSV，报告格式、记录数、跳过的行和能提取的中文词数，不生成数据库")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := validateEncoding(csvEncoding); err != nil {
+		return err
+	}
+	if *dryRun {
+		file, err := resolveDictCSV(*csvFile)
+		if err != nil {
+			return err
+		}
+		return DryRunConverter(file)
+	}
+
+	for _, file := range []string{englishDBFile, chineseDBFile} {
+		if _, err := os.Stat(file); err == nil && !*force {
+			return fmt.Errorf("数据库 %s 已存在，使用 -force 重新生成", file)
+		}
+	}
+	return buildDatabases(*csvFile)
+}
+
+// runServe 启动只读的 HTTP 查询服务，接口返回 JSON
+//
+//	GET /search?q=<关键词>  匹配的单词列表
+//	GET /word?q=<单词>      单词详情
+//	GET /random?n=<数量>    随机单词，可用 bnc、tag、pos、length 参数筛选
+//	GET /health             数据库健康检查，degraded 时返回 503
+func runServe(args []string) error {
+	fs := newSubcommandFlags("serve")
+	addr := fs.String("addr", "127.0.0.1:8080", "监听地址")
+	fs.IntVar(&searchLimit, "limit", config.Limit, "每次搜索最多返回的结果数")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if searchLimit <= 0 {
+		return fmt.Errorf("-limit 必须大于 0")
+	}
+
+	if err := openExistingDatabases(); err != nil {
+		return err
+	}
+	defer closeDatabases()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search", handleSearchRequest)
+	mux.HandleFunc("/word", handleWordRequest)
+	mux.HandleFunc("/random", handleRandomRequest)
+	mux.HandleFunc("/health", handleHealthRequest)
+
+	consolePrintf("✅ 查询服务已启动: http://%s\n", *addr)
+	return http.ListenAndServe(*addr, logRequests(mux))
+}
+
+// statusRecorder 记录处理函数写出的状态码，用于请求日志
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader 记下状态码后照常写出
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// logRequests 在 info 级别记录每个请求的路径、状态码和耗时，服务端错误记录为 error
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		level := levelInfo
+		if rec.status >= http.StatusInternalServerError {
+			level = levelError
+		}
+		logf(level, "%s %s %d %v", r.Method, r.URL.RequestURI(), rec.status, time.Since(start).Round(time.Microsecond))
+	})
+}
+
+// searchResponse /search 接口返回的一条匹配结果
+type searchResponse struct {
+	Word    string `json:"word"`
+	Match   string `json:"match"`
+	Family  string `json:"family,omitempty"`  // 开启 groupFamilies 时派生词所属的词根
+	User    bool   `json:"user,omitempty"`    // 来自用户词表
+	Ignored bool   `json:"ignored,omitempty"` // 匹配 ignoreWords，作为精确匹配仍然返回
+}
+
+// wordResponse /word 接口返回的单词详情，英文单词额外包含各字段
+type wordResponse struct {
+	Word        string `json:"word"`
+	Phonetic    string `json:"phonetic,omitempty"`
+	Definition  string `json:"definition,omitempty"`
+	Translation string `json:"translation,omitempty"`
+	Bnc         string `json:"bnc,omitempty"`
+	Source      string `json:"source,omitempty"`
+	Detail      string `json:"detail"` // 与交互界面相同的详情文本（不含颜色标签）
+}
+
+// handleSearchRequest 处理 /search 请求
+func handleSearchRequest(w http.ResponseWriter, r *http.Request) {
+	query := normalizeQuery(r.URL.Query().Get("q"))
+	if query == "" {
+		writeJSONError(w, http.StatusBadRequest, "缺少参数 q")
+		return
+	}
+
+	results, err := search(query)
+	if errors.Is(err, errQueryTimeout) {
+		writeJSONError(w, http.StatusGatewayTimeout, err.Error())
+		return
+	}
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	resp := make([]searchResponse, len(results))
+	for i, res := range results {
+		resp[i] = searchResponse{Word: res.Word, Match: res.Match.Label(), Family: res.Root, User: res.User, Ignored: res.Ignored}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleWordRequest 处理 /word 请求
+func handleWordRequest(w http.ResponseWriter, r *http.Request) {
+	word := normalizeQuery(r.URL.Query().Get("q"))
+	if word == "" {
+		writeJSONError(w, http.StatusBadRequest, "缺少参数 q")
+		return
+	}
+
+	resp := wordResponse{Word: word}
+	if !isChinese(word) {
+		entry, err := lookupEnglishWord(word)
+		if err != nil {
+			writeJSONError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		resp = newWordResponse(entry)
+	}
+
+	detail, _, err := renderDetail(detailKey{word: word, full: true, firstLookup: firstLookupLine(word)})
+	if err != nil {
+		writeJSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	resp.Detail = plainText(detail)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// newWordResponse 用英文单词的信息填充 wordResponse 的各字段，不含 Detail
+func newWordResponse(entry Word) wordResponse {
+	// 词典中的换行存储为字面的 \n，输出时还原为真正的换行
+	return wordResponse{
+		Word:        entry.Word,
+		Phonetic:    entry.Phonetic,
+		Definition:  strings.ReplaceAll(entry.Definition, `\n`, "\n"),
+		Translation: strings.ReplaceAll(entry.Translation, `\n`, "\n"),
+		Bnc:         entry.Bnc,
+		Source:      entry.Source,
+	}
+}
+
+// randomResponses 把随机抽取的单词转换为 JSON 输出，Detail 与交互界面的详情相同
+func randomResponses(words []Word) []wordResponse {
+	resp := make([]wordResponse, len(words))
+	for i, entry := range words {
+		resp[i] = newWordResponse(entry)
+		detail, _ := formatEnglishDetail(entry, detailKey{})
+		resp[i].Detail = plainText(detail)
+	}
+	return resp
+}
+
+// handleRandomRequest 处理 /random 请求，参数与 dict random 的选项相同
+func handleRandomRequest(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	n := 10
+	if v := params.Get("n"); v != "" {
+		var err error
+		if n, err = strconv.Atoi(v); err != nil || n <= 0 || n > searchLimit {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("参数 n 必须是 1 到 %d 之间的整数", searchLimit))
+			return
+		}
+	}
+	opts, err := randomOptions(params.Get("bnc"), params.Get("tag"), params.Get("pos"), params.Get("length"))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	words, err := RandomWords(n, opts...)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, randomResponses(words))
+}
+
+// writeJSON 以 JSON 格式写出响应
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeJSONError 以 {"error": "..."} 的形式写出错误
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+// exportLists 可导出的单词列表
+var exportLists = map[string]func() []string{
+	"history":   getSearchHistory,
+	"favorites": getFavorites,
+}
+
+// runExport 把历史记录或收藏导出为文本（每行一个单词）或带释义的 CSV
+func runExport(args []string) error {
+	fs := newSubcommandFlags("export")
+	list := fs.String("list", "favorites", "导出的列表：favorites（收藏）或 history（历史记录）")
+	format := fs.String("format", "txt", "导出格式：txt（每行一个单词）或 csv（单词、音标、释义）")
+	output := fs.String("o", "", "输出文件，默认输出到标准输出")
+	fs.StringVar(&profileName, "profile", config.Profile, "用户档案名")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	getList, ok := exportLists[*list]
+	if !ok {
+		return fmt.Errorf("未知的列表 %q（可选 favorites、history）", *list)
+	}
+	if *format != "txt" && *format != "csv" {
+		return fmt.Errorf("未知的导出格式 %q（可选 txt、csv）", *format)
+	}
+	if err := validateProfileName(profileName); err != nil {
+		return err
+	}
+	if err := loadState(); err != nil {
+		return fmt.Errorf("读取用户数据失败: %v", err)
+	}
+	words := getList()
+
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("无法创建文件 %s: %v", *output, err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if *format == "txt" {
+		for _, word := range words {
+			fmt.Fprintln(out, word)
+		}
+		return nil
+	}
+
+	if err := openExistingDatabases(); err != nil {
+		return err
+	}
+	defer closeDatabases()
+
+	writer := csv.NewWriter(out)
+	writer.Write([]string{"word", "phonetic", "translation"})
+	for _, word := range words {
+		record := []string{word, "", ""}
+		if isChinese(word) {
+			var englishWords string
+			if err := chineseDB.QueryRow(`SELECT english_words FROM chinese_words WHERE chinese = ?`, word).Scan(&englishWords); err == nil {
+				record[2] = cleanNewlines(strings.ReplaceAll(englishWords, "\n", "；"))
+			}
+		} else if entry, err := lookupEnglishWord(word); err == nil {
+			record[1] = entry.Phonetic
+			record[2] = cleanNewlines(entry.Translation)
+		}
+		writer.Write(record)
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// runRandom 随机抽取满足条件的单词，每行输出单词、音标和中文释义（以制表符分隔），或输出 JSON
+func runRandom(args []string) error {
+	fs := newSubcommandFlags("random")
+	n := fs.Int("n", 10, "抽取的单词数")
+	bnc := fs.String("bnc", "", "BNC 词频排名范围，如 1-5000（排名越小越常用）")
+	tag := fs.String("tag", "", "考试标签：zk、gk、cet4、cet6、ky、toefl、ielts、gre")
+	pos := fs.String("pos", "", "词性，如 n、v、adj、adv")
+	length := fs.String("length", "", "单词长度范围，如 4-8")
+	format := fs.String("format", "txt", "输出格式：txt 或 json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *n <= 0 {
+		return fmt.Errorf("-n 必须大于 0")
+	}
+	if *format != "txt" && *format != "json" {
+		return fmt.Errorf("未知的输出格式 %q（可选 txt、json）", *format)
+	}
+	opts, err := randomOptions(*bnc, *tag, *pos, *length)
+	if err != nil {
+		return fmt.Errorf("-%v", err)
+	}
+
+	if err := openExistingDatabases(); err != nil {
+		return err
+	}
+	defer closeDatabases()
+
+	words, err := RandomWords(*n, opts...)
+	if err != nil {
+		return err
+	}
+	if len(words) == 0 {
+		return fmt.Errorf("没有满足条件的单词")
+	}
+
+	if *format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(randomResponses(words))
+	}
+	for _, w := range words {
+		fmt.Printf("%s\t%s\t%s\n", w.Word, w.Phonetic, cleanNewlines(w.Translation))
+	}
+	return nil
+}
+
+var (
+	// colorTagRegex 匹配 tview 的颜色和样式标签，如 [yellow]、[-]、[white::b]
+	colorTagRegex = regexp.MustCompile(`\[(?:[a-zA-Z]+|#[0-9a-fA-F]{6}|-)?(?::(?:[a-zA-Z]+|#[0-9a-fA-F]{6}|-)?){0,2}\]`)
+	// escapedTagRegex 匹配 tview.Escape 转义过的方括号，如 [red[]
+	escapedTagRegex = regexp.MustCompile(`(\[[a-zA-Z0-9_,;: \-\."#]+\[*)\[\]`)
+)
+
+// plainText 去掉详情文本中的 tview 颜色标签，用于命令行和 HTTP 输出
+func plainText(text string) string {
+	text = regionTagRegex.ReplaceAllString(text, "")
+	text = colorTagRegex.ReplaceAllStringFunc(text, func(tag string) string {
+		if tag == "[]" {
+			return tag
+		}
+		return ""
+	})
+	return escapedTagRegex.ReplaceAllString(text, "$1]")
+}
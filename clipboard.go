@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// clipboardCommands 没有配置剪贴板命令时依次尝试的命令，要复制的文本从标准输入传入
+var clipboardCommands = [][]string{
+	{"pbcopy"},
+	{"wl-copy"},
+	{"xclip", "-selection", "clipboard"},
+	{"xsel", "--clipboard", "--input"},
+	{"clip.exe"},
+}
+
+// copyToClipboard 调用外部命令把文本复制到系统剪贴板
+func copyToClipboard(text string) error {
+	var command []string
+	if config.ClipboardCommand != "" {
+		command = strings.Fields(config.ClipboardCommand)
+	} else {
+		for _, candidate := range clipboardCommands {
+			if _, err := exec.LookPath(candidate[0]); err == nil {
+				command = candidate
+				break
+			}
+		}
+	}
+	if len(command) == 0 {
+		return fmt.Errorf("找不到剪贴板工具，请安装 xclip 或 wl-copy，或在 config.json 中设置 clipboardCommand")
+	}
+
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		msg := strings.TrimSpace(string(output))
+		if msg == "" {
+			msg = err.Error()
+		}
+		return fmt.Errorf("复制到剪贴板失败: %s", msg)
+	}
+	return nil
+}
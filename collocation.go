@@ -0,0 +1,159 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+const (
+	maxCollocations          = 8    // 常见搭配栏目最多显示的词组数
+	maxCollocationCandidates = 5000 // 参与排序的词组数上限，避免 the、of 这类词读出过多词组
+)
+
+// collocationPlaceholders 词组中的占位词，排序时不计入
+var collocationPlaceholders = map[string]bool{
+	"sb": true, "sth": true, "sb's": true, "one": true, "one's": true, "oneself": true,
+	"somebody": true, "something": true, "someone": true, "someone's": true,
+}
+
+// collocation 候选的搭配词组及其排序依据
+type collocation struct {
+	phrase string
+	bnc    int // 词组本身的 BNC 排名
+	other  int // 词组中除单词本身和占位词以外最少见的词的 BNC 排名，没有这样的词时视为没有词频
+	words  int // 词数
+}
+
+// findCollocations 在词库中查找把单词作为独立一词包含的全小写词组（take in、apple tree），作为粗略的常见搭配
+//
+// ECDICT 中的词组几乎都没有词频，因此先按词组本身的 BNC 排名、再按其中其他词的常见程度排序：
+// 其他词都很常见的词组（make it、take in）排在前面，含生僻词的排在后面，最后按词数和字母顺序。
+// 只处理由字母组成的单词，查询失败时返回 nil
+func findCollocations(w Word) []string {
+	word := strings.ToLower(w.Word)
+	if !lowerWordRegex.MatchString(word) {
+		return nil
+	}
+
+	// 词组必须含有空格，单词出现在开头、结尾或中间；全小写用来排除人名、地名和软件菜单项
+	where := `(word LIKE ? OR word LIKE ? OR word LIKE ?) AND word = LOWER(word)`
+	args := []interface{}{word + " %", "% " + word, "% " + word + " %"}
+	query := `SELECT word, COALESCE(bnc, '') FROM words WHERE ` + where
+	if trigrams := wordTrigrams(word); hasTrigramIndex && len(trigrams) > 0 {
+		rarest, trigramArgs := rarestTrigram(trigrams)
+		query = `SELECT word, COALESCE(bnc, '') FROM word_trigrams t JOIN words ON words.id = t.word_id
+			WHERE t.trigram = ` + rarest + ` AND ` + where
+		args = append(trigramArgs, args...)
+	}
+	query += ` LIMIT ?`
+	args = append(args, maxCollocationCandidates)
+
+	ctx, cancel := queryContext()
+	defer cancel()
+	rows, err := englishDB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil
+	}
+	var candidates []collocation
+	seen := make(map[string]bool)
+	tokens := make(map[string]bool)
+	for rows.Next() {
+		var phrase, bnc string
+		if err := rows.Scan(&phrase, &bnc); err != nil || seen[phrase] {
+			continue
+		}
+		seen[phrase] = true
+		candidates = append(candidates, collocation{phrase: phrase, bnc: parseBNC(bnc), words: len(strings.Fields(phrase))})
+		for _, t := range collocationTokens(phrase, word) {
+			tokens[t] = true
+		}
+	}
+	rows.Close()
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	ranks := wordRanks(tokens)
+	for i, c := range candidates {
+		tokens := collocationTokens(c.phrase, word)
+		if len(tokens) == 0 {
+			// 只由单词本身和占位词组成（make one、run oneself），没有可比较的词
+			candidates[i].other = parseBNC("")
+		}
+		for _, t := range tokens {
+			rank, ok := ranks[t]
+			if !ok {
+				rank = parseBNC("")
+			}
+			if rank > candidates[i].other {
+				candidates[i].other = rank
+			}
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.bnc != b.bnc {
+			return a.bnc < b.bnc
+		}
+		if a.other != b.other {
+			return a.other < b.other
+		}
+		if a.words != b.words {
+			return a.words < b.words
+		}
+		return a.phrase < b.phrase
+	})
+
+	var result []string
+	for _, c := range candidates {
+		if len(result) == maxCollocations {
+			break
+		}
+		result = append(result, c.phrase)
+	}
+	return result
+}
+
+// collocationTokens 返回词组中除 word 本身和占位词以外的词，去掉末尾的标点
+func collocationTokens(phrase, word string) []string {
+	var tokens []string
+	for _, t := range strings.Fields(phrase) {
+		t = strings.TrimRight(t, ".,;!?…")
+		if t != "" && t != word && !collocationPlaceholders[t] {
+			tokens = append(tokens, t)
+		}
+	}
+	return tokens
+}
+
+// wordRanks 查询一组单词的 BNC 排名，同一个单词有多个词条时取最靠前的排名；没有词频的单词不在结果中
+func wordRanks(words map[string]bool) map[string]int {
+	ranks := make(map[string]int)
+	if len(words) == 0 {
+		return ranks
+	}
+	args := make([]interface{}, 0, len(words))
+	for w := range words {
+		args = append(args, w)
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(args)), ",")
+
+	ctx, cancel := queryContext()
+	defer cancel()
+	rows, err := englishDB.QueryContext(ctx, `SELECT word, CAST(bnc AS INTEGER) FROM words WHERE word IN (`+placeholders+`) AND CAST(bnc AS INTEGER) > 0`, args...)
+	if err != nil {
+		return ranks
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var w string
+		var rank int
+		if err := rows.Scan(&w, &rank); err != nil {
+			continue
+		}
+		if old, ok := ranks[w]; !ok || rank < old {
+			ranks[w] = rank
+		}
+	}
+	return ranks
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// combinedSearch 为 true 时一次搜索同时匹配英文单词和它的中文释义（--combined）
+var combinedSearch bool
+
+// splitMixedQuery 把查询拆成英文部分和中文部分：含有汉字的词作为中文释义中要找的词，其余的词组成英文单词（或词组）
+//
+// 例如 app 苹果 拆成 app 和 [苹果]，两部分都可以为空
+func splitMixedQuery(query string) (english string, chinese []string) {
+	var words []string
+	for _, field := range strings.Fields(query) {
+		if isChinese(field) {
+			chinese = append(chinese, field)
+		} else {
+			words = append(words, field)
+		}
+	}
+	return strings.Join(words, " "), chinese
+}
+
+// searchCombined 同时按英文单词和中文释义查找，按匹配质量分组合并：
+// 英文部分是单词前缀且释义含有全部中文词的排在最前（单词和释义），其次是只按英文部分的各类匹配，
+// 最后是释义含有全部中文词的英文单词（释义匹配）。只有中文时先列出中文数据库中的匹配
+func searchCombined(query string) ([]SearchResult, error) {
+	english, chinese := splitMixedQuery(normalizeQuery(query))
+	seen := make(map[string]bool)
+	var results []SearchResult
+
+	if english != "" && len(chinese) > 0 {
+		both, err := searchTranslationContains(english, chinese, MatchWordAndTranslation, seen)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, both...)
+	}
+
+	var same []SearchResult
+	var err error
+	switch {
+	case english != "":
+		same, err = searchSameLanguage(english)
+	case len(chinese) > 0:
+		same, err = searchChinese(strings.Join(chinese, ""))
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range same {
+		if !seen[r.Word] {
+			seen[r.Word] = true
+			results = append(results, r)
+		}
+	}
+
+	if len(chinese) > 0 {
+		translated, err := searchTranslationContains("", chinese, MatchTranslation, seen)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, translated...)
+	}
+	return results, nil
+}
+
+// searchTranslationContains 查找中文释义含有 chinese 中每个词的英文单词，prefix 不为空时单词还要以它开头
+//
+// 释义中有一个义项正好是要找的词（如 apple 的「苹果」）时排在只是提到它的单词前面，其次按 BNC 词频排序
+func searchTranslationContains(prefix string, chinese []string, match MatchType, seen map[string]bool) ([]SearchResult, error) {
+	query := `SELECT word, translation FROM words WHERE 1 = 1`
+	var args []interface{}
+	if prefix != "" {
+		query += ` AND word LIKE ? ESCAPE '\'`
+		args = append(args, escapeLike(prefix)+"%")
+	}
+	for _, term := range chinese {
+		query += ` AND translation LIKE ? ESCAPE '\'`
+		args = append(args, "%"+escapeLike(term)+"%")
+	}
+	query += properNounFilter(config.HideProperNounsInSearch) +
+		` ORDER BY CASE WHEN CAST(bnc AS INTEGER) > 0 THEN CAST(bnc AS INTEGER) ELSE 1073741824 END, word LIMIT ?`
+	args = append(args, searchLimit*5)
+
+	start := time.Now()
+	ctx, cancel := queryContext()
+	defer cancel()
+	rows, err := englishDB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, timeoutError(ctx, err)
+	}
+	defer rows.Close()
+
+	type candidate struct {
+		word  string
+		whole bool
+	}
+	var candidates []candidate
+	for rows.Next() {
+		var word, translation string
+		if err := rows.Scan(&word, &translation); err != nil || seen[word] {
+			continue
+		}
+		candidates = append(candidates, candidate{word, hasWholeSense(translation, chinese)})
+	}
+	if err := timeoutError(ctx, rows.Err()); err != nil {
+		return nil, err
+	}
+	logQuery(match.Label(), start, len(candidates), query, args...)
+
+	// 查询已按词频排好，稳定排序只把有完整义项的单词提前
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].whole && !candidates[j].whole
+	})
+	var results []SearchResult
+	for _, c := range candidates {
+		if len(results) >= searchLimit {
+			break
+		}
+		seen[c.word] = true
+		results = append(results, SearchResult{Word: c.word, Match: match})
+	}
+	return results, nil
+}
+
+// hasWholeSense 检查释义中是否有某个义项（或逗号分隔的近义词）正好是 chinese 中的一个词
+func hasWholeSense(translation string, chinese []string) bool {
+	for _, g := range parseSenses(translation) {
+		for _, sense := range g.Senses {
+			for _, piece := range strings.FieldsFunc(sense, func(r rune) bool {
+				return r == ',' || r == '，' || r == '、' || unicode.IsSpace(r)
+			}) {
+				for _, term := range chinese {
+					if piece == term {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// configFile 配置文件路径（与数据库文件位于同一目录）
+const configFile = "config.json"
+
+// Config 用户配置，从 config.json 读取，作为命令行参数的默认值
+type Config struct {
+	Plain bool `json:"plain"` // 控制台只输出 ASCII 符号
+	Vim   bool `json:"vim"`   // 启用 vim 风格按键
+	Limit int  `json:"limit"` // 每次搜索最多返回的结果数
+
+	ProgressBar ProgressStyle `json:"progressBar"` // 生成数据库和下载时进度条的宽度、字符、颜色和刷新方式
+
+	HistorySize int `json:"historySize"` // 最多保存的搜索历史条数
+
+	HistoryMaxFileSize int `json:"historyMaxFileSize"` // 历史文件超过多少 KB 时归档并重新开始，0 表示不限制
+	HistoryRotateKeep  int `json:"historyRotateKeep"`  // 归档后保留在新文件中的最近历史条数
+
+	HideProperNouns         bool `json:"hideProperNouns"`         // 随机推荐中不出现专有名词和缩写
+	HideProperNounsInSearch bool `json:"hideProperNounsInSearch"` // 前缀和包含匹配中也不显示专有名词和缩写（精确匹配不受影响）
+
+	CrossLanguageFallback bool `json:"crossLanguageFallback"` // 搜索没有结果时到另一种语言的释义中查找
+	DualSearch            bool `json:"dualSearch"`            // 每次搜索同时查找两种语言，结果合并显示
+	CombinedSearch        bool `json:"combinedSearch"`        // 一次搜索同时匹配英文单词和中文释义，如 app 苹果
+	ChineseSubphrases     bool `json:"chineseSubphrases"`     // 中文搜索还列出词库中被查询包含的词，如 人工智能 → 智能
+
+	Wildcards         bool `json:"wildcards"`         // 英文查询开头或结尾的 * 作为通配符：pre* 前缀搜索，*tion 后缀搜索
+	ContainsMinLength int  `json:"containsMinLength"` // 英文查询少于这么多个字符时不做包含匹配，0 表示总是做
+	QueryTimeout      int  `json:"queryTimeout"`      // 单次查询最多等待的秒数，超时后返回「查询超时」，0 表示不限制
+
+	Profile string `json:"profile"` // 默认使用的用户档案
+
+	DetailSections  []string `json:"detailSections"`  // 英文详情中显示的栏目及顺序，未列出的栏目不显示
+	MaxDetailLength int      `json:"maxDetailLength"` // 详情最多显示的字符数，超出时截断（0 表示不限制）
+
+	MaxChineseEntries int `json:"maxChineseEntries"` // 中文详情最多列出的英文单词数，按展开键显示全部（0 表示不限制）
+
+	UserWords string `json:"userWords"` // 用户词表文件（CSV 或 .json），其中的词条在运行时合并到搜索结果中
+
+	IgnoreWords    []string `json:"ignoreWords"`    // 不出现在搜索结果和随机推荐中的单词或通配符模式，不区分大小写
+	KeepExactMatch bool     `json:"keepExactMatch"` // 精确匹配不受 ignoreWords 和结果筛选的影响，总是显示
+
+	SurpriseBNC string `json:"surpriseBNC"` // 「随便看看」抽取的 BNC 词频排名范围，如 1-20000，为空表示不限
+	SurpriseTag string `json:"surpriseTag"` // 「随便看看」只抽取带有这个考试标签的单词，为空表示不限
+
+	Encoding string `json:"encoding"` // 词典 CSV 的字符编码（auto、utf-8、gbk、gb18030、big5）
+	LowPower bool   `json:"lowPower"` // 生成数据库时使用低功耗模式：单协程、小批次、单核运行
+	Vacuum   bool   `json:"vacuum"`   // 生成数据库后执行 VACUUM 缩小文件
+
+	PersonalRanking  bool `json:"personalRanking"`  // 按查阅次数调整同一匹配类型内的排序
+	FirstLookupDates bool `json:"firstLookupDates"` // 记录每个单词首次查询的日期，显示在详情末尾
+	GroupFamilies    bool `json:"groupFamilies"`    // 把同一词族的派生词集中显示在词根之下
+
+	BNCDisplay string `json:"bncDisplay"` // BNC 词频的显示方式：rank（排名）、percentile（百分位）或 both
+	PosBadges  bool   `json:"posBadges"`  // 详情中的词性标记按词性显示为彩色徽标，颜色见 theme
+
+	TypeMarkers bool `json:"typeMarkers"` // 单词列表中在词组和缩写前显示类型标记，颜色见 theme
+	SenseCounts bool `json:"senseCounts"` // 单词列表和详情中显示每个英文单词的义项数，如 run (7)
+	Accessible  bool `json:"accessible"`  // 无障碍模式：黑白高对比度显示，不靠颜色传达信息，选中行前加文字标记
+
+	AudioURL    string `json:"audioURL"`    // 发音文件地址模板，{word} 会被替换为单词；为空时不启用发音
+	AudioPlayer string `json:"audioPlayer"` // 播放发音的命令（文件路径追加在最后），为空时自动查找常见播放器
+
+	ClipboardCommand string `json:"clipboardCommand"` // 复制到剪贴板的命令（文本从标准输入传入），为空时自动查找
+	ShareURL         string `json:"shareURL"`         // 查询服务（dict serve）的地址，设置后分享的是 /word 链接而不是 --open 命令
+
+	DictURL    string `json:"dictURL"`    // 找不到词典数据时从这里下载 ecdict.csv.gz，为空时不下载
+	DictSHA256 string `json:"dictSHA256"` // 下载的词典数据应有的 SHA-256 校验和，为空时只核对文件大小
+
+	Theme Theme `json:"theme"` // 界面配色
+
+	Keymap Keymap `json:"keymap"` // 快捷键绑定：动作名 → 按键名，未列出的动作使用默认按键
+
+	IdleTimeout int    `json:"idleTimeout"` // 无操作多少分钟后执行 idleAction，0 表示不启用
+	IdleAction  string `json:"idleAction"`  // 空闲超时后的动作：reset（回到初始界面）或 exit（退出程序）
+
+	AutoSaveInterval int `json:"autoSaveInterval"` // 后台保存用户数据的间隔（秒），0 表示只在退出时保存
+
+	NewWordsPerDay int `json:"newWordsPerDay"` // 测验中每天最多出现的新词数，其余都是到期的复习；0 表示只复习
+
+	LogLevel string `json:"logLevel"` // 诊断日志级别：debug、info、warn、error
+}
+
+// config 当前生效的配置
+var config = defaultConfig()
+
+// defaultConfig 返回没有配置文件时使用的默认配置
+func defaultConfig() Config {
+	return Config{
+		Limit:              100,
+		HistorySize:        1000,
+		HistoryMaxFileSize: 1024,
+		HistoryRotateKeep:  100,
+		HideProperNouns:    true,
+		Wildcards:          true,
+		ContainsMinLength:  3,
+		QueryTimeout:       2,
+		MaxDetailLength:    20000,
+		MaxChineseEntries:  30,
+		UserWords:          "userwords.csv",
+		SurpriseBNC:        "1-20000",
+		Encoding:           "auto",
+		KeepExactMatch:     true,
+		BNCDisplay:         bncDisplayRank,
+		PosBadges:          true,
+		TypeMarkers:        true,
+		Theme:              defaultTheme(),
+		ProgressBar:        defaultProgressStyle(),
+		IdleAction:         "reset",
+		AutoSaveInterval:   30,
+		NewWordsPerDay:     20,
+		LogLevel:           "warn",
+	}
+}
+
+// loadConfig 读取配置文件，文件不存在时返回默认配置，未填写的字段保持默认值
+func loadConfig(path string) (Config, error) {
+	cfg := defaultConfig()
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("无法读取配置文件 %s: %v", path, err)
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("配置文件 %s 格式错误: %v", path, err)
+	}
+	if cfg.Limit <= 0 {
+		return cfg, fmt.Errorf("配置文件 %s 中 limit 必须大于 0", path)
+	}
+	if err := validateDetailSections(cfg.DetailSections); err != nil {
+		return cfg, fmt.Errorf("配置文件 %s 中 %v", path, err)
+	}
+	if cfg.ContainsMinLength < 0 {
+		return cfg, fmt.Errorf("配置文件 %s 中 containsMinLength 不能小于 0", path)
+	}
+	if cfg.QueryTimeout < 0 {
+		return cfg, fmt.Errorf("配置文件 %s 中 queryTimeout 不能小于 0", path)
+	}
+	if cfg.MaxDetailLength < 0 {
+		return cfg, fmt.Errorf("配置文件 %s 中 maxDetailLength 不能小于 0", path)
+	}
+	if cfg.MaxChineseEntries < 0 {
+		return cfg, fmt.Errorf("配置文件 %s 中 maxChineseEntries 不能小于 0", path)
+	}
+	if err := validateEncoding(cfg.Encoding); err != nil {
+		return cfg, fmt.Errorf("配置文件 %s 中 %v", path, err)
+	}
+	if err := validateProfileName(cfg.Profile); err != nil {
+		return cfg, fmt.Errorf("配置文件 %s 中 %v", path, err)
+	}
+	if cfg.HistorySize <= 0 {
+		return cfg, fmt.Errorf("配置文件 %s 中 historySize 必须大于 0", path)
+	}
+	if cfg.HistoryMaxFileSize < 0 {
+		return cfg, fmt.Errorf("配置文件 %s 中 historyMaxFileSize 不能小于 0", path)
+	}
+	if cfg.HistoryRotateKeep < 0 {
+		return cfg, fmt.Errorf("配置文件 %s 中 historyRotateKeep 不能小于 0", path)
+	}
+	if cfg.SurpriseBNC != "" {
+		if _, _, err := parseRange(cfg.SurpriseBNC); err != nil {
+			return cfg, fmt.Errorf("配置文件 %s 中 surpriseBNC %v", path, err)
+		}
+	}
+	if err := validateIgnoreWords(cfg.IgnoreWords); err != nil {
+		return cfg, fmt.Errorf("配置文件 %s 中 %v", path, err)
+	}
+	if err := validateBNCDisplay(cfg.BNCDisplay); err != nil {
+		return cfg, fmt.Errorf("配置文件 %s 中 %v", path, err)
+	}
+	if err := cfg.Theme.validate(); err != nil {
+		return cfg, fmt.Errorf("配置文件 %s 中 %v", path, err)
+	}
+	if _, err := cfg.Keymap.bindings(); err != nil {
+		return cfg, fmt.Errorf("配置文件 %s 中 %v", path, err)
+	}
+	if _, err := cfg.Keymap.enterBehavior(); err != nil {
+		return cfg, fmt.Errorf("配置文件 %s 中 %v", path, err)
+	}
+	if cfg.IdleTimeout < 0 {
+		return cfg, fmt.Errorf("配置文件 %s 中 idleTimeout 不能小于 0", path)
+	}
+	if cfg.IdleAction != "reset" && cfg.IdleAction != "exit" {
+		return cfg, fmt.Errorf("配置文件 %s 中 idleAction 只能是 reset 或 exit", path)
+	}
+	if cfg.AutoSaveInterval < 0 {
+		return cfg, fmt.Errorf("配置文件 %s 中 autoSaveInterval 不能小于 0", path)
+	}
+	if cfg.NewWordsPerDay < 0 {
+		return cfg, fmt.Errorf("配置文件 %s 中 newWordsPerDay 不能小于 0", path)
+	}
+	if _, err := parseLogLevel(cfg.LogLevel); err != nil {
+		return cfg, fmt.Errorf("配置文件 %s 中 %v", path, err)
+	}
+	if err := validateShareURL(cfg.ShareURL); err != nil {
+		return cfg, fmt.Errorf("配置文件 %s 中 %v", path, err)
+	}
+	if err := validateDictURL(cfg.DictURL, cfg.DictSHA256); err != nil {
+		return cfg, fmt.Errorf("配置文件 %s 中 %v", path, err)
+	}
+	if err := cfg.ProgressBar.validate(); err != nil {
+		return cfg, fmt.Errorf("配置文件 %s 中 %v", path, err)
+	}
+	return cfg, nil
+}
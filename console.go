@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// plainOutput 为 true 时控制台只输出 ASCII 符号（不含 emoji、方框和方块字符）
+var plainOutput bool
+
+// plainReplacer 把初始化和进度信息中的 emoji、方框字符替换为 ASCII 版本
+var plainReplacer = strings.NewReplacer(
+	"━", "=",
+	"█", "#",
+	"→", "->",
+	"📚 ", "",
+	"✨ ", "* ",
+	"⏱️  ", "* ",
+	"💡 ", "* ",
+	"📦 ", "",
+	"🔨 ", "",
+	"🎉 ", "",
+	"📖 ", "",
+	"⏳ ", "",
+	"📊 ", "",
+	"🔄 ", "",
+	"📥 ", "",
+	"⏩ ", "",
+	"✅ ", "[OK] ",
+	"❌ ", "[ERROR] ",
+	"⚠️  ", "[WARN] ",
+)
+
+// consoleText 在纯文本模式下替换掉无法显示的字符
+func consoleText(s string) string {
+	if plainOutput {
+		return plainReplacer.Replace(s)
+	}
+	return s
+}
+
+// consolePrint 输出面向用户的提示信息
+func consolePrint(a ...interface{}) {
+	fmt.Print(consoleText(fmt.Sprint(a...)))
+}
+
+// consolePrintln 输出面向用户的提示信息并换行
+func consolePrintln(a ...interface{}) {
+	fmt.Print(consoleText(fmt.Sprintln(a...)))
+}
+
+// consolePrintf 按格式输出面向用户的提示信息
+func consolePrintf(format string, a ...interface{}) {
+	fmt.Print(consoleText(fmt.Sprintf(format, a...)))
+}
+
+// progressBar 在同一行重绘的进度条，可被多个协程并发更新；样式见配置文件中的 progressBar
+type progressBar struct {
+	label       string        // 进度条前的说明文字
+	width       int           // 进度条总格数
+	style       ProgressStyle // 填充字符、颜色和刷新方式
+	overwrite   bool          // 用 \r 在同一行重绘，为 false 时每 progressLineStep 个百分点输出一行
+	lastPercent int           // 上一次绘制时的百分比
+	start       time.Time     // 开始时间，用于估算剩余时间
+	mu          sync.Mutex
+}
+
+// etaMinElapsed 开始后多久才显示剩余时间，太早的估算波动很大
+const etaMinElapsed = time.Second
+
+// newProgressBar 创建进度条并绘制初始状态
+func newProgressBar(label string) *progressBar {
+	style := config.ProgressBar
+	p := &progressBar{label: label, width: style.barWidth(label), style: style, overwrite: style.overwrite(), lastPercent: -1, start: time.Now()}
+	p.Update(0, 1)
+	return p
+}
+
+// Update 根据已完成数量重绘进度条，百分比没有增加时不重绘
+func (p *progressBar) Update(done, total int) {
+	if total <= 0 {
+		return
+	}
+	if done > total {
+		done = total
+	}
+	percentage := done * 100 / total
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if percentage <= p.lastPercent {
+		return
+	}
+	// 逐行输出时只在跨过 10%、20% …… 时输出，0% 也输出一行表示已经开始；100% 留给 Finish 输出
+	if !p.overwrite && p.lastPercent >= 0 && (percentage/progressLineStep == p.lastPercent/progressLineStep || percentage == 100) {
+		return
+	}
+
+	// 按目前的平均速度估算剩余时间
+	suffix := ""
+	if elapsed := time.Since(p.start); done > 0 && elapsed >= etaMinElapsed {
+		remaining := time.Duration(float64(elapsed) * float64(total-done) / float64(done))
+		suffix = "剩余约 " + formatDuration(remaining)
+	}
+	p.draw(done*p.width/total, percentage, suffix)
+	p.lastPercent = percentage
+}
+
+// Finish 把进度条补全到 100%，显示总用时并换行
+func (p *progressBar) Finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.draw(p.width, 100, "用时 "+formatDuration(time.Since(p.start)))
+	p.Break()
+}
+
+// Break 结束进度条所在的行，之后的输出另起一行；逐行输出时每次绘制都已换行，不再输出空行
+func (p *progressBar) Break() {
+	if p.overwrite {
+		consolePrintln()
+	}
+}
+
+// draw 清除当前行并绘制 filled 格进度，suffix 显示在百分比之后
+func (p *progressBar) draw(filled, percentage int, suffix string) {
+	done := strings.Repeat(p.style.Fill, filled)
+	if p.style.colored() {
+		done = "\x1b[32m" + done + "\x1b[0m"
+	}
+	rest := strings.Repeat(p.style.Empty, p.width-filled)
+	if !p.overwrite {
+		consolePrintf("%s: [%s%s] %d%% %s\n", p.label, done, rest, percentage, suffix)
+		return
+	}
+	// 末尾补空格，覆盖上一次绘制时较长的文字
+	consolePrintf("\r%s: [%s%s] %d%% %-16s", p.label, done, rest, percentage, suffix)
+}
+
+// spinnerInterval 转圈提示两次重绘之间的最短间隔
+const spinnerInterval = 100 * time.Millisecond
+
+// spinnerFrames 转圈提示依次显示的字符，纯文本模式下使用 ASCII 版本
+var (
+	spinnerFrames      = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+	plainSpinnerFrames = []string{"|", "/", "-", "\\"}
+)
+
+// spinner 不知道总量时使用的进度提示：在说明文字后面转圈并显示已处理的数量，只能在一个协程中更新
+type spinner struct {
+	label    string    // 说明文字，已经输出在当前行
+	quiet    bool      // 进度条逐行输出时（见 ProgressStyle.redraw）不转圈，只留下说明文字
+	frame    int       // 下一次显示的字符
+	lastDraw time.Time // 上一次重绘的时间，避免频繁输出
+	width    int       // 上一次绘制的后缀长度，用于清除
+}
+
+// newSpinner 输出说明文字并返回转圈提示
+func newSpinner(label string) *spinner {
+	consolePrint(label)
+	return &spinner{label: label, quiet: !config.ProgressBar.overwrite()}
+}
+
+// Update 每隔 spinnerInterval 重绘一次，显示已处理 count 条；s 为 nil 时不做任何事
+func (s *spinner) Update(count int) {
+	if s == nil || s.quiet || time.Since(s.lastDraw) < spinnerInterval {
+		return
+	}
+	frames := spinnerFrames
+	if plainOutput {
+		frames = plainSpinnerFrames
+	}
+	suffix := fmt.Sprintf(" %s 已读取 %d 条", frames[s.frame%len(frames)], count)
+	s.frame++
+	s.lastDraw = time.Now()
+	consolePrint("\r" + s.label + suffix)
+	s.width = len(suffix)
+}
+
+// Stop 清除转圈和计数，只留下说明文字，之后的输出接在说明文字后面
+func (s *spinner) Stop() {
+	if s == nil || s.width == 0 {
+		return
+	}
+	consolePrint("\r" + s.label + strings.Repeat(" ", s.width) + "\r" + s.label)
+}
+
+// formatDuration 把时长格式化为「1分05秒」「12秒」这样的短文本，不足一秒按一秒显示
+func formatDuration(d time.Duration) string {
+	seconds := int((d + time.Second - 1) / time.Second)
+	if seconds < 60 {
+		return fmt.Sprintf("%d秒", seconds)
+	}
+	return fmt.Sprintf("%d分%02d秒", seconds/60, seconds%60)
+}
@@ -0,0 +1,25 @@
+package main
+
+import "testing"
+
+func TestConsoleTextPlain(t *testing.T) {
+	saved := plainOutput
+	plainOutput = true
+	defer func() { plainOutput = saved }()
+
+	tests := []struct {
+		text, want string
+	}{
+		{"📥 正在下载词典数据 https://example.com/ecdict.csv\n", "正在下载词典数据 https://example.com/ecdict.csv\n"},
+		{"      ⏩ 从上次中断处继续，跳过已写入的 3 个中文词\n", "      从上次中断处继续，跳过已写入的 3 个中文词\n"},
+		{"✅ 数据库创建完成", "[OK] 数据库创建完成"},
+		{"⚠️  磁盘空间不足", "[WARN] 磁盘空间不足"},
+		{"[██    ]", "[##    ]"},
+		{"- 英文数据库: 单词和释义（12.0 MB → 9.5 MB）\n", "- 英文数据库: 单词和释义（12.0 MB -> 9.5 MB）\n"},
+	}
+	for _, tt := range tests {
+		if got := consoleText(tt.text); got != tt.want {
+			t.Errorf("consoleText(%q) = %q，应为 %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -1,22 +1,50 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"regexp"
+	"runtime"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"syscall"
+	"time"
 	"unicode"
 
 	_ "modernc.org/sqlite"
 )
 
+// 数据库文件名（位于程序目录）
+const (
+	englishDBFile = "english_chinese.db"
+	chineseDBFile = "chinese_english.db"
+)
+
+// dictSource 正在导入的词典名称，写入 sources 表，详情中据此显示释义来源
+var dictSource = "ECDICT"
+
+// sourceName 从词典文件名得到词典名称，如 ecdict.csv.gz → ECDICT
+func sourceName(csvFile string) string {
+	name := filepath.Base(csvFile)
+	if i := strings.Index(name, "."); i > 0 {
+		name = name[:i]
+	}
+	return strings.ToUpper(name)
+}
+
+// examplesCSVFile 可选的例句数据文件，存在时在创建英文数据库后导入
+const examplesCSVFile = "examples.csv"
+
 // parseBNC 解析BNC词频，将空值、无效值或0视为最大值（排在最后）
 func parseBNC(bnc string) int {
 	bnc = strings.TrimSpace(bnc)
@@ -30,16 +58,9 @@ func parseBNC(bnc string) int {
 	return n
 }
 
-// CreateEnglishDB 创建英文到中文的数据库（优化并发版本）
-func CreateEnglishDB(csvFile, dbFile string) error {
-	fmt.Println("   📖 [1/2] 正在创建英文-中文数据库...")
-
-	// 打开CSV文件
-	file, err := os.Open(csvFile)
-	if err != nil {
-		return fmt.Errorf("无法打开CSV文件: %v", err)
-	}
-	defer file.Close()
+// CreateEnglishDB 从 CSV 内容创建英文到中文的数据库（优化并发版本），r 可以是普通文件，也可以是 gzip 解压流
+func CreateEnglishDB(ctx context.Context, r io.Reader, dbFile string) error {
+	consolePrintln("   📖 [1/2] 正在创建英文-中文数据库...")
 
 	// 创建SQLite数据库，启用 WAL 模式以支持并发
 	db, err := sql.Open("sqlite", dbFile+"?cache=shared&mode=rwc&_journal_mode=WAL")
@@ -52,6 +73,11 @@ func CreateEnglishDB(csvFile, dbFile string) error {
 	db.SetMaxOpenConns(1) // SQLite 写入最好用单连接
 	db.SetMaxIdleConns(1)
 
+	return populateEnglishDB(ctx, db, r)
+}
+
+// populateEnglishDB 在已打开的数据库中建表并写入 CSV 中的全部英文单词
+func populateEnglishDB(ctx context.Context, db *sql.DB, file io.Reader) error {
 	// 创建表 - 只保留必要字段
 	createTableSQL := `
 	CREATE TABLE IF NOT EXISTS words (
@@ -60,15 +86,33 @@ func CreateEnglishDB(csvFile, dbFile string) error {
 		phonetic TEXT,
 		definition TEXT,
 		translation TEXT,
-		bnc TEXT
+		bnc TEXT,
+		tag TEXT, -- 考试标签，如 "zk gk cet4"
+		phonetic_us TEXT, -- 美式音标，只有扩充过的词典才有
+		phonetic_uk TEXT, -- 英式音标
+		source_id INTEGER NOT NULL DEFAULT 0
+	);
+
+	-- 词典来源，合并多个词典时用于标注释义出处
+	CREATE TABLE IF NOT EXISTS sources (
+		id INTEGER PRIMARY KEY,
+		name TEXT NOT NULL UNIQUE
 	);
 	`
 
-	_, err = db.Exec(createTableSQL)
+	_, err := db.Exec(createTableSQL)
 	if err != nil {
 		return fmt.Errorf("无法创建表: %v", err)
 	}
 
+	var sourceID int64
+	if _, err := db.Exec(`INSERT OR IGNORE INTO sources (name) VALUES (?)`, dictSource); err != nil {
+		return fmt.Errorf("无法写入词典来源: %v", err)
+	}
+	if err := db.QueryRow(`SELECT id FROM sources WHERE name = ?`, dictSource).Scan(&sourceID); err != nil {
+		return fmt.Errorf("无法读取词典来源: %v", err)
+	}
+
 	// 创建索引 - 移除 frq 索引
 	indexSQL := `
 	CREATE INDEX IF NOT EXISTS idx_word ON words(word);
@@ -85,35 +129,18 @@ func CreateEnglishDB(csvFile, dbFile string) error {
 		return fmt.Errorf("无法设置PRAGMA: %v", err)
 	}
 
-	// 读取CSV数据
-	reader := csv.NewReader(file)
-
-	// 跳过表头
-	_, err = reader.Read()
-	if err != nil {
-		return fmt.Errorf("无法读取表头: %v", err)
-	}
-
 	// 读取所有记录到内存
-	fmt.Print("      ⏳ 正在读取词典数据...")
-	var allRecords [][]string
-	for {
-		record, err := reader.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			continue
-		}
-		if len(record) >= 13 {
-			allRecords = append(allRecords, record)
-		}
+	reading := newSpinner("      ⏳ 正在读取词典数据...")
+	allRecords, stats, err := readDictRecords(file, reading)
+	reading.Stop()
+	if err != nil {
+		return err
 	}
-	fmt.Printf(" 完成 (%d 条)\n", len(allRecords))
+	consolePrintf(" 完成 (%d 条)\n", len(allRecords))
+	stats.report()
 
 	// 并发处理参数
-	numWorkers := 4   // 减少工作协程数，因为数据库写入是瓶颈
-	batchSize := 1000 // 增加批次大小以提高效率
+	numWorkers, batchSize := conversionLimits()
 
 	// 用于统计
 	var totalCount int64
@@ -125,10 +152,7 @@ func CreateEnglishDB(csvFile, dbFile string) error {
 	recordChan := make(chan [][]string, numWorkers)
 
 	// 进度显示
-	fmt.Print("      📊 处理进度: [")
-	progressDots := 50
-	lastProgress := 0
-	var progressMutex sync.Mutex // 保护进度条的并发更新
+	bar := newProgressBar("      📊 处理进度")
 
 	// 启动工作协程
 	for i := 0; i < numWorkers; i++ {
@@ -140,32 +164,39 @@ func CreateEnglishDB(csvFile, dbFile string) error {
 				// 使用互斥锁保护数据库操作
 				dbMutex.Lock()
 
-				// 开始事务
-				tx, err := db.Begin()
+				// 开始事务（ctx 取消时事务会被自动回滚）
+				tx, err := db.BeginTx(ctx, nil)
 				if err != nil {
 					dbMutex.Unlock()
+					logWarnf("无法开始事务，跳过 %d 条记录: %v", len(batch), err)
 					continue
 				}
 
-				insertSQL := `INSERT INTO words (word, phonetic, definition, translation, bnc) 
-							  VALUES (?, ?, ?, ?, ?)`
+				insertSQL := `INSERT INTO words (word, phonetic, definition, translation, bnc, tag, phonetic_us, phonetic_uk, source_id) 
+							  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
 				stmt, err := tx.Prepare(insertSQL)
 				if err != nil {
 					tx.Rollback()
 					dbMutex.Unlock()
+					logWarnf("无法准备语句，跳过 %d 条记录: %v", len(batch), err)
 					continue
 				}
 
 				batchCount := 0
 				for _, record := range batch {
 					_, err = stmt.Exec(
-						record[0], // word
-						record[1], // phonetic
-						record[2], // definition
-						record[3], // translation
-						record[8], // bnc
+						record[0],  // word
+						record[1],  // phonetic
+						record[2],  // definition
+						record[3],  // translation
+						record[8],  // bnc
+						record[7],  // tag
+						record[13], // phonetic_us
+						record[14], // phonetic_uk
+						sourceID,
 					)
 					if err != nil {
+						logWarnf("写入单词 %q 失败: %v", record[0], err)
 						continue
 					}
 					batchCount++
@@ -176,52 +207,234 @@ func CreateEnglishDB(csvFile, dbFile string) error {
 				// 提交事务
 				err = tx.Commit()
 				dbMutex.Unlock()
-
-				// 更新计数和进度条
-				newCount := atomic.AddInt64(&totalCount, int64(batchCount))
-				progress := int(float64(newCount) / float64(totalRecords) * float64(progressDots))
-				percentage := int(float64(newCount) / float64(totalRecords) * 100)
-
-				// 更新进度条（需要加锁，因为多个协程可能同时更新）
-				progressMutex.Lock()
-				if progress > lastProgress {
-					// 清除之前的显示并重新绘制
-					fmt.Print("\r      📊 处理进度: [")
-					for i := 0; i < progress; i++ {
-						fmt.Print("█")
-					}
-					for i := progress; i < progressDots; i++ {
-						fmt.Print(" ")
+				if err != nil {
+					if ctx.Err() == nil {
+						logErrorf("提交事务失败，丢失 %d 条记录: %v", batchCount, err)
 					}
-					fmt.Printf("] %d%%", percentage)
-					lastProgress = progress
+					continue
 				}
-				progressMutex.Unlock()
+
+				// 更新计数和进度条（进度条内部加锁，多个协程可同时更新）
+				newCount := atomic.AddInt64(&totalCount, int64(batchCount))
+				bar.Update(int(newCount), int(totalRecords))
 			}
 		}(i)
 	}
 
-	// 分批发送数据
+	// 分批发送数据，取消时停止分发
+send:
 	for i := 0; i < len(allRecords); i += batchSize {
 		end := i + batchSize
 		if end > len(allRecords) {
 			end = len(allRecords)
 		}
-		recordChan <- allRecords[i:end]
+		select {
+		case recordChan <- allRecords[i:end]:
+		case <-ctx.Done():
+			break send
+		}
 	}
 
 	close(recordChan)
 	wg.Wait()
 
+	if err := ctx.Err(); err != nil {
+		consolePrintln()
+		return err
+	}
+
 	// 补全进度条
-	for i := lastProgress; i < progressDots; i++ {
-		fmt.Print("█")
+	bar.Finish()
+
+	consolePrintln("      ⏳ 正在创建包含匹配索引...")
+	if err := buildTrigramIndex(ctx, db); err != nil {
+		return err
+	}
+	if err := buildBNCPercentiles(ctx, db); err != nil {
+		return err
 	}
-	fmt.Printf("] 100%%\n")
-	fmt.Printf("      ✅ 英文数据库创建完成 (共 %d 条记录)\n", totalCount)
+	consolePrintf("      ✅ 英文数据库创建完成 (共 %d 条记录)\n", totalCount)
 	return nil
 }
 
+// dictColumnNames ECDICT 标准格式 CSV 的列，读取后的每条记录都按这个顺序排列
+var dictColumnNames = []string{
+	"word", "phonetic", "definition", "translation", "pos", "collins", "oxford",
+	"tag", "bnc", "frq", "exchange", "detail", "audio",
+}
+
+// dictColumns 标准格式的列数
+var dictColumns = len(dictColumnNames)
+
+// dictOptionalColumnNames 标准格式以外可以识别的列，排在标准列之后；扩充过的词典用它们分别提供美式和英式音标
+var dictOptionalColumnNames = []string{"phonetic_us", "phonetic_uk"}
+
+// csvReadStats 记录读取词典 CSV 时遇到的不规范行
+type csvReadStats struct {
+	variant   string // 根据表头识别出的文件格式
+	malformed int    // 无法解析或缺少单词而跳过的行
+	padded    int    // 列数不足、已补齐空列的行
+}
+
+// report 输出识别到的格式和不规范行的统计，没有问题时不输出统计
+func (s csvReadStats) report() {
+	if s.variant != "" {
+		consolePrintf("      💡 %s\n", s.variant)
+	}
+	if s.padded > 0 {
+		consolePrintf("      ⚠️  %d 行列数不足，已按空值补齐\n", s.padded)
+	}
+	if s.malformed > 0 {
+		consolePrintf("      ⚠️  %d 行格式错误，已跳过\n", s.malformed)
+	}
+}
+
+// dictLayout 描述 CSV 的列与标准格式的对应关系
+type dictLayout struct {
+	index   []int  // 标准格式中每一列在文件中的位置，-1 表示文件中没有该列
+	width   int    // 文件的列数
+	variant string // 格式说明
+}
+
+// detectDictLayout 根据表头识别 ECDICT 的格式变体
+//
+// 不同版本导出的文件列数和列顺序不完全相同（精简版缺少部分列，另一些版本多出自定义列），
+// 这里按列名重新对应；第一行不是表头时按标准列顺序读取，此时 hasHeader 返回 false
+func detectDictLayout(header []string) (layout dictLayout, hasHeader bool, err error) {
+	position := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := position[name]; !ok {
+			position[name] = i
+		}
+	}
+
+	recordColumns := dictColumns + len(dictOptionalColumnNames)
+	if _, ok := position["word"]; !ok {
+		layout = dictLayout{index: make([]int, recordColumns), width: dictColumns}
+		for i := range layout.index {
+			layout.index[i] = i
+			if i >= dictColumns {
+				layout.index[i] = -1
+			}
+		}
+		if len(header) == 1 && strings.ContainsAny(header[0], "\t;|") {
+			return layout, false, fmt.Errorf("无法识别词典格式：第一行只有 1 列，文件可能使用了制表符、分号或竖线分隔，词典需要以逗号分隔")
+		}
+		if len(header) < 4 {
+			return layout, false, fmt.Errorf("无法识别词典格式：第一行既不是表头，也不足 4 列")
+		}
+		layout.variant = "未找到表头，按 ECDICT 标准列顺序读取"
+		return layout, false, nil
+	}
+
+	layout = dictLayout{index: make([]int, recordColumns), width: len(header)}
+	var missing, optional []string
+	for i, name := range append(append([]string(nil), dictColumnNames...), dictOptionalColumnNames...) {
+		p, ok := position[name]
+		switch {
+		case ok:
+			layout.index[i] = p
+			if i >= dictColumns {
+				optional = append(optional, name)
+			}
+		case i < dictColumns:
+			layout.index[i] = -1
+			missing = append(missing, name)
+		default:
+			layout.index[i] = -1
+		}
+	}
+	extra := len(header) - (dictColumns - len(missing)) - len(optional)
+
+	switch {
+	case len(missing) == 0 && extra == 0:
+		layout.variant = fmt.Sprintf("检测到 ECDICT 标准格式（%d 列）", len(header))
+	case len(missing) == 0:
+		layout.variant = fmt.Sprintf("检测到 ECDICT 扩展格式（%d 列，多出的 %d 列已忽略）", len(header), extra)
+	default:
+		layout.variant = fmt.Sprintf("检测到 ECDICT 精简格式（%d 列，缺少 %s，相应内容留空）", len(header), strings.Join(missing, "、"))
+	}
+	if len(optional) > 0 {
+		layout.variant += "；包含 " + strings.Join(optional, "、") + " 列，详情中将分别显示美式和英式音标"
+	}
+	if layout.index[3] == -1 {
+		layout.variant += "；没有 translation 列，中文数据库将为空"
+	}
+	return layout, true, nil
+}
+
+// normalize 把文件中的一行按标准列顺序重新排列（可选列排在最后），文件中没有的列为空字符串
+func (l dictLayout) normalize(record []string) []string {
+	normalized := make([]string, len(l.index))
+	for i, p := range l.index {
+		if p >= 0 && p < len(record) {
+			normalized[i] = record[p]
+		}
+	}
+	return normalized
+}
+
+// readDictRecords 读取 ECDICT 格式 CSV 的全部记录（不含表头），每条记录都按 dictColumnNames、dictOptionalColumnNames 的顺序排列
+//
+// 实际使用的词典文件常有未转义的引号或列数不一致的行，这里放宽解析规则尽量保留数据：
+// 列数不足的行补齐空列，仍无法解析的行计入统计后跳过。progress 不为 nil 时在读取过程中显示已读取的条数
+func readDictRecords(r io.Reader, progress *spinner) ([][]string, csvReadStats, error) {
+	var stats csvReadStats
+
+	reader := csv.NewReader(r)
+	reader.LazyQuotes = true
+	reader.FieldsPerRecord = -1
+
+	// 读取表头，确定各列的位置
+	header, err := reader.Read()
+	if err != nil {
+		return nil, stats, fmt.Errorf("无法读取表头: %v", err)
+	}
+	layout, hasHeader, err := detectDictLayout(header)
+	if err != nil {
+		return nil, stats, err
+	}
+	stats.variant = layout.variant
+
+	var records [][]string
+	add := func(record []string) {
+		normalized := layout.normalize(record)
+		if strings.TrimSpace(normalized[0]) == "" {
+			stats.malformed++
+			return
+		}
+		if len(record) < layout.width {
+			stats.padded++
+		}
+		records = append(records, normalized)
+	}
+	if !hasHeader {
+		add(header)
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// 解析错误只影响当前行，可以继续读取后面的记录
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				stats.malformed++
+				continue
+			}
+			return nil, stats, fmt.Errorf("读取CSV失败: %v", err)
+		}
+		add(record)
+		if len(records)%1000 == 0 {
+			progress.Update(len(records))
+		}
+	}
+	return records, stats, nil
+}
+
 // extractChineseWords 从翻译中提取纯中文词汇
 func extractChineseWords(translation string) []string {
 	// 去除括号内容
@@ -262,16 +475,15 @@ func isAllChinese(text string) bool {
 	return true
 }
 
-// CreateChineseDB 创建中文到英文的反向数据库（优化版本，每个中文词一行记录）
-func CreateChineseDB(csvFile, dbFile string) error {
-	fmt.Println("   📖 [2/2] 正在创建中文-英文数据库...")
+// engEntry 表示反向映射中某个中文词对应的一条英文单词及其中文释义
+type engEntry struct {
+	word string
+	def  string
+}
 
-	// 打开CSV文件
-	file, err := os.Open(csvFile)
-	if err != nil {
-		return fmt.Errorf("无法打开CSV文件: %v", err)
-	}
-	defer file.Close()
+// CreateChineseDB 从 CSV 内容创建中文到英文的反向数据库（优化版本，每个中文词一行记录），r 的要求同 CreateEnglishDB
+func CreateChineseDB(ctx context.Context, r io.Reader, dbFile string) error {
+	consolePrintln("   📖 [2/2] 正在创建中文-英文数据库...")
 
 	// 创建SQLite数据库，启用 WAL 模式
 	db, err := sql.Open("sqlite", dbFile+"?cache=shared&mode=rwc&_journal_mode=WAL")
@@ -284,16 +496,37 @@ func CreateChineseDB(csvFile, dbFile string) error {
 	db.SetMaxOpenConns(1)
 	db.SetMaxIdleConns(1)
 
+	if err := markBuildState(db); err != nil {
+		return err
+	}
+	if err := populateChineseDB(ctx, db, r); err != nil {
+		return err
+	}
+	return clearBuildState(db)
+}
+
+// populateChineseDB 在已打开的数据库中建表并根据 CSV 中的中文翻译写入反向映射
+//
+// 每写入 chineseCheckpointSize 个中文词提交一次事务；数据库中已有的中文词（上次中断前提交的检查点）直接跳过
+func populateChineseDB(ctx context.Context, db *sql.DB, file io.Reader) error {
 	// 创建表 - 每个中文词一行，所有英文单词存在一个字段中
 	createTableSQL := `
 	CREATE TABLE IF NOT EXISTS chinese_words (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		chinese TEXT NOT NULL UNIQUE,
-		english_words TEXT NOT NULL
+		english_words TEXT NOT NULL,
+		pinyin TEXT NOT NULL DEFAULT ''
 	);
+
+	-- 汉字到中文词的倒排索引，用于加速包含匹配（LIKE '%x%' 无法使用普通索引）
+	CREATE TABLE IF NOT EXISTS chinese_chars (
+		ch TEXT NOT NULL,
+		word_id INTEGER NOT NULL,
+		PRIMARY KEY (ch, word_id)
+	) WITHOUT ROWID;
 	`
 
-	_, err = db.Exec(createTableSQL)
+	_, err := db.Exec(createTableSQL)
 	if err != nil {
 		return fmt.Errorf("无法创建表: %v", err)
 	}
@@ -301,6 +534,7 @@ func CreateChineseDB(csvFile, dbFile string) error {
 	// 创建索引
 	indexSQL := `
 	CREATE INDEX IF NOT EXISTS idx_chinese ON chinese_words(chinese);
+	CREATE INDEX IF NOT EXISTS idx_pinyin ON chinese_words(pinyin);
 	`
 	_, err = db.Exec(indexSQL)
 	if err != nil {
@@ -313,40 +547,42 @@ func CreateChineseDB(csvFile, dbFile string) error {
 		return fmt.Errorf("无法设置PRAGMA: %v", err)
 	}
 
-	// 读取CSV数据
-	reader := csv.NewReader(file)
-
-	// 跳过表头
-	_, err = reader.Read()
+	// 读取所有记录到内存，只保留有中文翻译的记录
+	reading := newSpinner("      ⏳ 正在读取词典数据...")
+	records, stats, err := readDictRecords(file, reading)
+	reading.Stop()
 	if err != nil {
-		return fmt.Errorf("无法读取表头: %v", err)
+		return err
 	}
-
-	// 读取所有记录到内存
-	fmt.Print("      ⏳ 正在读取词典数据...")
 	var allRecords [][]string
-	for {
-		record, err := reader.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			continue
-		}
-		if len(record) >= 13 && record[3] != "" {
+	for _, record := range records {
+		if record[3] != "" {
 			allRecords = append(allRecords, record)
 		}
 	}
-	fmt.Printf(" 完成 (%d 条)\n", len(allRecords))
+	consolePrintf(" 完成 (%d 条)\n", len(allRecords))
+	stats.report()
+
+	consolePrint("      🔄 正在构建反向索引...")
+	// 创建英文单词到BNC词频的映射，提高查询效率
+	bncMap := make(map[string]int)
+	for _, record := range allRecords {
+		englishWord := record[0]
+		if _, exists := bncMap[englishWord]; !exists {
+			bncMap[englishWord] = parseBNC(record[8])
+		}
+	}
 
-	fmt.Print("      🔄 正在构建反向索引...")
 	// 构建中文词到英文单词的映射
-	// key: 中文词, value: map[英文单词]中文释义
-	chineseMap := make(map[string]map[string]string)
+	// key: 中文词, value: map[小写英文单词]英文条目
+	// 英文单词按小写合并，使 "Apple" 和 "apple" 在同一中文词下只出现一次，
+	// 显示时保留词频最高（BNC 最小）的那种大小写形式
+	chineseMap := make(map[string]map[string]engEntry)
 
 	for _, record := range allRecords {
 		englishWord := record[0]
 		translation := record[3]
+		key := strings.ToLower(englishWord)
 
 		// 提取纯中文词汇
 		chineseWords := extractChineseWords(translation)
@@ -354,47 +590,100 @@ func CreateChineseDB(csvFile, dbFile string) error {
 		// 为每个中文词建立反向映射
 		for _, chWord := range chineseWords {
 			if chineseMap[chWord] == nil {
-				chineseMap[chWord] = make(map[string]string)
+				chineseMap[chWord] = make(map[string]engEntry)
 			}
-			// 如果该英文单词还没有记录，或者当前翻译更完整，则更新
-			if _, exists := chineseMap[chWord][englishWord]; !exists {
-				chineseMap[chWord][englishWord] = translation
+			existing, exists := chineseMap[chWord][key]
+			if !exists || bncMap[englishWord] < bncMap[existing.word] {
+				chineseMap[chWord][key] = engEntry{word: englishWord, def: translation}
 			}
 		}
 	}
-	fmt.Printf(" 完成 (%d 个中文词)\n", len(chineseMap))
+	consolePrintf(" 完成 (%d 个中文词)\n", len(chineseMap))
 
-	// 创建英文单词到BNC词频的映射，提高查询效率
-	bncMap := make(map[string]int)
-	for _, record := range allRecords {
-		englishWord := record[0]
-		if _, exists := bncMap[englishWord]; !exists {
-			bncMap[englishWord] = parseBNC(record[8])
+	// 拼音表是可选的，没有时拼音列留空
+	var pinyinTable map[rune]string
+	if _, err := os.Stat(pinyinTableFile); err == nil {
+		pinyinTable, err = loadPinyinTable(pinyinTableFile)
+		if err != nil {
+			return err
 		}
+		consolePrintf("      🔄 已读取拼音表 (%d 个汉字)\n", len(pinyinTable))
 	}
 
-	// 开始事务
-	tx, err := db.Begin()
+	// 上次生成中断前已提交的中文词，chinese 列的 UNIQUE 约束保证每个词只有一行
+	done := make(map[string]bool)
+	existing, err := db.QueryContext(ctx, `SELECT chinese FROM chinese_words`)
 	if err != nil {
-		return fmt.Errorf("无法开始事务: %v", err)
+		return fmt.Errorf("无法读取已写入的中文词: %v", err)
+	}
+	for existing.Next() {
+		var chWord string
+		if err := existing.Scan(&chWord); err != nil {
+			existing.Close()
+			return fmt.Errorf("无法读取已写入的中文词: %v", err)
+		}
+		done[chWord] = true
+	}
+	existing.Close()
+	if len(done) > 0 {
+		consolePrintf("      ⏩ 从上次中断处继续，跳过已写入的 %d 个中文词\n", len(done))
 	}
 
-	insertSQL := `INSERT INTO chinese_words (chinese, english_words) VALUES (?, ?)`
-	stmt, err := tx.Prepare(insertSQL)
-	if err != nil {
+	// chineseMap 的键互不相同，正常情况下不会冲突；万一写入时与已有的行冲突（例如以后对中文词做了规范化），
+	// 把英文单词追加到已有的行中而不是丢弃，RETURNING 在插入和合并时都返回行 id
+	insertSQL := `INSERT INTO chinese_words (chinese, english_words, pinyin) VALUES (?, ?, ?)
+		ON CONFLICT(chinese) DO UPDATE SET english_words = english_words || char(10) || excluded.english_words
+		RETURNING id`
+
+	// begin 开始一个检查点的事务并准备语句（ctx 取消时事务会被自动回滚），语句随事务提交或回滚一起关闭
+	var tx *sql.Tx
+	var stmt, charStmt *sql.Stmt
+	begin := func() error {
+		var err error
+		if tx, err = db.BeginTx(ctx, nil); err != nil {
+			return fmt.Errorf("无法开始事务: %v", err)
+		}
+		if stmt, err = tx.Prepare(insertSQL); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("无法准备语句: %v", err)
+		}
+		if charStmt, err = tx.Prepare(`INSERT OR IGNORE INTO chinese_chars (ch, word_id) VALUES (?, ?)`); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("无法准备语句: %v", err)
+		}
+		return nil
+	}
+	if err := begin(); err != nil {
+		return err
+	}
+
+	// 写入前后的行数差少于成功写入的次数，说明有中文词与已有的行冲突并被合并
+	var rowsBefore, rows int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM chinese_words`).Scan(&rowsBefore); err != nil {
 		tx.Rollback()
-		return fmt.Errorf("无法准备语句: %v", err)
+		return fmt.Errorf("无法统计中文词: %v", err)
 	}
-	defer stmt.Close()
 
 	// 进度显示
-	fmt.Print("      📊 写入进度: [")
-	progressDots := 50
-	lastProgress := 0
+	bar := newProgressBar("      📊 写入进度")
 	totalWords := len(chineseMap)
 	count := 0
+	skipped := 0
+	var failed []string    // 写入失败的中文词
+	var unindexed []string // 已写入但汉字索引写入失败的中文词
 
 	for chWord, engMap := range chineseMap {
+		if err := ctx.Err(); err != nil {
+			tx.Rollback()
+			consolePrintln()
+			return err
+		}
+		if done[chWord] {
+			skipped++
+			bar.Update(count+skipped, totalWords)
+			continue
+		}
+
 		// 收集英文单词信息：单词、释义、BNC词频
 		type engInfo struct {
 			word string
@@ -403,15 +692,15 @@ func CreateChineseDB(csvFile, dbFile string) error {
 		}
 		var engList []engInfo
 
-		for engWord, cnTranslation := range engMap {
+		for _, entry := range engMap {
 			// 从BNC映射中获取词频，默认为最大值
-			bnc, exists := bncMap[engWord]
+			bnc, exists := bncMap[entry.word]
 			if !exists {
 				bnc = 1 << 30
 			}
 			engList = append(engList, engInfo{
-				word: engWord,
-				def:  cnTranslation,
+				word: entry.word,
+				def:  entry.def,
 				bnc:  bnc,
 			})
 		}
@@ -431,37 +720,46 @@ func CreateChineseDB(csvFile, dbFile string) error {
 		// 用换行符连接所有英文单词
 		englishWords := strings.Join(englishEntries, "\n")
 
-		_, err = stmt.Exec(chWord, englishWords)
-		if err != nil {
+		var wordID int64
+		if err := stmt.QueryRow(chWord, englishWords, termPinyin(chWord, pinyinTable)).Scan(&wordID); err != nil {
+			logWarnf("写入中文词 %q 失败: %v", chWord, err)
+			failed = append(failed, chWord)
 			continue
 		}
 
+		// 为词中的每个汉字写入倒排索引；写入失败时词本身仍在数据库中，只是包含匹配可能找不到它
+		for _, r := range chWord {
+			if _, err := charStmt.Exec(string(r), wordID); err != nil {
+				logWarnf("写入中文词 %q 的汉字索引失败: %v", chWord, err)
+				unindexed = append(unindexed, chWord)
+				break
+			}
+		}
+
 		count++
 
 		// 更新进度条和百分比
-		progress := int(float64(count) / float64(totalWords) * float64(progressDots))
-		percentage := int(float64(count) / float64(totalWords) * 100)
-
-		if progress > lastProgress || count%100 == 0 {
-			// 清除当前行并重新绘制进度条
-			fmt.Print("\r      📊 写入进度: [")
-			for i := 0; i < progress; i++ {
-				fmt.Print("█")
+		bar.Update(count+skipped, totalWords)
+
+		if count%chineseCheckpointSize == 0 {
+			if err := tx.Commit(); err != nil {
+				consolePrintln()
+				return fmt.Errorf("提交事务失败: %v", err)
 			}
-			for i := progress; i < progressDots; i++ {
-				fmt.Print(" ")
+			if err := begin(); err != nil {
+				consolePrintln()
+				return err
 			}
-			fmt.Printf("] %d%%", percentage)
-			lastProgress = progress
 		}
 	}
 
 	// 确保显示100%
-	fmt.Print("\r      📊 写入进度: [")
-	for i := 0; i < progressDots; i++ {
-		fmt.Print("█")
+	bar.Finish()
+
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM chinese_words`).Scan(&rows); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("无法统计中文词: %v", err)
 	}
-	fmt.Printf("] 100%%\n")
 
 	// 提交事务
 	err = tx.Commit()
@@ -469,26 +767,330 @@ func CreateChineseDB(csvFile, dbFile string) error {
 		return fmt.Errorf("提交事务失败: %v", err)
 	}
 
-	fmt.Printf("      ✅ 中文数据库创建完成 (共 %d 个中文词)\n", count)
+	if merged := count - (rows - rowsBefore); merged > 0 {
+		consolePrintf("      ⚠️  %d 个中文词与已有的词重复，对应的英文单词已合并到同一行\n", merged)
+	}
+	if len(failed) > 0 {
+		consolePrintf("      ⚠️  %d 个中文词写入失败，未包含在数据库中（如 %q，原因见诊断日志）\n", len(failed), failed[0])
+	}
+	if len(unindexed) > 0 {
+		consolePrintf("      ⚠️  %d 个中文词的汉字索引写入失败，包含匹配可能找不到它们（如 %q，原因见诊断日志）\n", len(unindexed), unindexed[0])
+	}
+	consolePrintf("      ✅ 中文数据库创建完成 (共 %d 个中文词)\n", rows)
 	return nil
 }
 
+// ImportExamples 把可选的例句CSV（列：word,example[,translation]）导入英文数据库的 examples 表
+func ImportExamples(ctx context.Context, csvFile, dbFile string) error {
+	consolePrintln("   📖 正在导入例句数据...")
+
+	// 打开CSV文件
+	file, err := openCSV(csvFile)
+	if err != nil {
+		return fmt.Errorf("无法打开例句文件: %v", err)
+	}
+	defer file.Close()
+
+	db, err := sql.Open("sqlite", dbFile)
+	if err != nil {
+		return fmt.Errorf("无法打开数据库: %v", err)
+	}
+	defer db.Close()
+
+	createTableSQL := `
+	CREATE TABLE IF NOT EXISTS examples (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		word TEXT NOT NULL,
+		example TEXT NOT NULL,
+		translation TEXT
+	);
+	CREATE INDEX IF NOT EXISTS idx_examples_word ON examples(word);
+	`
+	if _, err := db.Exec(createTableSQL); err != nil {
+		return fmt.Errorf("无法创建例句表: %v", err)
+	}
+
+	reader := csv.NewReader(file)
+	reader.LazyQuotes = true
+	reader.FieldsPerRecord = -1 // 翻译列可选
+
+	// 跳过表头
+	if _, err := reader.Read(); err != nil {
+		return fmt.Errorf("无法读取表头: %v", err)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("无法开始事务: %v", err)
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO examples (word, example, translation) VALUES (?, ?, ?)`)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("无法准备语句: %v", err)
+	}
+	defer stmt.Close()
+
+	count := 0
+	var stats csvReadStats
+	for {
+		if err := ctx.Err(); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil || len(record) < 2 {
+			stats.malformed++
+			continue
+		}
+		word := strings.TrimSpace(record[0])
+		example := strings.TrimSpace(record[1])
+		if word == "" || example == "" {
+			stats.malformed++
+			continue
+		}
+		translation := ""
+		if len(record) >= 3 {
+			translation = strings.TrimSpace(record[2])
+		}
+		if _, err := stmt.Exec(word, example, translation); err != nil {
+			logWarnf("写入 %q 的例句失败: %v", word, err)
+			continue
+		}
+		count++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("提交事务失败: %v", err)
+	}
+
+	stats.report()
+	consolePrintf("      ✅ 例句导入完成 (共 %d 条)\n", count)
+	return nil
+}
+
+// lowPowerMode 为 true 时转换只用一个写入协程、较小的批次并限制为单核运行，速度更慢但 CPU 占用和发热更低
+var lowPowerMode bool
+
+// 写入英文数据库时的并发参数
+const (
+	defaultWorkers    = 4    // 工作协程数，数据库写入是瓶颈，更多协程没有帮助
+	defaultBatchSize  = 1000 // 每个事务写入的记录数
+	lowPowerWorkers   = 1
+	lowPowerBatchSize = 200
+	lowPowerMaxProcs  = 1 // 低功耗模式下 Go 运行时（包括垃圾回收）同时使用的 CPU 核数
+)
+
+// conversionLimits 返回写入英文数据库时的工作协程数和批次大小
+func conversionLimits() (workers, batchSize int) {
+	if lowPowerMode {
+		return lowPowerWorkers, lowPowerBatchSize
+	}
+	return defaultWorkers, defaultBatchSize
+}
+
+// tmpDBSuffix 生成过程中数据库文件名的后缀，检查通过后才重命名为正式文件名
+const tmpDBSuffix = ".tmp"
+
 // RunConverter 执行转换操作
+//
+// 数据库先写入 .tmp 文件，两个数据库都生成并通过完整性检查后才重命名为正式文件名，
+// 因此正式文件存在就说明它是完整的；中途出错、按 Ctrl+C 或程序崩溃时已有的数据库保持不变
 func RunConverter(csvFile string) error {
-	fmt.Println("开始创建英文到中文数据库...")
-	err := CreateEnglishDB(csvFile, "english_chinese.db")
+	// 转换过程中按 Ctrl+C 时取消转换：正在进行的事务会被回滚，已生成的临时文件会被删除
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// 协程数只限制了写入的并发，解析 CSV、构建索引和垃圾回收仍会用满所有核，因此同时限制运行时的核数
+	if lowPowerMode {
+		defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(lowPowerMaxProcs))
+		consolePrintln("低功耗模式：单协程写入，转换会比平时慢")
+	}
+
+	start := time.Now()
+	englishTmp, chineseTmp := englishDBFile+tmpDBSuffix, chineseDBFile+tmpDBSuffix
+	// 上次生成中途崩溃时留下的临时文件；同一份 CSV 未写完的中文数据库保留，生成时从最后一个检查点继续
+	buildFingerprint = csvFingerprint(csvFile)
+	removeDBFile(englishTmp)
+	if resumableChineseTmp(chineseTmp, buildFingerprint) == 0 {
+		removeDBFile(chineseTmp)
+	}
+
+	// 两个临时数据库都通过检查后才开始替换，任何一个检查失败时两个正式文件都保持原样
+	err := runConversion(ctx, csvFile, englishTmp, chineseTmp)
+	if err == nil {
+		err = checkDBFile(englishTmp)
+	}
+	if err == nil {
+		err = checkDBFile(chineseTmp)
+	}
+	if err == nil {
+		err = replaceDBFile(englishTmp, englishDBFile)
+	}
+	if err == nil {
+		err = replaceDBFile(chineseTmp, chineseDBFile)
+	}
+	if err != nil {
+		removeDBFile(englishTmp)
+		if ctx.Err() != nil {
+			removeDBFile(chineseTmp)
+			return fmt.Errorf("转换已取消，已删除未完成的数据库文件")
+		}
+		if saved := resumableChineseTmp(chineseTmp, buildFingerprint); saved > 0 {
+			consolePrintf("\n已写入的 %d 个中文词保留在 %s，再次生成时从这里继续\n", saved, chineseTmp)
+		} else {
+			removeDBFile(chineseTmp)
+		}
+		return err
+	}
+
+	logInfof("由 %s 生成数据库，用时 %v", csvFile, time.Since(start).Round(time.Millisecond))
+	consolePrintln("\n所有数据库创建完成！")
+	reportDBSizes(vacuumAfterBuild)
+	return nil
+}
+
+// withCSV 打开词典 CSV（或 .gz），把内容交给 create，结束后关闭文件
+func withCSV(csvFile string, create func(io.Reader) error) error {
+	file, err := openCSV(csvFile)
+	if err != nil {
+		return fmt.Errorf("无法打开CSV文件: %v", err)
+	}
+	defer file.Close()
+	return create(file)
+}
+
+// runConversion 依次生成英文数据库、导入例句并生成中文数据库，ctx 取消时尽快返回
+//
+// 两个数据库各读取一遍 CSV；csvFile 为 .gz 时每次都重新解压读取，不在磁盘上生成解压后的文件
+func runConversion(ctx context.Context, csvFile, englishFile, chineseFile string) error {
+	dictSource = sourceName(csvFile)
+	consolePrintln("开始创建英文到中文数据库...")
+	err := withCSV(csvFile, func(r io.Reader) error {
+		return CreateEnglishDB(ctx, r, englishFile)
+	})
 	if err != nil {
 		return fmt.Errorf("创建英文数据库失败: %v", err)
 	}
 
-	fmt.Println("\n开始创建中文到英文反向数据库...")
-	err = CreateChineseDB(csvFile, "chinese_english.db")
+	// 例句数据是可选的，没有例句文件时跳过
+	if _, err := os.Stat(examplesCSVFile); err == nil {
+		consolePrintln("\n开始导入例句...")
+		if err := ImportExamples(ctx, examplesCSVFile, englishFile); err != nil {
+			return fmt.Errorf("导入例句失败: %v", err)
+		}
+	}
+
+	consolePrintln("\n开始创建中文到英文反向数据库...")
+	err = withCSV(csvFile, func(r io.Reader) error {
+		return CreateChineseDB(ctx, r, chineseFile)
+	})
 	if err != nil {
 		return fmt.Errorf("创建中文反向数据库失败: %v", err)
 	}
 
-	fmt.Println("\n所有数据库创建完成！")
-	fmt.Println("- english_chinese.db: 英文到中文翻译")
-	fmt.Println("- chinese_english.db: 中文到英文翻译")
+	consolePrint("\n⏳ 正在收集查询统计信息...")
+	for _, file := range []string{englishFile, chineseFile} {
+		if err := analyzeDBFile(ctx, file); err != nil {
+			consolePrintln()
+			return err
+		}
+		if err := writeSchemaVersion(ctx, file); err != nil {
+			consolePrintln()
+			return err
+		}
+	}
+	consolePrintln(" 完成")
 	return nil
 }
+
+// openMemoryDatabases 用 CSV 文件在内存中生成英文和中文数据库，不在磁盘上留下任何文件
+//
+// 内存数据库只存在于单个连接中，因此连接池限制为一个连接且不会被回收
+func openMemoryDatabases(csvFile string) (english *sql.DB, chinese *sql.DB, err error) {
+	dictSource = sourceName(csvFile)
+	open := func(populate func(context.Context, *sql.DB, io.Reader) error) (*sql.DB, error) {
+		file, err := openCSV(csvFile)
+		if err != nil {
+			return nil, fmt.Errorf("无法打开CSV文件: %v", err)
+		}
+		defer file.Close()
+
+		db, err := sql.Open("sqlite", ":memory:")
+		if err != nil {
+			return nil, fmt.Errorf("无法创建数据库: %v", err)
+		}
+		db.SetMaxOpenConns(1)
+		db.SetMaxIdleConns(1)
+		db.SetConnMaxLifetime(0)
+
+		if err := populate(context.Background(), db, file); err != nil {
+			db.Close()
+			return nil, err
+		}
+		if err := setSchemaVersion(context.Background(), db); err != nil {
+			db.Close()
+			return nil, err
+		}
+		return db, nil
+	}
+
+	if english, err = open(populateEnglishDB); err != nil {
+		return nil, nil, fmt.Errorf("创建英文数据库失败: %v", err)
+	}
+	if chinese, err = open(populateChineseDB); err != nil {
+		english.Close()
+		return nil, nil, fmt.Errorf("创建中文反向数据库失败: %v", err)
+	}
+	return english, chinese, nil
+}
+
+// checkDBFile 检查临时数据库的完整性，通过后才能用 replaceDBFile 替换正式的数据库
+//
+// 检查前先把 WAL 日志合并回主文件，之后重命名的就是完整的单个文件。
+// 使用 quick_check 而不是 integrity_check：它同样检查页面和记录是否损坏，只是不核对索引内容，耗时约为后者的四分之一
+func checkDBFile(tmpFile string) error {
+	db, err := sql.Open("sqlite", tmpFile)
+	if err != nil {
+		return fmt.Errorf("无法打开数据库 %s: %v", tmpFile, err)
+	}
+	if _, err := db.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		db.Close()
+		return fmt.Errorf("无法写入数据库 %s: %v", tmpFile, err)
+	}
+	var result string
+	if err := db.QueryRow("PRAGMA quick_check").Scan(&result); err != nil {
+		db.Close()
+		return fmt.Errorf("无法检查数据库 %s: %v", tmpFile, err)
+	}
+	if err := db.Close(); err != nil {
+		return fmt.Errorf("无法关闭数据库 %s: %v", tmpFile, err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("数据库 %s 完整性检查失败: %s", tmpFile, result)
+	}
+	return nil
+}
+
+// replaceDBFile 把通过 checkDBFile 检查的临时数据库重命名为正式文件名，替换已有的数据库
+func replaceDBFile(tmpFile, dbFile string) error {
+	// 旧数据库残留的日志文件不能留下，否则会被当作新数据库的日志
+	for _, suffix := range []string{"-wal", "-shm", "-journal"} {
+		os.Remove(dbFile + suffix)
+	}
+	if err := os.Rename(tmpFile, dbFile); err != nil {
+		return fmt.Errorf("无法重命名数据库 %s: %v", tmpFile, err)
+	}
+	return nil
+}
+
+// removeDBFile 删除数据库文件及 SQLite 的日志文件
+func removeDBFile(path string) {
+	for _, suffix := range []string{"", "-wal", "-shm", "-journal"} {
+		os.Remove(path + suffix)
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"modernc.org/sqlite"
+)
+
+// nearDuplicatesCSV 中 苹果 和 苹·果 只差一个间隔号，在忽略间隔号的排序规则下是同一个中文词
+const nearDuplicatesCSV = "testdata/near_duplicates.csv"
+
+func init() {
+	// 比较时忽略间隔号，用来在测试中制造 chineseMap 中不同、写入数据库时却冲突的中文词
+	sqlite.MustRegisterCollationUtf8("ignore_dot", func(a, b string) int {
+		return strings.Compare(strings.ReplaceAll(a, "·", ""), strings.ReplaceAll(b, "·", ""))
+	})
+}
+
+// openChineseTestDB 打开内存数据库并执行 setup（如预先建表），再用 csvFile 写入中文反向映射，返回写入时的控制台输出
+func openChineseTestDB(t *testing.T, csvFile, setup string) (*sql.DB, string) {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxLifetime(0)
+	t.Cleanup(func() { db.Close() })
+	if _, err := db.Exec(setup); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := openCSV(csvFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	var populateErr error
+	output := captureStdout(t, func() {
+		populateErr = populateChineseDB(context.Background(), db, file)
+	})
+	if populateErr != nil {
+		t.Fatal(populateErr)
+	}
+	return db, output
+}
+
+// captureStdout 返回 fn 执行期间写到标准输出的内容
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	done := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		done <- string(b)
+	}()
+	defer func() { os.Stdout = stdout }()
+	fn()
+	w.Close()
+	return <-done
+}
+
+func TestPopulateChineseDBMergesNearDuplicates(t *testing.T) {
+	// 与 populateChineseDB 建的表相同，只是 chinese 列忽略间隔号，苹果 和 苹·果 冲突
+	db, output := openChineseTestDB(t, nearDuplicatesCSV, `CREATE TABLE chinese_words (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		chinese TEXT NOT NULL UNIQUE COLLATE ignore_dot,
+		english_words TEXT NOT NULL,
+		pinyin TEXT NOT NULL DEFAULT ''
+	)`)
+
+	rows, err := db.Query(`SELECT id, english_words FROM chinese_words WHERE chinese = '苹果'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int64
+	var english string
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id, &english); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if len(ids) != 1 {
+		t.Fatalf("苹果 有 %d 行，冲突的中文词应合并为一行", len(ids))
+	}
+	for _, word := range []string{"apple（", "apfel（"} {
+		if !strings.Contains(english, word) {
+			t.Errorf("合并后的英文单词 %q 中缺少 %s", english, strings.TrimSuffix(word, "（"))
+		}
+	}
+	if !strings.Contains(output, "1 个中文词与已有的词重复") {
+		t.Errorf("没有报告合并的中文词，输出为:\n%s", output)
+	}
+
+	// 合并后的行同样写入了汉字索引
+	var indexed int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM chinese_chars WHERE word_id = ? AND ch IN ('苹', '果')`, ids[0]).Scan(&indexed); err != nil {
+		t.Fatal(err)
+	}
+	if indexed != 2 {
+		t.Errorf("苹果 的汉字索引有 %d 个汉字，应为 2 个", indexed)
+	}
+}
+
+func TestPopulateChineseDBReportsIndexErrors(t *testing.T) {
+	// 写入 梨 的汉字索引时出错，梨 本身仍应写入并在输出中报告
+	db, output := openChineseTestDB(t, nearDuplicatesCSV, `CREATE TABLE chinese_chars (
+		ch TEXT NOT NULL,
+		word_id INTEGER NOT NULL,
+		PRIMARY KEY (ch, word_id)
+	) WITHOUT ROWID;
+	CREATE TRIGGER fail_pear BEFORE INSERT ON chinese_chars WHEN NEW.ch = '梨'
+	BEGIN SELECT RAISE(ABORT, 'disk full'); END`)
+
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM chinese_words WHERE chinese = '梨'`).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("汉字索引写入失败的 梨 没有写入数据库")
+	}
+	if !strings.Contains(output, "1 个中文词的汉字索引写入失败") || !strings.Contains(output, `"梨"`) {
+		t.Errorf("没有报告汉字索引写入失败，输出为:\n%s", output)
+	}
+}
+
+func TestPopulateChineseDBMergesCasingVariants(t *testing.T) {
+	// fixtureCSV 中 apple 和 Apple 的释义都有 苹果，只有 apple 有词频
+	db, _ := openChineseTestDB(t, fixtureCSV, `SELECT 1`)
+
+	rows, err := db.Query(`SELECT chinese, english_words FROM chinese_words`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	found := false
+	for rows.Next() {
+		var chinese, englishWords string
+		if err := rows.Scan(&chinese, &englishWords); err != nil {
+			t.Fatal(err)
+		}
+		// 同一中文词下不会有只差大小写的英文单词
+		seen := make(map[string]string)
+		for _, entry := range strings.Split(englishWords, "\n") {
+			word, _, _ := strings.Cut(entry, "（")
+			if prev, ok := seen[strings.ToLower(word)]; ok {
+				t.Errorf("%s 下同时有 %s 和 %s", chinese, prev, word)
+			}
+			seen[strings.ToLower(word)] = word
+		}
+		if chinese == "苹果" {
+			found = true
+			if seen["apple"] != "apple" {
+				t.Errorf("苹果 下应保留词频更高的 apple，english_words 为:\n%s", englishWords)
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if !found {
+		t.Fatal("没有写入 苹果")
+	}
+}
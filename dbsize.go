@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+)
+
+// vacuumAfterBuild 为 true 时生成数据库后执行 VACUUM，回收并发写入和建索引留下的空闲页（--vacuum）
+var vacuumAfterBuild bool
+
+// formatFileSize 把字节数格式化为便于阅读的大小，如 312.5 MB
+func formatFileSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value, suffix := float64(n)/unit, "KB"
+	for _, s := range []string{"MB", "GB"} {
+		if value < unit {
+			break
+		}
+		value, suffix = value/unit, s
+	}
+	return fmt.Sprintf("%.1f %s", value, suffix)
+}
+
+// fileSize 返回文件大小，文件不存在或无法读取时返回 0
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// vacuumDBFile 对数据库执行 VACUUM 并把 WAL 日志合并回主文件，返回执行前后的文件大小
+func vacuumDBFile(path string) (before, after int64, err error) {
+	before = fileSize(path)
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return before, before, fmt.Errorf("无法打开数据库 %s: %v", path, err)
+	}
+	defer db.Close()
+	if _, err := db.Exec("VACUUM"); err != nil {
+		return before, before, fmt.Errorf("整理数据库 %s 失败: %v", path, err)
+	}
+	if _, err := db.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return before, fileSize(path), fmt.Errorf("无法合并 %s 的 WAL 日志: %v", path, err)
+	}
+	return before, fileSize(path), nil
+}
+
+// reportDBSizes 输出生成的数据库文件及其大小；vacuum 为 true 时先执行 VACUUM，并输出整理前后的大小
+//
+// VACUUM 失败不影响已经生成好的数据库，只给出警告
+func reportDBSizes(vacuum bool) {
+	files := []struct{ name, description string }{
+		{englishDBFile, "英文到中文翻译"},
+		{chineseDBFile, "中文到英文翻译"},
+	}
+	if vacuum {
+		consolePrintln("\n⏳ 正在整理数据库文件（VACUUM）...")
+	}
+	var total int64
+	for _, f := range files {
+		if !vacuum {
+			size := fileSize(f.name)
+			total += size
+			consolePrintf("- %s: %s（%s）\n", f.name, f.description, formatFileSize(size))
+			continue
+		}
+		before, after, err := vacuumDBFile(f.name)
+		total += after
+		if err != nil {
+			consolePrintf("⚠️  %v\n", err)
+		}
+		consolePrintf("- %s: %s（%s → %s）\n", f.name, f.description, formatFileSize(before), formatFileSize(after))
+	}
+	consolePrintf("  合计 %s\n", formatFileSize(total))
+}
+
+// analyzeDBFile 对数据库执行 ANALYZE，把各索引的统计信息写入 sqlite_stat1，供查询规划器选择索引
+func analyzeDBFile(ctx context.Context, path string) error {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return fmt.Errorf("无法打开数据库 %s: %v", path, err)
+	}
+	defer db.Close()
+	if _, err := db.ExecContext(ctx, "ANALYZE"); err != nil {
+		return fmt.Errorf("无法收集 %s 的统计信息: %v", path, err)
+	}
+	return nil
+}
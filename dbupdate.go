@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// errDatabaseUpdating 数据库正在更新时搜索和详情查询返回的错误，界面据此显示提示而不是错误
+var errDatabaseUpdating = errors.New("数据库更新中...")
+
+// dbUpdating 为 true 时数据库正在被替换，新的查询直接返回 errDatabaseUpdating
+var dbUpdating atomic.Bool
+
+// dbWatchInterval 检查后台是否在重新生成数据库的间隔
+const dbWatchInterval = 2 * time.Second
+
+// databaseReady 数据库可以查询时返回 nil，正在更新时返回 errDatabaseUpdating
+func databaseReady() error {
+	if dbUpdating.Load() {
+		return errDatabaseUpdating
+	}
+	return nil
+}
+
+// reopenMutex 保证后台检查和手动重新加载不会同时替换数据库
+var reopenMutex sync.Mutex
+
+// reopenDatabases 重新打开数据库文件，让正在运行的程序改用新生成的数据
+//
+// 替换期间新的查询返回 errDatabaseUpdating；旧数据库的 Close 会等待已经开始的查询结束后再关闭。
+// 打开失败时保留原来的数据库
+func reopenDatabases() error {
+	reopenMutex.Lock()
+	defer reopenMutex.Unlock()
+	dbUpdating.Store(true)
+	defer dbUpdating.Store(false)
+
+	oldEnglish, oldChinese := englishDB, chineseDB
+	if err := openDatabases(); err != nil {
+		return err
+	}
+	oldEnglish.Close()
+	oldChinese.Close()
+	detailCache.clear()
+	return nil
+}
+
+// rebuildInProgress 检查是否有生成中的临时数据库文件（例如另一个终端中正在运行的 dict build -force）
+func rebuildInProgress() bool {
+	for _, file := range []string{englishDBFile, chineseDBFile} {
+		if _, err := os.Stat(file + tmpDBSuffix); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// dbModTimes 返回两个数据库文件的修改时间，用于判断文件是否被替换
+func dbModTimes() [2]time.Time {
+	var times [2]time.Time
+	for i, file := range []string{englishDBFile, chineseDBFile} {
+		if info, err := os.Stat(file); err == nil {
+			times[i] = info.ModTime()
+		}
+	}
+	return times
+}
+
+// watchDatabaseRebuild 在后台定期检查数据库是否正在被其他进程重新生成
+//
+// 生成期间继续使用旧数据库，开始时调用一次 onStart；临时文件消失后，如果正式文件已被替换就重新打开数据库，
+// 以 updated 为 true 和 reopenDatabases 的结果调用 onDone，生成失败（正式文件没有变化）时 updated 为 false。
+// 两个回调都在后台协程中调用。返回的函数用于停止检查
+func watchDatabaseRebuild(interval time.Duration, onStart func(), onDone func(updated bool, err error)) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		building := false
+		modTimes := dbModTimes()
+		for {
+			select {
+			case <-ticker.C:
+				if rebuildInProgress() {
+					if !building {
+						building = true
+						logInfof("检测到数据库正在重新生成，继续使用旧数据库")
+						onStart()
+					}
+					continue
+				}
+				if !building {
+					continue
+				}
+				building = false
+				if current := dbModTimes(); current != modTimes {
+					modTimes = current
+					err := reopenDatabases()
+					if err != nil {
+						logErrorf("切换到新数据库失败: %v", err)
+					} else {
+						logInfof("已切换到重新生成的数据库")
+					}
+					onDone(true, err)
+				} else {
+					logWarnf("数据库重新生成未完成，正式文件没有变化")
+					onDone(false, nil)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
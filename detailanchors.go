@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// sectionRegionPrefix 详情中栏目标题的 tview 区域名前缀，区域名为 section-栏目名
+const sectionRegionPrefix = "section-"
+
+// sectionRegex 从渲染好的详情文本中找出栏目标题，第一组为栏目名
+var sectionRegex = regexp.MustCompile(`\["section-([a-z]+)"\]`)
+
+// detailAnchors 详情面板中依次出现的栏目，第 i 个对应区域 section-{detailAnchors[i]}
+var detailAnchors []string
+
+// anchorSection 把栏目第一行开头的黄色标题（如 [yellow]音标:[-]）包进区域 section-name，用于在栏目间跳转
+func anchorSection(name string, lines []string) []string {
+	if len(lines) == 0 || !strings.HasPrefix(lines[0], "[yellow]") {
+		return lines
+	}
+	end := strings.Index(lines[0], "[-]")
+	if end < 0 {
+		return lines
+	}
+	end += len("[-]")
+	lines[0] = `["` + sectionRegionPrefix + name + `"]` + lines[0][:end] + `[""]` + lines[0][end:]
+	return lines
+}
+
+// updateDetailAnchors 在详情文本更新后重新收集其中的栏目（需在主线程中调用）
+func updateDetailAnchors(detail string) {
+	detailAnchors = nil
+	for _, m := range sectionRegex.FindAllStringSubmatch(detail, -1) {
+		detailAnchors = append(detailAnchors, m[1])
+	}
+}
+
+// sectionTitle 返回栏目在详情中显示的标题，用于状态栏
+func sectionTitle(name string) string {
+	return tview.Escape(strings.TrimSuffix(detailView.GetRegionText(sectionRegionPrefix+name), ":"))
+}
+
+// jumpSection 高亮下一个（dir 为 1）或上一个（dir 为 -1）栏目的标题并滚动到它；
+// 当前没有高亮的栏目时向后跳到第一个、向前跳到最后一个
+func jumpSection(dir int) {
+	if len(detailAnchors) == 0 {
+		setStatus("[yellow]当前详情没有可以跳转的栏目[-]")
+		return
+	}
+	current := -1
+	if highlights := detailView.GetHighlights(); len(highlights) > 0 {
+		for i, name := range detailAnchors {
+			if highlights[0] == sectionRegionPrefix+name {
+				current = i
+			}
+		}
+	}
+	next := current + dir
+	switch {
+	case current < 0 && dir < 0:
+		next = len(detailAnchors) - 1
+	case next < 0:
+		next = len(detailAnchors) - 1
+	case next >= len(detailAnchors):
+		next = 0
+	}
+
+	// 高亮栏目时不会打开单词，selectingLink 只是让高亮回调忽略这次变化
+	selectingLink = true
+	detailView.Highlight(sectionRegionPrefix + detailAnchors[next]).ScrollToHighlight()
+	selectingLink = false
+	setStatus(fmt.Sprintf("%s（%d / %d）", sectionTitle(detailAnchors[next]), next+1, len(detailAnchors)))
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+)
+
+// detailCacheSize 详情缓存最多保存的单词数，超出时丢弃最久没有用到的
+const detailCacheSize = 64
+
+// prefetchRadius 在列表中移动时预先渲染选中项上下各几个单词的详情
+const prefetchRadius = 3
+
+// detailKey 缓存的详情对应的单词和影响渲染结果的显示状态，由 newDetailKey 在主线程中记下，
+// renderDetail 只按键中的状态渲染，后台渲染期间切换显示方式不会让缓存的内容与键不符
+type detailKey struct {
+	word        string
+	split       bool   // 中文释义分栏显示
+	full        bool   // 显示完整详情（不限条数）
+	phonetic    bool   // 发音练习模式
+	emphasis    bool   // 加强显示词头
+	firstLookup string // 首次查询日期，第一次查询后详情末尾会多出一行
+}
+
+// renderedDetail 渲染好的详情文本，与 renderDetail 的返回值相同
+type renderedDetail struct {
+	main, side string
+}
+
+// detailLRU 按最近使用顺序淘汰的详情缓存，可被多个协程并发访问
+type detailLRU struct {
+	mu    sync.Mutex
+	order *list.List // 最近用到的在前，元素为 *detailEntry
+	items map[detailKey]*list.Element
+}
+
+// detailEntry detailLRU 中的一项
+type detailEntry struct {
+	key    detailKey
+	detail renderedDetail
+}
+
+// detailCache 单词列表中各单词渲染好的详情，数据库或用户词表重新加载时清空
+var detailCache = &detailLRU{order: list.New(), items: make(map[detailKey]*list.Element)}
+
+// prefetchVersion 预渲染的版本号，每次选中项或列表变化时递增，正在进行的旧预渲染随即停止
+var prefetchVersion int64
+
+// get 返回缓存的详情并把它移到最前
+func (c *detailLRU) get(key detailKey) (renderedDetail, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.items[key]
+	if !ok {
+		return renderedDetail{}, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*detailEntry).detail, true
+}
+
+// contains 判断详情是否已经缓存，不改变使用顺序
+func (c *detailLRU) contains(key detailKey) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.items[key]
+	return ok
+}
+
+// put 保存详情，超出 detailCacheSize 时丢弃最久没有用到的一项
+func (c *detailLRU) put(key detailKey, detail renderedDetail) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[key]; ok {
+		e.Value.(*detailEntry).detail = detail
+		c.order.MoveToFront(e)
+		return
+	}
+	c.items[key] = c.order.PushFront(&detailEntry{key: key, detail: detail})
+	if c.order.Len() > detailCacheSize {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*detailEntry).key)
+	}
+}
+
+// clear 清空缓存
+func (c *detailLRU) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	c.items = make(map[detailKey]*list.Element)
+}
+
+// newDetailKey 记下当前的显示状态作为缓存的键（需在主线程中调用）
+func newDetailKey(word string, split, full bool) detailKey {
+	return detailKey{
+		word:        word,
+		split:       split,
+		full:        full,
+		phonetic:    phoneticMode,
+		emphasis:    emphasisMode,
+		firstLookup: firstLookupLine(word),
+	}
+}
+
+// cachedRenderDetail 先在缓存中查找详情，没有时调用 renderDetail 渲染并缓存（出错的结果不缓存）
+func cachedRenderDetail(key detailKey) (main string, side string, err error) {
+	if d, ok := detailCache.get(key); ok {
+		return d.main, d.side, nil
+	}
+	main, side, err = renderDetail(key)
+	if err == nil {
+		detailCache.put(key, renderedDetail{main: main, side: side})
+	}
+	return main, side, err
+}
+
+// cancelPrefetch 停止正在进行的预渲染，列表内容被替换时调用
+func cancelPrefetch() {
+	atomic.AddInt64(&prefetchVersion, 1)
+}
+
+// prefetchDetails 在后台预先渲染列表第 index 行上下各 prefetchRadius 个单词的详情，
+// 离选中项近的先渲染，已经缓存的跳过；选中项或列表再次变化时，尚未渲染的不再继续（需在主线程中调用）
+//
+// 对比模式（固定了单词）下显示的是两个单词的对照，不预渲染
+func prefetchDetails(index int) {
+	version := atomic.AddInt64(&prefetchVersion, 1)
+	if pinnedWord != "" {
+		return
+	}
+
+	var keys []detailKey
+	for d := 1; d <= prefetchRadius; d++ {
+		for _, row := range []int{index + d, index - d} {
+			word := wordAt(row)
+			if word == "" || word == expandedWord {
+				continue
+			}
+			if key := newDetailKey(word, splitActive, false); !detailCache.contains(key) {
+				keys = append(keys, key)
+			}
+		}
+	}
+	if len(keys) == 0 {
+		return
+	}
+
+	go func() {
+		for _, key := range keys {
+			if atomic.LoadInt64(&prefetchVersion) != version {
+				return
+			}
+			cachedRenderDetail(key)
+		}
+	}()
+}
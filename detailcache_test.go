@@ -0,0 +1,122 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCachedRenderDetailUsesKeyState(t *testing.T) {
+	useFixtureDatabases(t)
+	detailCache.clear()
+	t.Cleanup(func() {
+		detailCache.clear()
+		emphasisMode, phoneticMode = false, false
+	})
+
+	// plain 和 emphasized 是两种显示方式下 apple 的详情，用于判断缓存的内容是按哪种方式渲染的
+	plain, _, err := renderDetail(detailKey{word: "apple"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	emphasized, _, err := renderDetail(detailKey{word: "apple", emphasis: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	phonetic, _, err := renderDetail(detailKey{word: "apple", phonetic: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain == emphasized || plain == phonetic {
+		t.Fatal("加强显示和发音练习模式下的详情应与默认显示不同")
+	}
+
+	tests := []struct {
+		name                   string
+		emphasis, phonetic     bool // 记下键时的状态
+		toEmphasis, toPhonetic bool // 渲染时（预渲染协程运行期间）已切换成的状态
+		want                   string
+	}{
+		{"切换为加强显示", false, false, true, false, plain},
+		{"切换为默认显示", true, false, false, false, emphasized},
+		{"切换为发音练习", false, false, false, true, plain},
+		{"退出发音练习", false, true, false, false, phonetic},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detailCache.clear()
+			emphasisMode, phoneticMode = tt.emphasis, tt.phonetic
+			key := newDetailKey("apple", false, false)
+
+			// 键已经记下，还没渲染时按下 F11 或 F12
+			emphasisMode, phoneticMode = tt.toEmphasis, tt.toPhonetic
+			if _, _, err := cachedRenderDetail(key); err != nil {
+				t.Fatal(err)
+			}
+
+			cached, ok := detailCache.get(key)
+			if !ok {
+				t.Fatal("渲染的详情没有缓存")
+			}
+			if cached.main != tt.want {
+				t.Errorf("缓存在 emphasis=%v phonetic=%v 下的详情是按切换后的状态渲染的:\n%s", key.emphasis, key.phonetic, cached.main)
+			}
+		})
+	}
+}
+
+func TestPrefetchDetailsToggleMidway(t *testing.T) {
+	useFixtureDatabases(t)
+	detailCache.clear()
+	searchMutex.Lock()
+	searchResults = []string{"apple", "applet", "applejack", "pineapple", "dog", "give", "run"}
+	searchMutex.Unlock()
+	t.Cleanup(func() {
+		cancelPrefetch()
+		detailCache.clear()
+		emphasisMode, phoneticMode = false, false
+		searchMutex.Lock()
+		searchResults = nil
+		searchMutex.Unlock()
+	})
+
+	// 预渲染第 0 行下面的单词，协程开始后立即切换加强显示（测试所在的协程相当于主线程）
+	emphasisMode = false
+	prefetchDetails(0)
+	emphasisMode = true
+
+	var keys []detailKey
+	for _, word := range []string{"applet", "applejack", "pineapple"} {
+		keys = append(keys, detailKey{word: word})
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for _, key := range keys {
+		for !detailCache.contains(key) {
+			if time.Now().After(deadline) {
+				t.Fatalf("%s 的详情没有预渲染", key.word)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	for _, key := range keys {
+		cached, _ := detailCache.get(key)
+		want, _, err := renderDetail(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cached.main != want {
+			t.Errorf("%s 缓存在 emphasis=false 下，内容却是切换后渲染的:\n%s", key.word, cached.main)
+		}
+	}
+}
+
+func TestRenderDetailChineseEmphasis(t *testing.T) {
+	useFixtureDatabases(t)
+	emphasized, _, err := renderDetail(detailKey{word: "苹果", emphasis: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(emphasized, "苹 果") {
+		t.Errorf("加强显示的中文详情词头应加宽显示:\n%s", emphasized)
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// linkRegion 返回详情中可点击单词的 tview 区域标记，n 从 1 开始
+func linkRegion(n int, word string) string {
+	return fmt.Sprintf(`["link%d"]%s[""]`, n, word)
+}
+
+// linkRegex 从渲染好的详情文本中找出可点击的单词，第一组为序号，第二组为（转义过的）单词
+var linkRegex = regexp.MustCompile(`\["link(\d+)"\](?:\[cyan\])?(.*?)\[""\]`)
+
+// regionTagRegex 匹配 tview 的区域标记，输出纯文本时去掉
+var regionTagRegex = regexp.MustCompile(`\["[a-zA-Z0-9_,;: \-\.]*"\]`)
+
+const maxDetailBack = 50 // 导航栈最多保存的单词数
+
+var (
+	detailLinks   []string // 详情面板中可点击的单词，第 i 个对应区域 link{i+1}
+	detailBack    []string // 导航栈：从详情中的链接跳转前显示的单词，按返回键依次回到这些单词
+	selectingLink bool     // 正在用键盘移动高亮，此时高亮变化不打开单词
+)
+
+// setupDetailLinks 让详情面板支持点击或用 ←/→ 选择其中的单词、Enter 打开
+func setupDetailLinks() {
+	detailView.SetRegions(true)
+	detailView.SetHighlightedFunc(func(added, removed, remaining []string) {
+		if selectingLink || len(added) == 0 {
+			return
+		}
+		// 鼠标点击单词时 tview 会高亮该区域，直接打开它
+		if word := linkWord(added[0]); word != "" {
+			followLink(word)
+		}
+	})
+	detailView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if len(detailLinks) == 0 {
+			return event
+		}
+		switch event.Key() {
+		case tcell.KeyRight:
+			moveLinkHighlight(1)
+			return nil
+		case tcell.KeyLeft:
+			moveLinkHighlight(-1)
+			return nil
+		case tcell.KeyEnter:
+			if highlights := detailView.GetHighlights(); len(highlights) > 0 {
+				if word := linkWord(highlights[0]); word != "" {
+					followLink(word)
+				}
+			}
+			return nil
+		}
+		return event
+	})
+}
+
+// updateDetailLinks 在详情文本更新后重新收集其中可点击的单词（需在主线程中调用）
+func updateDetailLinks(detail string) {
+	detailLinks = nil
+	for _, m := range linkRegex.FindAllStringSubmatch(detail, -1) {
+		detailLinks = append(detailLinks, plainText(m[2]))
+	}
+	updateDetailAnchors(detail)
+	selectingLink = true
+	detailView.Highlight()
+	selectingLink = false
+}
+
+// linkIndex 返回区域 link{n} 的序号 n，不是单词链接时返回 0
+func linkIndex(region string) int {
+	var n int
+	if _, err := fmt.Sscanf(region, "link%d", &n); err != nil || n < 1 || n > len(detailLinks) {
+		return 0
+	}
+	return n
+}
+
+// linkWord 返回区域对应的单词，不是单词链接时返回空字符串
+func linkWord(region string) string {
+	if n := linkIndex(region); n > 0 {
+		return detailLinks[n-1]
+	}
+	return ""
+}
+
+// moveLinkHighlight 把高亮移到下一个（dir 为 1）或上一个（dir 为 -1）单词，并滚动到它所在的位置
+func moveLinkHighlight(dir int) {
+	current := 0
+	if highlights := detailView.GetHighlights(); len(highlights) > 0 {
+		current = linkIndex(highlights[0])
+	}
+	next := current + dir
+	if next < 1 {
+		next = len(detailLinks)
+	} else if next > len(detailLinks) {
+		next = 1
+	}
+
+	selectingLink = true
+	detailView.Highlight(fmt.Sprintf("link%d", next)).ScrollToHighlight()
+	selectingLink = false
+	setStatus(fmt.Sprintf("%s（%d / %d）· Enter 打开", tview.Escape(detailLinks[next-1]), next, len(detailLinks)))
+}
+
+// pushDetailBack 把详情中当前的单词压入导航栈，栈中最多保留 maxDetailBack 个
+func pushDetailBack() {
+	if currentWord == "" {
+		return
+	}
+	detailBack = append(detailBack, currentWord)
+	if len(detailBack) > maxDetailBack {
+		detailBack = detailBack[len(detailBack)-maxDetailBack:]
+	}
+}
+
+// followLink 打开详情中的单词并记入搜索历史，同时把当前单词压入导航栈
+func followLink(word string) {
+	if currentWord != word {
+		pushDetailBack()
+	}
+	addToHistory(word)
+	loadDetail(word)
+	if len(detailBack) > 0 {
+		setStatus("已打开 " + tview.Escape(word) + "，按 " + keyLabel(actionBack) + " 返回 " + tview.Escape(detailBack[len(detailBack)-1]))
+	}
+}
+
+// goBack 回到导航栈中上一个单词
+func goBack() {
+	if len(detailBack) == 0 {
+		setStatus("[yellow]没有可以返回的单词[-]")
+		return
+	}
+	word := detailBack[len(detailBack)-1]
+	detailBack = detailBack[:len(detailBack)-1]
+	loadDetail(word)
+	setStatus("已返回 " + tview.Escape(word))
+}
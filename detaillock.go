@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// lockedWord 锁定在详情面板中的单词，为空表示未锁定；锁定期间选中其他单词只在状态栏预览
+var lockedWord string
+
+// toggleDetailLock 把详情面板锁定在当前单词上，或解除锁定并显示列表中选中的单词
+func toggleDetailLock() {
+	if lockedWord != "" {
+		lockedWord = ""
+		setStatus("详情面板已解锁")
+		updateDetailTitle()
+		if word := wordAt(wordList.GetCurrentItem()); word != "" && word != currentWord {
+			loadDetail(word)
+		}
+		return
+	}
+	if currentWord == "" {
+		setStatus("[yellow]请先选中一个单词[-]")
+		return
+	}
+	lockedWord = currentWord
+	setStatus(fmt.Sprintf("详情已锁定在 %s，在列表中移动时只在状态栏预览（%s 解锁）", tview.Escape(lockedWord), keyLabel(actionLock)))
+	updateDetailTitle()
+}
+
+// showLockedPreview 详情锁定时在状态栏显示 word 的简短释义，代替切换详情面板
+func showLockedPreview(word string) {
+	go func() {
+		preview := previewWord(word)
+		app.QueueUpdateDraw(func() {
+			// 预览返回前已经解锁，或用户又移动到了别的单词时不再显示
+			if lockedWord == "" || wordAt(wordList.GetCurrentItem()) != word {
+				return
+			}
+			text := "[yellow]" + tview.Escape(word) + "[-]"
+			if preview != "" {
+				text += "  " + tview.Escape(preview)
+			}
+			setStatus(text + "  [gray]（详情已锁定，" + keyLabel(actionLock) + " 解锁）[-]")
+		})
+	}()
+}
+
+// previewWord 返回单词的一行简短释义：英文单词取中文释义的第一个词性，中文词取第一个英文单词；查不到时返回空字符串
+func previewWord(word string) string {
+	if err := databaseReady(); err != nil {
+		return ""
+	}
+	if isChinese(word) {
+		ctx, cancel := queryContext()
+		defer cancel()
+		var englishWords string
+		if err := chineseDB.QueryRowContext(ctx, `SELECT english_words FROM chinese_words WHERE chinese = ?`, word).Scan(&englishWords); err != nil {
+			return ""
+		}
+		first, _, _ := strings.Cut(englishWords, "\n")
+		return cleanNewlines(first)
+	}
+
+	w, err := lookupEnglishWord(word)
+	if err != nil {
+		return ""
+	}
+	groups := parseSenses(w.Translation)
+	if len(groups) == 0 {
+		return ""
+	}
+	g := groups[0]
+	text := strings.Join(g.Senses, "；")
+	if g.POS != "" {
+		text = g.POS + " " + text
+	}
+	if runes := []rune(text); len(runes) > 60 {
+		text = string(runes[:60]) + "…"
+	}
+	return text
+}
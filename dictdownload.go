@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// 下载词典数据时的重试次数和每次重试前等待的时间（第 n 次重试等待 n 倍）
+const (
+	downloadRetries    = 5
+	downloadRetryDelay = 2 * time.Second
+)
+
+// downloadPartSuffix 下载中的词典数据文件的后缀，下载中断后保留，下次从已下载的位置继续
+const downloadPartSuffix = ".part"
+
+// downloadConnectTimeout 下载词典数据时建立连接、完成 TLS 握手和等待响应头的超时时间
+const downloadConnectTimeout = 30 * time.Second
+
+// downloadIdleTimeout 下载过程中连续这么久没有收到数据时断开连接，交给重试从断点继续；
+// 网络静默中断（没有 RST）时连接不会报错，没有这个超时会一直等下去
+var downloadIdleTimeout = 60 * time.Second
+
+// downloadClient 下载词典数据使用的客户端。不设置整体超时，几十 MB 的文件在慢速网络上需要很久，
+// 停滞的连接由 idleTimeoutReader 中止
+var downloadClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           (&net.Dialer{Timeout: downloadConnectTimeout, KeepAlive: 30 * time.Second}).DialContext,
+		TLSHandshakeTimeout:   downloadConnectTimeout,
+		ResponseHeaderTimeout: downloadConnectTimeout,
+	},
+}
+
+// validateDictURL 检查 dictURL 是 http 或 https 地址，dictSHA256 为空或是 64 位十六进制
+func validateDictURL(rawURL, checksum string) error {
+	if rawURL != "" {
+		u, err := url.Parse(rawURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("dictURL %q 不是有效的 http 或 https 地址", rawURL)
+		}
+	}
+	if checksum != "" {
+		if b, err := hex.DecodeString(checksum); err != nil || len(b) != sha256.Size {
+			return fmt.Errorf("dictSHA256 必须是 64 位十六进制的 SHA-256 校验和")
+		}
+	}
+	return nil
+}
+
+// downloadDict 把 config.DictURL 下载为 dest，先写入 dest.part：连接中断时自动重试，
+// 用 HTTP Range 请求从已下载的位置继续；程序退出后再次下载也会继续上次的进度。
+// 下载完成后核对文件大小和（配置了 dictSHA256 时的）校验和，通过后才重命名为 dest
+func downloadDict(dest string) error {
+	part := dest + downloadPartSuffix
+	consolePrintf("📥 正在下载词典数据 %s\n", config.DictURL)
+
+	var total int64
+	var err error
+	for attempt := 0; attempt <= downloadRetries; attempt++ {
+		if attempt > 0 {
+			wait := downloadRetryDelay * time.Duration(attempt)
+			consolePrintf("   ⚠️  下载中断: %v，%v 后从断点重试（%d/%d）\n", err, wait, attempt, downloadRetries)
+			time.Sleep(wait)
+		}
+		if total, err = downloadPart(config.DictURL, part); err == nil {
+			break
+		}
+		logWarnf("下载 %s 失败: %v", config.DictURL, err)
+	}
+	if err != nil {
+		return fmt.Errorf("下载词典数据失败: %v（已下载的部分保留在 %s，再次运行时继续）", err, part)
+	}
+
+	if err := verifyDownload(part, total, config.DictSHA256); err != nil {
+		os.Remove(part)
+		return fmt.Errorf("下载的词典数据校验失败，已删除，请重新运行: %v", err)
+	}
+	if err := os.Rename(part, dest); err != nil {
+		return fmt.Errorf("无法保存 %s: %v", dest, err)
+	}
+	consolePrintf("   ✅ 已下载 %s（%s）\n", dest, formatFileSize(total))
+	consolePrintln()
+	return nil
+}
+
+// downloadPart 从 part 已有的长度继续下载 rawURL，返回文件的总大小（服务器没有提供时为已下载的长度）
+//
+// 服务器不支持 Range 请求（返回 200）时从头重新下载；返回 416 说明文件已经下载完整
+func downloadPart(rawURL, part string) (int64, error) {
+	var offset int64
+	if info, err := os.Stat(part); err == nil {
+		offset = info.Size()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := downloadClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	body := newIdleTimeoutReader(resp.Body, downloadIdleTimeout, cancel)
+	defer body.stop()
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	var total int64
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		total = contentRangeTotal(resp.Header.Get("Content-Range"))
+		if total < 0 && resp.ContentLength >= 0 {
+			total = offset + resp.ContentLength
+		}
+	case http.StatusOK:
+		if offset > 0 {
+			consolePrintln("   服务器不支持断点续传，从头重新下载")
+		}
+		offset = 0
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		total = resp.ContentLength
+	case http.StatusRequestedRangeNotSatisfiable:
+		if total = contentRangeTotal(resp.Header.Get("Content-Range")); total == offset {
+			return total, nil
+		}
+		// 已下载的部分比服务器上的文件还长，只能重新下载
+		os.Remove(part)
+		return 0, fmt.Errorf("服务器上的文件已变化（%s）", resp.Status)
+	default:
+		return 0, fmt.Errorf("服务器返回 %s", resp.Status)
+	}
+
+	file, err := os.OpenFile(part, flags, 0644)
+	if err != nil {
+		return 0, fmt.Errorf("无法写入 %s: %v", part, err)
+	}
+	defer file.Close()
+
+	progress := &downloadProgress{done: offset, total: total}
+	if total > 0 {
+		progress.bar = newProgressBar("   📊 下载进度")
+		progress.bar.Update(int(offset), int(total))
+	}
+	n, err := io.Copy(file, io.TeeReader(body, progress))
+	if progress.bar != nil {
+		if err == nil && offset+n == total {
+			progress.bar.Finish()
+		} else {
+			progress.bar.Break()
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	if total < 0 {
+		total = offset + n
+	} else if offset+n < total {
+		return 0, fmt.Errorf("连接提前关闭（已下载 %s / %s）", formatFileSize(offset+n), formatFileSize(total))
+	}
+	return total, nil
+}
+
+// idleTimeoutReader 每次读到数据时重新计时，超过 timeout 没有数据时调用 cancel 中止请求
+type idleTimeoutReader struct {
+	r        io.Reader
+	timeout  time.Duration
+	timer    *time.Timer
+	timedOut atomic.Bool
+}
+
+// newIdleTimeoutReader 包装 r 并开始计时
+func newIdleTimeoutReader(r io.Reader, timeout time.Duration, cancel context.CancelFunc) *idleTimeoutReader {
+	t := &idleTimeoutReader{r: r, timeout: timeout}
+	t.timer = time.AfterFunc(timeout, func() {
+		t.timedOut.Store(true)
+		cancel()
+	})
+	return t
+}
+
+// Read 读取数据并重新计时；因为超时而中止时返回说明超时的错误
+func (t *idleTimeoutReader) Read(p []byte) (int, error) {
+	n, err := t.r.Read(p)
+	if n > 0 {
+		t.timer.Reset(t.timeout)
+	}
+	if err != nil && err != io.EOF && t.timedOut.Load() {
+		err = fmt.Errorf("%v 内没有收到数据", t.timeout)
+	}
+	return n, err
+}
+
+// stop 停止计时
+func (t *idleTimeoutReader) stop() {
+	t.timer.Stop()
+}
+
+// downloadProgress 统计已下载的字节数并更新进度条
+type downloadProgress struct {
+	done, total int64
+	bar         *progressBar
+}
+
+// Write 记下经过的字节数，不改变数据
+func (p *downloadProgress) Write(b []byte) (int, error) {
+	p.done += int64(len(b))
+	if p.bar != nil {
+		p.bar.Update(int(p.done), int(p.total))
+	}
+	return len(b), nil
+}
+
+// contentRangeTotal 从 Content-Range（如 bytes 100-199/1000 或 bytes */1000）中取出文件总大小，没有时返回 -1
+func contentRangeTotal(header string) int64 {
+	i := strings.LastIndex(header, "/")
+	if i < 0 {
+		return -1
+	}
+	total, err := strconv.ParseInt(header[i+1:], 10, 64)
+	if err != nil {
+		return -1
+	}
+	return total
+}
+
+// verifyDownload 检查下载的文件大小为 size，checksum 不为空时再核对 SHA-256
+func verifyDownload(path string, size int64, checksum string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() != size {
+		return fmt.Errorf("文件大小为 %d 字节，应为 %d 字节", info.Size(), size)
+	}
+	if checksum == "" {
+		return nil
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return err
+	}
+	if sum := hex.EncodeToString(hash.Sum(nil)); !strings.EqualFold(sum, checksum) {
+		return fmt.Errorf("SHA-256 为 %s，应为 %s", sum, strings.ToLower(checksum))
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// downloadContent 测试服务器提供的文件内容
+var downloadContent = bytes.Repeat([]byte("apple,n. 苹果\n"), 1000)
+
+// serveContent 支持 Range 请求地提供 downloadContent
+func serveContent(w http.ResponseWriter, r *http.Request) {
+	http.ServeContent(w, r, "ecdict.csv.gz", time.Time{}, bytes.NewReader(downloadContent))
+}
+
+// partFile 在临时目录中准备下载中的文件，content 为 nil 时不创建
+func partFile(t *testing.T, content []byte) string {
+	t.Helper()
+	part := filepath.Join(t.TempDir(), "ecdict.csv.gz"+downloadPartSuffix)
+	if content != nil {
+		if err := os.WriteFile(part, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return part
+}
+
+// checkPart 检查下载到的文件内容与 downloadContent 相同
+func checkPart(t *testing.T, part string) {
+	t.Helper()
+	got, err := os.ReadFile(part)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, downloadContent) {
+		t.Errorf("下载的文件有 %d 字节，与服务器上的 %d 字节不同", len(got), len(downloadContent))
+	}
+}
+
+func TestDownloadPart(t *testing.T) {
+	half := downloadContent[:len(downloadContent)/2]
+	tests := []struct {
+		name     string
+		handler  http.HandlerFunc
+		existing []byte // 已下载的部分
+		ranges   string // 请求中应有的 Range
+	}{
+		{"从头下载", serveContent, nil, ""},
+		{"206 从已下载的位置继续", serveContent, half, "bytes=" + strconv.Itoa(len(half)) + "-"},
+		// 服务器不支持 Range 时返回 200，已下载的部分作废
+		{"200 从头重新下载", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Length", strconv.Itoa(len(downloadContent)))
+			w.Write(downloadContent)
+		}, []byte("部分内容"), "bytes=12-"},
+		// 文件已经下载完整
+		{"416 已下载完整", serveContent, downloadContent, "bytes=" + strconv.Itoa(len(downloadContent)) + "-"},
+	}
+	for _, tt := range tests {
+		var ranges atomic.Value
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ranges.Store(r.Header.Get("Range"))
+			tt.handler(w, r)
+		}))
+		part := partFile(t, tt.existing)
+		var total int64
+		var err error
+		captureStdout(t, func() { total, err = downloadPart(server.URL, part) })
+		server.Close()
+		if err != nil {
+			t.Errorf("%s: downloadPart 出错: %v", tt.name, err)
+			continue
+		}
+		if total != int64(len(downloadContent)) {
+			t.Errorf("%s: 文件总大小为 %d，应为 %d", tt.name, total, len(downloadContent))
+		}
+		if got := ranges.Load(); got != tt.ranges {
+			t.Errorf("%s: 请求的 Range 为 %q，应为 %q", tt.name, got, tt.ranges)
+		}
+		checkPart(t, part)
+	}
+}
+
+func TestDownloadPartDroppedConnection(t *testing.T) {
+	// 第一次请求只发送一半内容就断开连接，第二次正常提供剩下的部分
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Content-Length", strconv.Itoa(len(downloadContent)))
+			w.Write(downloadContent[:len(downloadContent)/2])
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+		serveContent(w, r)
+	}))
+	defer server.Close()
+
+	part := partFile(t, nil)
+	var err error
+	captureStdout(t, func() { _, err = downloadPart(server.URL, part) })
+	if err == nil {
+		t.Fatal("连接中断时 downloadPart 应返回错误")
+	}
+	if info, statErr := os.Stat(part); statErr != nil || info.Size() != int64(len(downloadContent)/2) {
+		t.Fatalf("连接中断后应保留已下载的一半内容: %v, %v", info, statErr)
+	}
+	captureStdout(t, func() { _, err = downloadPart(server.URL, part) })
+	if err != nil {
+		t.Fatalf("重试时 downloadPart 出错: %v", err)
+	}
+	checkPart(t, part)
+}
+
+func TestDownloadDictStalledConnection(t *testing.T) {
+	saved := downloadIdleTimeout
+	downloadIdleTimeout = 200 * time.Millisecond
+	defer func() { downloadIdleTimeout = saved }()
+
+	// 第一次请求发送一半内容后不再发送数据，也不关闭连接
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Content-Length", strconv.Itoa(len(downloadContent)))
+			w.Write(downloadContent[:len(downloadContent)/2])
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+			return
+		}
+		serveContent(w, r)
+	}))
+	defer server.Close()
+
+	config = defaultConfig()
+	config.DictURL = server.URL
+	dest := filepath.Join(t.TempDir(), "ecdict.csv.gz")
+	var err error
+	output := captureStdout(t, func() { err = downloadDict(dest) })
+	if err != nil {
+		t.Fatalf("downloadDict 出错: %v\n%s", err, output)
+	}
+	if !strings.Contains(output, "没有收到数据") {
+		t.Errorf("没有报告连接停滞，输出为:\n%s", output)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("服务器收到 %d 次请求，应为 2 次", n)
+	}
+	checkPart(t, dest)
+	if _, err := os.Stat(dest + downloadPartSuffix); !os.IsNotExist(err) {
+		t.Errorf("下载完成后 %s 应已重命名", dest+downloadPartSuffix)
+	}
+}
+
+func TestDownloadDictChecksum(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(serveContent))
+	defer server.Close()
+	sum := sha256.Sum256(downloadContent)
+
+	tests := []struct {
+		name     string
+		checksum string
+		ok       bool
+	}{
+		{"校验和一致", hex.EncodeToString(sum[:]), true},
+		{"校验和大小写不同", strings.ToUpper(hex.EncodeToString(sum[:])), true},
+		{"校验和不一致", strings.Repeat("0", 64), false},
+	}
+	for _, tt := range tests {
+		config = defaultConfig()
+		config.DictURL = server.URL
+		config.DictSHA256 = tt.checksum
+		dest := filepath.Join(t.TempDir(), "ecdict.csv.gz")
+		var err error
+		captureStdout(t, func() { err = downloadDict(dest) })
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: downloadDict 的错误为 %v", tt.name, err)
+		}
+		_, destErr := os.Stat(dest)
+		if tt.ok != (destErr == nil) {
+			t.Errorf("%s: %s 是否存在应为 %v", tt.name, dest, tt.ok)
+		}
+		// 校验失败时删除下载的文件，下次从头下载
+		if _, err := os.Stat(dest + downloadPartSuffix); !os.IsNotExist(err) {
+			t.Errorf("%s: %s 应已删除或重命名", tt.name, dest+downloadPartSuffix)
+		}
+	}
+}
+
+func TestContentRangeTotal(t *testing.T) {
+	tests := []struct {
+		header string
+		want   int64
+	}{
+		{"bytes 100-199/1000", 1000},
+		{"bytes */1000", 1000},
+		{"bytes 0-99/*", -1},
+		{"", -1},
+		{"bytes 0-99", -1},
+	}
+	for _, tt := range tests {
+		if got := contentRangeTotal(tt.header); got != tt.want {
+			t.Errorf("contentRangeTotal(%q) = %d，应为 %d", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestVerifyDownload(t *testing.T) {
+	part := partFile(t, downloadContent)
+	sum := sha256.Sum256(downloadContent)
+	tests := []struct {
+		size     int64
+		checksum string
+		ok       bool
+	}{
+		{int64(len(downloadContent)), "", true},
+		{int64(len(downloadContent)), hex.EncodeToString(sum[:]), true},
+		{int64(len(downloadContent)) + 1, "", false},
+		{int64(len(downloadContent)), strings.Repeat("0", 64), false},
+	}
+	for _, tt := range tests {
+		if err := verifyDownload(part, tt.size, tt.checksum); (err == nil) != tt.ok {
+			t.Errorf("verifyDownload(%d, %q) 的错误为 %v", tt.size, tt.checksum, err)
+		}
+	}
+}
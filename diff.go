@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// diffHighlights 摘要中列出的最常用的变化单词数
+const diffHighlights = 10
+
+// diffEntry 比较两个词典版本时每个单词保留的内容
+type diffEntry struct {
+	definition  string
+	translation string
+	bnc         string
+}
+
+// diffChange 一个新增、删除或释义有变化的单词
+type diffChange struct {
+	word              string
+	old, new          diffEntry
+	definitionChange  bool
+	translationChange bool
+}
+
+// readDiffEntries 用生成数据库时相同的规则读取词典 CSV，返回 单词 → 内容；同一个单词出现多次时以第一次为准
+func readDiffEntries(path string) (map[string]diffEntry, error) {
+	file, err := openCSV(path)
+	if err != nil {
+		return nil, fmt.Errorf("无法打开CSV文件 %s: %v", path, err)
+	}
+	defer file.Close()
+
+	records, stats, err := readDictRecords(file, nil)
+	if err != nil {
+		return nil, fmt.Errorf("无法读取 %s: %v", path, err)
+	}
+	consolePrintf("📖 %s: %d 条记录\n", path, len(records))
+	stats.report()
+
+	entries := make(map[string]diffEntry, len(records))
+	for _, r := range records {
+		word := strings.TrimSpace(r[0])
+		if _, exists := entries[word]; exists {
+			continue
+		}
+		entries[word] = diffEntry{
+			definition:  strings.TrimSpace(r[2]),
+			translation: strings.TrimSpace(r[3]),
+			bnc:         strings.TrimSpace(r[8]),
+		}
+	}
+	return entries, nil
+}
+
+// runDiff 比较两个版本的词典 CSV，输出新增、删除和释义有变化的单词数，以及其中最常用的几个词；
+// detail 为 true 时再按 BNC 词频列出全部变化
+func runDiff(oldPath, newPath string, detail bool) error {
+	oldEntries, err := readDiffEntries(oldPath)
+	if err != nil {
+		return err
+	}
+	newEntries, err := readDiffEntries(newPath)
+	if err != nil {
+		return err
+	}
+
+	var added, removed, changed []diffChange
+	for word, n := range newEntries {
+		o, ok := oldEntries[word]
+		if !ok {
+			added = append(added, diffChange{word: word, new: n})
+			continue
+		}
+		c := diffChange{word: word, old: o, new: n,
+			definitionChange:  o.definition != n.definition,
+			translationChange: o.translation != n.translation,
+		}
+		if c.definitionChange || c.translationChange {
+			changed = append(changed, c)
+		}
+	}
+	for word, o := range oldEntries {
+		if _, ok := newEntries[word]; !ok {
+			removed = append(removed, diffChange{word: word, old: o})
+		}
+	}
+	for _, list := range [][]diffChange{added, removed, changed} {
+		sortDiffChanges(list)
+	}
+
+	translations, definitions := 0, 0
+	for _, c := range changed {
+		if c.translationChange {
+			translations++
+		}
+		if c.definitionChange {
+			definitions++
+		}
+	}
+	fmt.Println()
+	fmt.Printf("新增 %d 个单词，删除 %d 个，释义有变化 %d 个（中文释义 %d，英文释义 %d）\n",
+		len(added), len(removed), len(changed), translations, definitions)
+	if len(added)+len(removed)+len(changed) == 0 {
+		return nil
+	}
+
+	if !detail {
+		printDiffHighlights("新增", added)
+		printDiffHighlights("删除", removed)
+		printDiffHighlights("释义有变化", changed)
+		fmt.Println()
+		fmt.Println("加上 --diff-list 列出全部变化")
+		return nil
+	}
+
+	printDiffSection("新增", added, func(c diffChange) []string {
+		return []string{"+ " + c.word + diffSummary(c.new.translation)}
+	})
+	printDiffSection("删除", removed, func(c diffChange) []string {
+		return []string{"- " + c.word + diffSummary(c.old.translation)}
+	})
+	printDiffSection("释义有变化", changed, func(c diffChange) []string {
+		lines := []string{"~ " + c.word}
+		if c.translationChange {
+			lines = append(lines, "    中文释义: "+diffText(c.old.translation)+" → "+diffText(c.new.translation))
+		}
+		if c.definitionChange {
+			lines = append(lines, "    英文释义: "+diffText(c.old.definition)+" → "+diffText(c.new.definition))
+		}
+		return lines
+	})
+	return nil
+}
+
+// sortDiffChanges 按 BNC 词频排序（新版本中的词频优先，没有词频的排在最后），同词频按字母顺序
+func sortDiffChanges(list []diffChange) {
+	rank := func(c diffChange) int {
+		if c.new.bnc != "" {
+			return parseBNC(c.new.bnc)
+		}
+		return parseBNC(c.old.bnc)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if ri, rj := rank(list[i]), rank(list[j]); ri != rj {
+			return ri < rj
+		}
+		return list[i].word < list[j].word
+	})
+}
+
+// printDiffHighlights 在一行中列出某类变化中最常用的几个单词
+func printDiffHighlights(title string, list []diffChange) {
+	if len(list) == 0 {
+		return
+	}
+	var words []string
+	for i := 0; i < len(list) && i < diffHighlights; i++ {
+		words = append(words, list[i].word)
+	}
+	more := ""
+	if len(list) > diffHighlights {
+		more = fmt.Sprintf(" 等 %d 个", len(list))
+	}
+	fmt.Printf("  %s: %s%s\n", title, strings.Join(words, ", "), more)
+}
+
+// printDiffSection 输出某类变化的全部单词，每个单词由 lines 生成一行或多行
+func printDiffSection(title string, list []diffChange, lines func(diffChange) []string) {
+	if len(list) == 0 {
+		return
+	}
+	fmt.Printf("\n%s（%d）:\n", title, len(list))
+	for _, c := range list {
+		for _, line := range lines(c) {
+			fmt.Println(line)
+		}
+	}
+}
+
+// diffSummary 返回附在单词后面的简短中文释义，没有释义时返回空字符串
+func diffSummary(translation string) string {
+	if translation == "" {
+		return ""
+	}
+	return "  " + diffText(translation)
+}
+
+// diffText 把释义压缩为一行并截断过长的部分，空释义显示为（空）
+func diffText(text string) string {
+	text = strings.Join(strings.Fields(strings.ReplaceAll(text, "\\n", " ")), " ")
+	if text == "" {
+		return "（空）"
+	}
+	if runes := []rune(text); len(runes) > 60 {
+		return string(runes[:60]) + "…"
+	}
+	return text
+}
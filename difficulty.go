@@ -0,0 +1,45 @@
+package main
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// difficultyLevels 难度标签，从易到难
+var difficultyLevels = []string{"基础", "初级", "中级", "高级", "专业"}
+
+// difficultyBands 各难度对应的 BNC 词频排名上限：排名在前 2000 的是基础词，依此类推，
+// 超出最后一档或没有词频的单词算作专业词汇
+var difficultyBands = []int{2000, 5000, 10000, 20000}
+
+// longWordLength 字母数达到这个长度的单词拼写和记忆都更难，难度提高一档
+const longWordLength = 11
+
+// wordDifficulty 根据 BNC 词频和长度粗略估计单词的难度，返回难度标签
+//
+// 词频决定基本难度，很长的单词再提高一档；这只是帮助判断是否值得记忆的参考，并非严格的分级。
+// 词组大多没有词频，无法估计，返回空字符串
+func wordDifficulty(word, bnc string) string {
+	// parseBNC 对没有词频的单词返回一个很大的数，落在最后一档
+	rank := parseBNC(bnc)
+	if rank == parseBNC("") && strings.Contains(word, " ") {
+		return ""
+	}
+
+	level := len(difficultyBands)
+	for i, max := range difficultyBands {
+		if rank <= max {
+			level = i
+			break
+		}
+	}
+	if letterCount(word) >= longWordLength && level < len(difficultyLevels)-1 {
+		level++
+	}
+	return difficultyLevels[level]
+}
+
+// letterCount 返回单词的字母数，词组中的空格不计
+func letterCount(word string) int {
+	return utf8.RuneCountInString(strings.ReplaceAll(word, " ", ""))
+}
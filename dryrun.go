@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DryRunConverter 只读取和解析词典 CSV，报告识别到的格式、记录数、跳过的行和能提取的中文词数，不写入任何数据库
+//
+// 用于在正式生成（需要一两分钟）之前发现分隔符不对、缺少列之类的问题
+func DryRunConverter(csvFile string) error {
+	start := time.Now()
+	consolePrintf("📖 正在检查 %s（不生成数据库）\n", csvFile)
+
+	file, err := openCSV(csvFile)
+	if err != nil {
+		return fmt.Errorf("无法打开CSV文件: %v", err)
+	}
+	defer file.Close()
+
+	reading := newSpinner("   ⏳ 正在读取词典数据...")
+	records, stats, err := readDictRecords(file, reading)
+	reading.Stop()
+	if err != nil {
+		consolePrintln()
+		return err
+	}
+	consolePrintf(" 完成 (%d 条)\n", len(records))
+	stats.report()
+	if len(records) == 0 {
+		return fmt.Errorf("%s 中没有可以导入的记录", csvFile)
+	}
+
+	words := make(map[string]bool, len(records))
+	chineseWords := make(map[string]bool)
+	var translated, phonetic, definition, bnc int
+	for _, r := range records {
+		words[strings.TrimSpace(r[0])] = true
+		if r[1] != "" {
+			phonetic++
+		}
+		if r[2] != "" {
+			definition++
+		}
+		if r[3] != "" {
+			translated++
+			for _, w := range extractChineseWords(r[3]) {
+				chineseWords[w] = true
+			}
+		}
+		if parseBNC(r[8]) != parseBNC("") {
+			bnc++
+		}
+	}
+
+	consolePrintf("   英文数据库: %d 个词条（%d 个不同的单词），其中 %d 条有中文释义、%d 条有英文释义、%d 条有音标、%d 条有 BNC 词频\n",
+		len(records), len(words), translated, definition, phonetic, bnc)
+	consolePrintf("   中文数据库: %d 个中文词\n", len(chineseWords))
+	consolePrintf("✅ 检查完成，用时 %s；去掉 -dry-run 即可生成数据库\n", formatDuration(time.Since(start)))
+	return nil
+}
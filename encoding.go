@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/transform"
+)
+
+// csvEncoding 词典 CSV 文件的字符编码，auto 表示根据文件内容在 UTF-8 和 GB18030 之间自动判断
+var csvEncoding = "auto"
+
+// utf8BOM UTF-8 字节顺序标记，Excel 等工具导出的 CSV 常在文件开头带有它
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// encodingSampleSize 自动判断编码时读取的文件开头字节数
+const encodingSampleSize = 64 * 1024
+
+// csvEncodings 支持的编码名称，值为 nil 表示 UTF-8（不需要转换）
+var csvEncodings = map[string]encoding.Encoding{
+	"utf-8":   nil,
+	"utf8":    nil,
+	"gbk":     simplifiedchinese.GBK,
+	"gb18030": simplifiedchinese.GB18030,
+	"big5":    traditionalchinese.Big5,
+}
+
+// validateEncoding 检查编码名称是否受支持
+func validateEncoding(name string) error {
+	name = strings.ToLower(name)
+	if name == "auto" {
+		return nil
+	}
+	if _, ok := csvEncodings[name]; !ok {
+		return fmt.Errorf("不支持的编码 %q（可选 auto、utf-8、gbk、gb18030、big5）", name)
+	}
+	return nil
+}
+
+// csvReader 把转换编码后的读取器和底层文件组合在一起，便于统一关闭
+type csvReader struct {
+	io.Reader
+	file *os.File
+	gz   *gzip.Reader // 读取 .gz 文件时的解压器，普通 CSV 为 nil
+}
+
+// Close 关闭解压器和底层文件
+func (r *csvReader) Close() error {
+	if r.gz != nil {
+		r.gz.Close()
+	}
+	return r.file.Close()
+}
+
+// openCSV 打开 CSV 文件，按 csvEncoding 把内容转换为 UTF-8；文件名以 .gz 结尾时边读边解压，不需要先解压到磁盘
+//
+// 文件开头的 UTF-8 BOM 会被去掉，否则它会粘在表头的第一个字段（或没有表头时第一个单词）前面；
+// 自动判断编码时带 BOM 的文件直接按 UTF-8 读取
+func openCSV(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	var src io.Reader = file
+	var gz *gzip.Reader
+	if strings.HasSuffix(strings.ToLower(path), ".gz") {
+		if gz, err = gzip.NewReader(file); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("无法解压 %s: %v", path, err)
+		}
+		src = gz
+	}
+
+	br := bufio.NewReaderSize(src, encodingSampleSize)
+	name := strings.ToLower(csvEncoding)
+	if head, _ := br.Peek(len(utf8BOM)); bytes.Equal(head, utf8BOM) {
+		br.Discard(len(utf8BOM))
+		if name == "auto" {
+			name = "utf-8"
+		}
+	}
+	if name == "auto" {
+		// Peek 在文件小于采样大小时返回 EOF，这里只需要已读到的内容
+		sample, _ := br.Peek(encodingSampleSize)
+		name = detectEncoding(sample)
+	}
+
+	enc := csvEncodings[name]
+	if enc == nil {
+		return &csvReader{Reader: br, file: file, gz: gz}, nil
+	}
+	return &csvReader{Reader: transform.NewReader(br, enc.NewDecoder()), file: file, gz: gz}, nil
+}
+
+// detectEncoding 根据文件开头的内容判断编码：合法的 UTF-8 视为 UTF-8，否则按 GB18030（兼容 GBK）处理
+//
+// Big5 与 GBK 的字节范围大量重叠，无法可靠区分，需要通过配置显式指定
+func detectEncoding(sample []byte) string {
+	// 采样可能在多字节字符中间截断，去掉最后一个换行符之后的部分，检查之前的全部内容
+	if i := bytes.LastIndexByte(sample, '\n'); i >= 0 && len(sample) == encodingSampleSize {
+		sample = sample[:i]
+	}
+	if utf8.Valid(sample) {
+		return "utf-8"
+	}
+	return "gb18030"
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"io"
+	"os"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestOpenCSVStripsBOM(t *testing.T) {
+	tests := []struct {
+		file  string
+		first string // 去掉 BOM 后文件的开头
+	}{
+		{"testdata/bom_header.csv", "word,"},
+		{"testdata/bom_noheader.csv", "apple,"},
+		{fixtureCSV, "word,"},
+	}
+	for _, tt := range tests {
+		r, err := openCSV(tt.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		head := make([]byte, len(tt.first))
+		_, err = io.ReadFull(r, head)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(head) != tt.first {
+			t.Errorf("openCSV(%s) 的开头为 %q，应为 %q", tt.file, head, tt.first)
+		}
+	}
+}
+
+// TestBOMPrefixedDictionary Excel 导出的 CSV 开头带 BOM，第一个单词（或表头）前面不能粘上它
+func TestBOMPrefixedDictionary(t *testing.T) {
+	for _, file := range []string{"testdata/bom_header.csv", "testdata/bom_noheader.csv"} {
+		config = defaultConfig()
+		english, chinese, err := openMemoryDatabases(file)
+		if err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+		useDatabases(english, chinese)
+
+		// apple 是第一条记录
+		results, err := searchEnglish("apple")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) == 0 || results[0].Word != "apple" || results[0].Match != MatchExact {
+			t.Errorf("%s: searchEnglish(apple) = %q，第一个结果应为精确匹配的 apple", file, wordsOf(results))
+		}
+		if w, err := lookupEnglishWord("apple"); err != nil || w.Translation == "" {
+			t.Errorf("%s: lookupEnglishWord(apple) = %+v, %v", file, w, err)
+		}
+
+		var n int
+		if err := english.QueryRow(`SELECT COUNT(*) FROM words WHERE word LIKE char(65279) || '%' OR word = 'word'`).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		if n != 0 {
+			t.Errorf("%s: 有 %d 个单词带着 BOM 或是表头", file, n)
+		}
+		if err := english.QueryRow(`SELECT COUNT(*) FROM words`).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		if n != 3 {
+			t.Errorf("%s: 读出 %d 个单词，应为 3 个", file, n)
+		}
+		english.Close()
+		chinese.Close()
+	}
+}
+
+// gbkCSV 从 ECDICT 摘取的几个词条，以 GBK 编码保存（GBK 无法表示国际音标，音标列留空）
+const gbkCSV = "testdata/gbk.csv"
+
+// truncatedSample 用 line 重复填满 encodingSampleSize 字节，使采样在最后一个字符的中间截断
+func truncatedSample(t *testing.T, line string) []byte {
+	t.Helper()
+	var b []byte
+	for len(b) <= encodingSampleSize {
+		b = append(b, line...)
+	}
+	sample := b[:encodingSampleSize]
+	for utf8.Valid(sample) {
+		// 截断处恰好是字符边界时，在开头补一个字母让边界移动
+		b = append([]byte("x"), b...)
+		sample = b[:encodingSampleSize]
+	}
+	return sample
+}
+
+func TestDetectEncoding(t *testing.T) {
+	gbk, err := os.ReadFile(gbkCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	utf8Full := truncatedSample(t, "apple,n. 苹果\n")
+	// 前面的完整行中有 GBK 编码的内容，截断在最后一行
+	mixedFull := append(append([]byte{}, gbk...), utf8Full[len(gbk):]...)
+
+	tests := []struct {
+		name   string
+		sample []byte
+		want   string
+	}{
+		{"ASCII", []byte("word,phonetic\napple,\n"), "utf-8"},
+		{"UTF-8", []byte("apple,n. 苹果\ndog,n. 狗\n"), "utf-8"},
+		{"GBK", gbk, "gb18030"},
+		{"UTF-8 采样在字符中间截断", utf8Full, "utf-8"},
+		{"截断之前有 GBK 内容", mixedFull, "gb18030"},
+		// 文件比采样小时读到的就是全部内容，结尾不完整说明不是 UTF-8
+		{"不足采样大小的文件在字符中间结束", []byte("apple,n. 苹果")[:len("apple,n. 苹果")-1], "gb18030"},
+		{"空文件", nil, "utf-8"},
+	}
+	for _, tt := range tests {
+		if got := detectEncoding(tt.sample); got != tt.want {
+			t.Errorf("%s: detectEncoding = %q，应为 %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGBKDictionary(t *testing.T) {
+	saved := csvEncoding
+	defer func() { csvEncoding = saved }()
+
+	for _, encoding := range []string{"gbk", "auto"} {
+		csvEncoding = encoding
+		config = defaultConfig()
+		english, chinese, err := openMemoryDatabases(gbkCSV)
+		if err != nil {
+			t.Fatalf("csvEncoding=%s: %v", encoding, err)
+		}
+		useDatabases(english, chinese)
+
+		// 解码后的释义是正确的中文，从中提取的中文词可以反查
+		for word, translation := range map[string]string{"apple": `n. 苹果, 家伙\n[医] 苹果`, "dog": `n. 狗, 坏蛋\nvt. 跟踪, 尾随`} {
+			w, err := lookupEnglishWord(word)
+			if err != nil {
+				t.Errorf("csvEncoding=%s: lookupEnglishWord(%s) 出错: %v", encoding, word, err)
+			} else if w.Translation != translation {
+				t.Errorf("csvEncoding=%s: %s 的释义为 %q，应为 %q", encoding, word, w.Translation, translation)
+			}
+		}
+		for _, term := range []string{"苹果", "字典", "词典", "跟踪"} {
+			results, err := searchChinese(term)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, ok := matchOf(results, term); !ok || got != MatchExact {
+				t.Errorf("csvEncoding=%s: searchChinese(%s) = %v，应精确匹配到它", encoding, term, wordsOf(results))
+			}
+		}
+		english.Close()
+		chinese.Close()
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"math/rand"
+	"sync"
+)
+
+var (
+	favorites          []string   // 收藏的单词，按收藏时间先后排列
+	favoritesMutex     sync.Mutex // 保护 favorites 的并发访问
+	lastRandomFavorite string     // 上一次随机抽到的收藏，避免连续抽到同一个
+)
+
+// isFavorite 判断单词是否已收藏
+func isFavorite(word string) bool {
+	favoritesMutex.Lock()
+	defer favoritesMutex.Unlock()
+
+	for _, w := range favorites {
+		if w == word {
+			return true
+		}
+	}
+	return false
+}
+
+// toggleFavorite 收藏或取消收藏单词，返回操作后是否处于收藏状态
+func toggleFavorite(word string) bool {
+	favoritesMutex.Lock()
+	defer favoritesMutex.Unlock()
+	defer markStateDirty()
+
+	for i, w := range favorites {
+		if w == word {
+			favorites = append(favorites[:i], favorites[i+1:]...)
+			stats.recordFavorite(word, false)
+			return false
+		}
+	}
+	favorites = append(favorites, word)
+	stats.recordFavorite(word, true)
+	return true
+}
+
+// getFavorites 返回收藏列表的副本
+func getFavorites() []string {
+	favoritesMutex.Lock()
+	defer favoritesMutex.Unlock()
+
+	result := make([]string, len(favorites))
+	copy(result, favorites)
+	return result
+}
+
+// randomFavorite 随机返回一个收藏的单词，收藏多于一个时不会与上一次相同；没有收藏时返回空字符串
+func randomFavorite() string {
+	favoritesMutex.Lock()
+	defer favoritesMutex.Unlock()
+
+	var candidates []string
+	for _, w := range favorites {
+		if w != lastRandomFavorite || len(favorites) == 1 {
+			candidates = append(candidates, w)
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+
+	lastRandomFavorite = candidates[rand.Intn(len(candidates))]
+	return lastRandomFavorite
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+var (
+	resultFilter *tview.InputField // 结果筛选框，打开后显示在搜索框下方
+	filterOpen   bool              // 筛选框是否打开
+
+	// 最近一次搜索的完整结果行（与 buildResultRows 的返回值相同），筛选只在这些行中进行，不重新查询数据库
+	resultTexts []string
+	resultWords []string
+	resultExact map[string]bool // 其中的精确匹配，开启 keepExactMatch 时总是保留在筛选结果中
+)
+
+// newResultFilter 创建结果筛选框，初始时高度为 0 不显示
+func newResultFilter() *tview.InputField {
+	resultFilter = tview.NewInputField().
+		SetLabel("筛选: ").
+		SetFieldWidth(0).
+		SetPlaceholder("在当前结果中筛选...")
+	resultFilter.SetChangedFunc(func(text string) {
+		if filterOpen {
+			applyResultFilter(text)
+		}
+	})
+	resultFilter.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter || key == tcell.KeyDown {
+			app.SetFocus(wordList)
+		}
+	})
+	return resultFilter
+}
+
+// toggleResultFilter 打开结果筛选框，或关闭它并恢复完整的结果列表
+func toggleResultFilter() {
+	if filterOpen {
+		closeResultFilter()
+		app.SetFocus(wordList)
+		showResultRows(resultTexts, resultWords)
+		setStatus("已关闭筛选")
+		return
+	}
+	if browseMode || len(resultWords) == 0 {
+		setStatus("[yellow]请先搜索，筛选只作用于当前的搜索结果[-]")
+		return
+	}
+	filterOpen = true
+	resultFilter.SetText("")
+	leftPanel.ResizeItem(resultFilter, 1, 0)
+	app.SetFocus(resultFilter)
+	setStatus(fmt.Sprintf("输入关键词筛选当前 %d 个结果，%s 关闭筛选", countWords(resultWords), keyLabel(actionFilter)))
+}
+
+// closeResultFilter 隐藏筛选框并清空其内容，不改变列表
+func closeResultFilter() {
+	filterOpen = false
+	resultFilter.SetText("")
+	leftPanel.ResizeItem(resultFilter, 0, 0)
+}
+
+// applyResultFilter 用筛选词在完整结果中重新挑选并刷新列表
+func applyResultFilter(text string) {
+	texts, words := filterResultRows(resultTexts, resultWords, strings.TrimSpace(text), resultExact)
+	showResultRows(texts, words)
+	if text = strings.TrimSpace(text); text == "" {
+		setStatus("")
+		return
+	}
+	setStatus(fmt.Sprintf("筛选「%s」: %d / %d 个结果", tview.Escape(text), countWords(words), countWords(resultWords)))
+}
+
+// filterResultRows 保留单词中包含 filter 的行（不区分大小写）以及 keep 中的单词，只保留下面还有单词的分组标题；
+// filter 为空时返回全部行
+func filterResultRows(texts, words []string, filter string, keep map[string]bool) ([]string, []string) {
+	if filter == "" {
+		return texts, words
+	}
+	filter = strings.ToLower(filter)
+	var keptTexts, keptWords []string
+	header := -1 // 尚未写入的分组标题
+	for i, word := range words {
+		if word == "" {
+			header = i
+			continue
+		}
+		if !strings.Contains(strings.ToLower(word), filter) && !keep[word] {
+			continue
+		}
+		if header >= 0 {
+			keptTexts = append(keptTexts, texts[header])
+			keptWords = append(keptWords, "")
+			header = -1
+		}
+		keptTexts = append(keptTexts, texts[i])
+		keptWords = append(keptWords, word)
+	}
+	return keptTexts, keptWords
+}
+
+// exactMatches 返回搜索结果中的精确匹配，未开启 keepExactMatch 时返回 nil
+func exactMatches(results []SearchResult) map[string]bool {
+	if !config.KeepExactMatch {
+		return nil
+	}
+	exact := make(map[string]bool)
+	for _, r := range results {
+		if r.Match == MatchExact {
+			exact[r.Word] = true
+		}
+	}
+	return exact
+}
+
+// countWords 返回结果行中单词的个数（不含分组标题）
+func countWords(words []string) int {
+	n := 0
+	for _, w := range words {
+		if w != "" {
+			n++
+		}
+	}
+	return n
+}
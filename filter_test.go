@@ -0,0 +1,95 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSearchKeepExactMatchIgnored(t *testing.T) {
+	tests := []struct {
+		keep    bool
+		listed  []string
+		dropped []string
+	}{
+		// 精确匹配保留并标记为已排除，其余被排除的词不显示
+		{true, []string{"apple", "pineapple"}, []string{"applet", "applejack", "Apple"}},
+		{false, []string{"pineapple"}, []string{"apple", "applet", "applejack", "Apple"}},
+	}
+	for _, tt := range tests {
+		useFixtureDatabases(t)
+		config.IgnoreWords = []string{"app*"}
+		config.KeepExactMatch = tt.keep
+
+		results, err := search("apple")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, word := range tt.listed {
+			if _, ok := matchOf(results, word); !ok {
+				t.Errorf("keepExactMatch 为 %v 时 search(apple) = %v，应包含 %s", tt.keep, wordsOf(results), word)
+			}
+		}
+		for _, word := range tt.dropped {
+			if _, ok := matchOf(results, word); ok {
+				t.Errorf("keepExactMatch 为 %v 时 search(apple) = %v，不应包含 %s", tt.keep, wordsOf(results), word)
+			}
+		}
+		if tt.keep {
+			if first := results[0]; first.Word != "apple" || first.Match != MatchExact || !first.Ignored {
+				t.Errorf("第一个结果为 %+v，应为标记为已排除的精确匹配 apple", first)
+			}
+		}
+		for _, r := range results {
+			if r.Ignored && r.Match != MatchExact {
+				t.Errorf("%s（%s）被标记为已排除，只有精确匹配会保留", r.Word, r.Match.Label())
+			}
+		}
+	}
+}
+
+func TestFilterResultRowsKeepsExactMatch(t *testing.T) {
+	useFixtureDatabases(t)
+	results, err := searchEnglish("apple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	texts, words := buildResultRows(results)
+
+	tests := []struct {
+		filter string
+		keep   bool
+		want   []string // 筛选后的单词（不含分组标题）
+	}{
+		{"jack", true, []string{"apple", "applejack"}},
+		{"jack", false, []string{"applejack"}},
+		{"PINE", true, []string{"apple", "pineapple"}},
+		{"zzz", true, []string{"apple"}},
+		{"zzz", false, nil},
+	}
+	for _, tt := range tests {
+		config.KeepExactMatch = tt.keep
+		keptTexts, keptWords := filterResultRows(texts, words, tt.filter, exactMatches(results))
+		if len(keptTexts) != len(keptWords) {
+			t.Fatalf("筛选结果的行数 %d 与单词数 %d 不同", len(keptTexts), len(keptWords))
+		}
+		var got []string
+		for i, w := range keptWords {
+			if w == "" {
+				// 只保留下面还有单词的分组标题
+				if i+1 >= len(keptWords) || keptWords[i+1] == "" {
+					t.Errorf("筛选 %q 后保留了空的分组标题 %q", tt.filter, keptTexts[i])
+				}
+				continue
+			}
+			got = append(got, w)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("keepExactMatch 为 %v 时筛选 %q 的结果为 %v，应为 %v", tt.keep, tt.filter, got, tt.want)
+		}
+	}
+
+	// 筛选词为空时返回全部行
+	if _, all := filterResultRows(texts, words, "", exactMatches(results)); !slices.Equal(all, words) {
+		t.Errorf("筛选词为空时结果为 %v，应为全部行 %v", all, words)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import "time"
+
+// firstLookupFormat 首次查询日期的保存和显示格式
+const firstLookupFormat = "2006-01-02"
+
+// firstLookups 每个单词第一次被有意查询的日期（开启 firstLookupDates 后才记录），
+// 与查阅次数一起保存在 lookups.json 中，由 lookupMutex 保护；记下后不再改变
+var firstLookups = make(map[string]string)
+
+// recordFirstLookup 在单词还没有首次查询日期时记下今天，调用时需持有 lookupMutex
+func recordFirstLookup(word string) {
+	if !config.FirstLookupDates {
+		return
+	}
+	if _, ok := firstLookups[word]; !ok {
+		firstLookups[word] = time.Now().Format(firstLookupFormat)
+	}
+}
+
+// getFirstLookups 返回首次查询日期的副本，用于保存和导出
+func getFirstLookups() map[string]string {
+	lookupMutex.Lock()
+	defer lookupMutex.Unlock()
+
+	dates := make(map[string]string, len(firstLookups))
+	for w, d := range firstLookups {
+		dates[w] = d
+	}
+	return dates
+}
+
+// mergeFirstLookups 导入首次查询日期，两边都有时保留较早的一个，返回有变化的单词数；调用时需持有 lookupMutex
+func mergeFirstLookups(dates map[string]string) int {
+	updated := 0
+	for w, d := range dates {
+		if _, err := time.Parse(firstLookupFormat, d); err != nil {
+			continue
+		}
+		// 日期格式固定，按字符串比较即可
+		if old, ok := firstLookups[w]; !ok || d < old {
+			firstLookups[w] = d
+			updated++
+		}
+	}
+	return updated
+}
+
+// firstLookupLine 开启 firstLookupDates 时详情末尾显示的首次查询日期，没有记录时为空字符串
+func firstLookupLine(word string) string {
+	if !config.FirstLookupDates {
+		return ""
+	}
+	lookupMutex.Lock()
+	date := firstLookups[word]
+	lookupMutex.Unlock()
+	if date == "" {
+		return ""
+	}
+	return "[gray]首次查询: " + date + "[-]"
+}
@@ -5,6 +5,8 @@ go 1.25
 require (
 	github.com/gdamore/tcell/v2 v2.7.0
 	github.com/rivo/tview v0.0.0-20240101144852-b3bd1aa5e9f2
+	golang.org/x/term v0.15.0
+	golang.org/x/text v0.14.0
 	modernc.org/sqlite v1.28.0
 )
 
@@ -20,8 +22,6 @@ require (
 	github.com/rivo/uniseg v0.4.3 // indirect
 	golang.org/x/mod v0.8.0 // indirect
 	golang.org/x/sys v0.15.0 // indirect
-	golang.org/x/term v0.15.0 // indirect
-	golang.org/x/text v0.14.0 // indirect
 	golang.org/x/tools v0.6.0 // indirect
 	lukechampine.com/uint128 v1.2.0 // indirect
 	modernc.org/cc/v3 v3.40.0 // indirect
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"os"
+)
+
+// 健康检查的整体状态
+const (
+	healthOK       = "ok"       // 两个数据库都能正常查询
+	healthDegraded = "degraded" // 有数据库打不开、查询失败或正在替换
+)
+
+// healthDB 健康检查中一个数据库的结果
+type healthDB struct {
+	File          string `json:"file"`
+	OK            bool   `json:"ok"`
+	Rows          int64  `json:"rows"`           // 词条数（words 或 chinese_words 的行数）
+	SchemaVersion int    `json:"schema_version"` // PRAGMA user_version
+	Error         string `json:"error,omitempty"`
+}
+
+// healthReport /health 接口和 --check 输出的 JSON
+type healthReport struct {
+	Status  string   `json:"status"`
+	English healthDB `json:"english"`
+	Chinese healthDB `json:"chinese"`
+}
+
+// checkDBHealth 在 db 上读取 schema 版本并统计 table 的行数，作为能否正常查询的检查
+func checkDBHealth(db *sql.DB, file, table string) healthDB {
+	result := healthDB{File: file}
+	if db == nil {
+		result.Error = "数据库未打开"
+		return result
+	}
+	ctx, cancel := queryContext()
+	defer cancel()
+	if err := db.QueryRowContext(ctx, `PRAGMA user_version`).Scan(&result.SchemaVersion); err != nil {
+		result.Error = timeoutError(ctx, err).Error()
+		return result
+	}
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+table).Scan(&result.Rows); err != nil {
+		result.Error = timeoutError(ctx, err).Error()
+		return result
+	}
+	// 旧版本生成的数据库缺少新的列，用到这些列的查询会失败
+	if result.SchemaVersion < schemaVersion {
+		result.Error = schemaError(result.SchemaVersion).Error()
+		return result
+	}
+	result.OK = true
+	return result
+}
+
+// checkHealth 检查两个已打开的数据库，任何一个不能查询时整体状态为 degraded
+func checkHealth() healthReport {
+	if err := databaseReady(); err != nil {
+		return healthReport{
+			Status:  healthDegraded,
+			English: healthDB{File: englishDBFile, Error: err.Error()},
+			Chinese: healthDB{File: chineseDBFile, Error: err.Error()},
+		}
+	}
+	report := healthReport{
+		Status:  healthOK,
+		English: checkDBHealth(englishDB, englishDBFile, "words"),
+		Chinese: checkDBHealth(chineseDB, chineseDBFile, "chinese_words"),
+	}
+	if !report.English.OK || !report.Chinese.OK {
+		report.Status = healthDegraded
+	}
+	return report
+}
+
+// handleHealthRequest 处理 /health 请求：正常时返回 200，degraded 时返回 503，内容相同
+func handleHealthRequest(w http.ResponseWriter, r *http.Request) {
+	report := checkHealth()
+	status := http.StatusOK
+	if report.Status != healthOK {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, report)
+}
+
+// unopenedDBHealth 数据库没能打开时的结果：文件不存在时说明是哪个文件，否则使用打开时的错误
+func unopenedDBHealth(file string, err error) healthDB {
+	if _, statErr := os.Stat(file); statErr != nil {
+		return healthDB{File: file, Error: "找不到数据库文件，请先运行 dict build"}
+	}
+	return healthDB{File: file, Error: err.Error()}
+}
+
+// runHealthCheck 执行 --check：打开数据库并把健康检查结果以 JSON 输出到标准输出，状态不是 ok 时返回 false
+func runHealthCheck() bool {
+	var report healthReport
+	if err := openExistingDatabases(); err != nil {
+		report = healthReport{
+			Status:  healthDegraded,
+			English: unopenedDBHealth(englishDBFile, err),
+			Chinese: unopenedDBHealth(chineseDBFile, err),
+		}
+	} else {
+		report = checkHealth()
+		closeDatabases()
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(report)
+	return report.Status == healthOK
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const maxHistorySuggestions = 8 // 搜索框下拉列表中最多显示的历史记录数
+
+var (
+	historyDropdownOpen   bool // 搜索框下方正显示匹配的历史记录
+	historyDropdownActive bool // 已按上下键进入下拉列表，此时 Enter 选择其中的记录
+	historyDropdownHidden bool // 正在关闭下拉列表，historySuggestions 不返回记录
+)
+
+// setupHistoryDropdown 输入时在搜索框下方列出以输入内容开头的历史记录，选中后重新搜索该记录
+func setupHistoryDropdown() {
+	searchInput.SetAutocompleteStyles(tcell.ColorDarkSlateGray,
+		tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorDarkSlateGray),
+		tcell.StyleDefault.Foreground(tcell.ColorBlack).Background(tcell.ColorYellow))
+	searchInput.SetAutocompleteFunc(historySuggestions)
+	searchInput.SetAutocompletedFunc(func(text string, index, source int) bool {
+		if source == tview.AutocompletedNavigate {
+			return false
+		}
+		// 先清除标记，SetFocus 触发的 blur 不再重复关闭下拉列表
+		historyDropdownOpen = false
+		historyDropdownActive = false
+		searchInput.SetText(text)
+		app.SetFocus(wordList)
+		return true
+	})
+	searchInput.SetBlurFunc(func() {
+		if historyDropdownOpen {
+			closeHistoryDropdown()
+		}
+	})
+}
+
+// historySuggestions 返回以 text 开头（不区分大小写）的历史记录，最近的在前，不包括与 text 相同的记录
+//
+// 浏览模式下输入的是前缀而不是单词，不显示历史记录
+func historySuggestions(text string) []string {
+	query := strings.ToLower(normalizeQuery(text))
+	var entries []string
+	if query != "" && !browseMode && !historyDropdownHidden {
+		for _, word := range getSearchHistory() {
+			lower := strings.ToLower(word)
+			if lower != query && strings.HasPrefix(lower, query) {
+				entries = append(entries, tview.Escape(word))
+				if len(entries) == maxHistorySuggestions {
+					break
+				}
+			}
+		}
+	}
+	historyDropdownOpen = len(entries) > 0
+	if !historyDropdownOpen {
+		historyDropdownActive = false
+	}
+	return entries
+}
+
+// closeHistoryDropdown 关闭历史记录下拉列表（不能在搜索框自己的按键处理过程中调用）
+func closeHistoryDropdown() {
+	historyDropdownOpen = false
+	historyDropdownActive = false
+	historyDropdownHidden = true
+	searchInput.Autocomplete()
+	historyDropdownHidden = false
+}
+
+// handleHistoryDropdownKey 在下拉列表显示时先于全局快捷键处理按键，handled 为 false 时按键照常处理
+//
+// 第一次按上下键进入列表，之后上下键在列表中移动、Enter 或 Tab 选择；
+// 没有进入列表时 Enter 和 Tab 关闭列表并照常使用，不影响直接查看搜索结果。Esc 只关闭列表
+func handleHistoryDropdownKey(event *tcell.EventKey) (result *tcell.EventKey, handled bool) {
+	switch event.Key() {
+	case tcell.KeyUp, tcell.KeyDown, tcell.KeyPgUp, tcell.KeyPgDn:
+		if !historyDropdownActive {
+			historyDropdownActive = true
+			setStatus("↑/↓ 选择历史记录，Enter 重新搜索，Esc 关闭")
+			return nil, true
+		}
+		return event, true
+	case tcell.KeyEnter, tcell.KeyTab:
+		if historyDropdownActive {
+			return event, true
+		}
+		closeHistoryDropdown()
+		return event, false
+	case tcell.KeyEscape:
+		closeHistoryDropdown()
+		setStatus("")
+		return nil, true
+	}
+	return event, false
+}
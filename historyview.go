@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+var (
+	pages         *tview.Pages      // 根页面，包含主界面、历史记录界面和测验界面
+	historyFilter *tview.InputField // 历史记录界面的筛选框
+	historyList   *tview.List       // 历史记录界面的列表
+	historyView   *tview.Flex       // 历史记录界面的外层容器
+	historyWords  []string          // historyList 每一行对应的单词
+)
+
+// newHistoryView 创建完整历史记录界面：筛选框、列表和底部提示
+func newHistoryView() *tview.Flex {
+	historyFilter = tview.NewInputField().
+		SetLabel("筛选: ").
+		SetFieldWidth(0).
+		SetPlaceholder("输入关键词筛选历史记录...")
+
+	historyList = tview.NewList().
+		ShowSecondaryText(false).
+		SetWrapAround(false).
+		SetMainTextColor(tcell.ColorWhite).
+		SetSelectedTextColor(tcell.ColorBlack).
+		SetSelectedBackgroundColor(tcell.ColorYellow)
+
+	hint := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText("[gray]Enter 打开单词 · Delete/d 删除记录 · Tab 切换筛选框和列表 · Esc/" + keyLabel(actionHistory) + " 返回[-]")
+
+	historyFilter.SetChangedFunc(func(text string) {
+		refreshHistoryList()
+	})
+	historyFilter.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			app.SetFocus(historyList)
+		}
+	})
+
+	historyList.SetSelectedFunc(func(index int, mainText string, secondaryText string, shortcut rune) {
+		if index < len(historyWords) {
+			openHistoryWord(historyWords[index])
+		}
+	})
+
+	historyView = tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(historyFilter, 1, 0, true).
+		AddItem(historyList, 0, 1, false).
+		AddItem(hint, 1, 0, false)
+	historyView.SetBorder(true)
+	historyView.SetInputCapture(handleHistoryKey)
+	return historyView
+}
+
+// showHistoryView 打开完整历史记录界面
+func showHistoryView() {
+	historyFilter.SetText("")
+	refreshHistoryList()
+	pages.ShowPage("history")
+	app.SetFocus(historyFilter)
+}
+
+// closeHistoryView 关闭历史记录界面，回到主界面
+func closeHistoryView() {
+	pages.HidePage("history")
+	app.SetFocus(searchInput)
+
+	// 搜索框为空时主界面显示的是最近历史，可能已被删除，需要刷新
+	if searchInput.GetText() == "" {
+		showInitialWords()
+	}
+}
+
+// refreshHistoryList 按筛选框的内容重新填充历史列表（不区分大小写的包含匹配）
+func refreshHistoryList() {
+	filter := strings.ToLower(strings.TrimSpace(historyFilter.GetText()))
+	history := getSearchHistory()
+
+	current := historyList.GetCurrentItem()
+	historyList.Clear()
+	historyWords = nil
+	for _, word := range history {
+		if filter != "" && !strings.Contains(strings.ToLower(word), filter) {
+			continue
+		}
+		historyWords = append(historyWords, word)
+		historyList.AddItem(tview.Escape(word), "", 0, nil)
+	}
+
+	if current >= len(historyWords) {
+		current = len(historyWords) - 1
+	}
+	if current >= 0 {
+		historyList.SetCurrentItem(current)
+	}
+
+	title := fmt.Sprintf("搜索历史（共 %d 条）", len(history))
+	if filter != "" {
+		title = fmt.Sprintf("搜索历史（%d / %d 条）", len(historyWords), len(history))
+	}
+	historyView.SetTitle(title)
+}
+
+// handleHistoryKey 处理历史记录界面中的按键
+func handleHistoryKey(event *tcell.EventKey) *tcell.EventKey {
+	if event.Key() == tcell.KeyEsc || keyBindings[event.Key()] == actionHistory {
+		closeHistoryView()
+		return nil
+	}
+	switch event.Key() {
+	case tcell.KeyTab:
+		if app.GetFocus() == historyFilter {
+			app.SetFocus(historyList)
+		} else {
+			app.SetFocus(historyFilter)
+		}
+		return nil
+	case tcell.KeyDown:
+		if app.GetFocus() == historyFilter {
+			app.SetFocus(historyList)
+			return nil
+		}
+	case tcell.KeyDelete:
+		if app.GetFocus() == historyList {
+			deleteHistoryItem(historyList.GetCurrentItem())
+			return nil
+		}
+	case tcell.KeyRune:
+		if app.GetFocus() == historyList {
+			if event.Rune() == 'd' {
+				deleteHistoryItem(historyList.GetCurrentItem())
+			} else {
+				// 在列表中直接打字时转到筛选框
+				app.SetFocus(historyFilter)
+				return event
+			}
+			return nil
+		}
+	}
+	return event
+}
+
+// deleteHistoryItem 删除历史列表第 index 行对应的记录
+func deleteHistoryItem(index int) {
+	if index < 0 || index >= len(historyWords) {
+		return
+	}
+	word := historyWords[index]
+	removeFromHistory(word)
+	refreshHistoryList()
+	setStatus("已从历史记录中删除 " + tview.Escape(word))
+}
+
+// openHistoryWord 关闭历史记录界面并搜索选中的单词
+func openHistoryWord(word string) {
+	searchInput.SetText(word)
+	closeHistoryView()
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// htmlSense 中文释义中的一个词性分组
+type htmlSense struct {
+	POS      string
+	POSClass string // 词性徽标的 CSS 类，如 pos-noun；领域标记（[计]）为 field
+	Senses   []string
+}
+
+// htmlDefinition 英文释义的一行
+type htmlDefinition struct {
+	POS      string
+	POSClass string
+	Text     string
+}
+
+// htmlPhonetic 一个音标，Label 为空表示不区分英美
+type htmlPhonetic struct {
+	Label string
+	Text  string
+}
+
+// htmlWord 渲染 HTML 时使用的单词内容
+type htmlWord struct {
+	Word        string
+	Phonetics   []htmlPhonetic
+	Senses      []htmlSense
+	Definitions []htmlDefinition
+	BNC         string
+	Difficulty  string
+	Source      string
+	Colors      Theme
+}
+
+// htmlTemplate 独立的 HTML 页面：详情中各部分的颜色（黄色栏目标题、绿色圆点、词性徽标）对应为 CSS 类，
+// 只用内联样式表，可以直接粘贴到网页笔记中
+var htmlTemplate = template.Must(template.New("word").Parse(`<!DOCTYPE html>
+<html lang="zh">
+<head>
+<meta charset="utf-8">
+<title>{{.Word}}</title>
+<style>
+.dict-word { font-family: sans-serif; max-width: 40em; line-height: 1.6; }
+.dict-word .headword { margin-bottom: 0.2em; }
+.dict-word .phonetic { color: #555; margin-top: 0; }
+.dict-word .phonetic-label { color: #888; font-size: 0.85em; margin-right: 0.3em; }
+.dict-word .section-title { color: #b8860b; font-size: 1em; margin-bottom: 0.3em; }
+.dict-word ul { padding-left: 1.2em; }
+.dict-word li::marker { color: green; }
+.dict-word .pos { color: #000; border-radius: 3px; padding: 0 0.3em; margin-right: 0.4em; font-size: 0.9em; }
+.dict-word .pos-noun { background: {{.Colors.PosNoun}}; }
+.dict-word .pos-verb { background: {{.Colors.PosVerb}}; }
+.dict-word .pos-adjective { background: {{.Colors.PosAdjective}}; }
+.dict-word .pos-adverb { background: {{.Colors.PosAdverb}}; }
+.dict-word .pos-other { background: {{.Colors.PosOther}}; }
+.dict-word .field { color: darkcyan; margin-right: 0.4em; }
+.dict-word .badge { display: inline-block; border: 1px solid #ccc; border-radius: 1em; padding: 0 0.6em; margin-right: 0.4em; font-size: 0.85em; color: #555; }
+.dict-word .source { color: #888; font-size: 0.85em; }
+</style>
+</head>
+<body>
+<article class="dict-word">
+<h1 class="headword">{{.Word}}</h1>
+{{- if .Phonetics}}
+<p class="phonetic">{{range $i, $p := .Phonetics}}{{if $i}} {{end}}{{if $p.Label}}<span class="phonetic-label">{{$p.Label}}</span>{{end}}/{{$p.Text}}/{{end}}</p>
+{{- end}}
+{{- if .Senses}}
+<section class="translation">
+<h2 class="section-title">中文释义</h2>
+<ul>
+{{- range .Senses}}
+<li>{{if .POS}}<span class="{{.POSClass}}">{{.POS}}</span>{{end}}
+{{- if eq (len .Senses) 1}}{{index .Senses 0}}{{else}}
+<ol>{{range .Senses}}<li>{{.}}</li>{{end}}</ol>
+{{- end}}</li>
+{{- end}}
+</ul>
+</section>
+{{- end}}
+{{- if .Definitions}}
+<section class="definition">
+<h2 class="section-title">英文释义</h2>
+<ul>
+{{- range .Definitions}}
+<li>{{if .POS}}<span class="{{.POSClass}}">{{.POS}}</span>{{end}}{{.Text}}</li>
+{{- end}}
+</ul>
+</section>
+{{- end}}
+{{- if or .BNC .Difficulty}}
+<p class="frequency">{{if .BNC}}<span class="badge bnc">BNC词频: {{.BNC}}</span>{{end}}{{if .Difficulty}}<span class="badge difficulty">{{.Difficulty}}</span>{{end}}</p>
+{{- end}}
+{{- if .Source}}
+<p class="source">来源: {{.Source}}</p>
+{{- end}}
+</article>
+</body>
+</html>
+`))
+
+// posClass 返回词性标记对应的 CSS 类
+func posClass(marker string) string {
+	return "pos pos-" + posCategory(marker)
+}
+
+// wordHTML 把英文单词的详情渲染为独立的 HTML 页面
+func wordHTML(w Word) (string, error) {
+	data := htmlWord{
+		Word:       w.Word,
+		BNC:        formatBNC(w.Bnc),
+		Difficulty: wordDifficulty(w.Word, w.Bnc),
+		Colors:     config.Theme,
+	}
+	if (multipleSources || w.Source == userWordSource) && w.Source != "" {
+		data.Source = w.Source
+	}
+	if phonetics := splitPhonetics(w); len(phonetics) > 0 {
+		for _, p := range phonetics {
+			data.Phonetics = append(data.Phonetics, htmlPhonetic{p.label, p.text})
+		}
+	} else if w.Phonetic != "" {
+		data.Phonetics = []htmlPhonetic{{"", w.Phonetic}}
+	}
+	for _, g := range parseSenses(w.Translation) {
+		class := "field"
+		if posMarkerRegex.MatchString(g.POS) {
+			class = posClass(g.POS)
+		}
+		data.Senses = append(data.Senses, htmlSense{POS: g.POS, POSClass: class, Senses: g.Senses})
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(w.Definition, "\\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		d := htmlDefinition{Text: line}
+		if m := definitionPOSRegex.FindStringSubmatch(line); m != nil {
+			d.POS, d.POSClass, d.Text = m[1], posClass(m[1]), line[len(m[0]):]
+		}
+		data.Definitions = append(data.Definitions, d)
+	}
+
+	var b strings.Builder
+	if err := htmlTemplate.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("生成 HTML 失败: %v", err)
+	}
+	return b.String(), nil
+}
+
+// lookupHTML 查询英文单词并渲染为 HTML，中文词不支持
+func lookupHTML(word string) (string, error) {
+	if isChinese(word) {
+		return "", fmt.Errorf("HTML 导出只支持英文单词")
+	}
+	w, err := lookupEnglishWord(word)
+	if err != nil {
+		return "", err
+	}
+	return wordHTML(w)
+}
+
+// copyDetailHTML 把当前英文单词的详情以 HTML 复制到剪贴板
+func copyDetailHTML() {
+	if currentWord == "" || isChinese(currentWord) {
+		setStatus("[yellow]请先选中一个英文单词[-]")
+		return
+	}
+
+	word := currentWord
+	go func() {
+		page, err := lookupHTML(word)
+		if err == nil {
+			err = copyToClipboard(page)
+		}
+		app.QueueUpdateDraw(func() {
+			if err != nil {
+				showError(err)
+				return
+			}
+			setStatus("已复制 " + tview.Escape(word) + " 的 HTML 详情")
+		})
+	}()
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// validateIgnoreWords 检查 ignoreWords 中的通配符模式是否合法
+func validateIgnoreWords(patterns []string) error {
+	for _, p := range patterns {
+		if strings.TrimSpace(p) == "" {
+			return fmt.Errorf("ignoreWords 中不能有空字符串")
+		}
+		if _, err := path.Match(strings.ToLower(p), ""); err != nil {
+			return fmt.Errorf("ignoreWords 中的模式 %q 无效: %v", p, err)
+		}
+	}
+	return nil
+}
+
+// isIgnored 判断单词是否匹配 ignoreWords 中的某个单词或通配符模式（* 任意字符、? 单个字符、[abc] 字符集），不区分大小写
+func isIgnored(word string) bool {
+	if len(config.IgnoreWords) == 0 {
+		return false
+	}
+	word = strings.ToLower(word)
+	for _, p := range config.IgnoreWords {
+		if ok, _ := path.Match(strings.ToLower(p), word); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// removeIgnored 从搜索结果中去掉 ignoreWords 排除的单词，保持其余结果的顺序
+//
+// 开启 keepExactMatch 时精确匹配总是保留，只标记为 Ignored，避免输入了完整的单词却看不到它
+func removeIgnored(results []SearchResult) []SearchResult {
+	if len(config.IgnoreWords) == 0 {
+		return results
+	}
+	kept := results[:0]
+	for _, r := range results {
+		if !isIgnored(r.Word) {
+			kept = append(kept, r)
+		} else if config.KeepExactMatch && r.Match == MatchExact {
+			r.Ignored = true
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
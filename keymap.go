@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// 可以在配置文件 keymap 中重新绑定按键的动作
+const (
+	actionQuit            = "quit"             // 退出程序
+	actionFocusNext       = "focus-next"       // 在搜索框、单词列表、详情面板间切换焦点
+	actionBrowse          = "browse"           // 切换浏览模式
+	actionSplit           = "split"            // 切换分栏布局
+	actionPin             = "pin"              // 固定当前单词用于对比
+	actionPersonalRanking = "personal-ranking" // 切换按查阅次数排序
+	actionPronounce       = "pronounce"        // 播放发音
+	actionFavorite        = "favorite"         // 收藏 / 取消收藏
+	actionRandomFavorite  = "random-favorite"  // 随机复习收藏
+	actionExpand          = "expand"           // 显示完整详情
+	actionHistory         = "history"          // 打开完整搜索历史
+	actionEmphasis        = "emphasis"         // 加强显示词头
+	actionPhoneticMode    = "phonetic-mode"    // 发音练习模式
+	actionCopyPhonetic    = "copy-phonetic"    // 复制音标
+	actionQuiz            = "quiz"             // 单词测验
+	actionBack            = "back"             // 返回从详情链接跳转前的单词
+	actionFamilies        = "families"         // 切换按词族分组显示结果
+	actionReload          = "reload"           // 重新打开数据库文件
+	actionWrap            = "wrap"             // 切换详情面板的自动换行
+	actionLock            = "lock"             // 把详情面板锁定在当前单词上
+	actionFilter          = "filter"           // 在当前搜索结果中筛选
+	actionLeaderboard     = "leaderboard"      // 查阅次数排行
+	actionCopyHTML        = "copy-html"        // 以 HTML 复制单词详情
+	actionSurprise        = "surprise"         // 随机显示一个单词的详情
+	actionNextSection     = "next-section"     // 详情滚动到下一个栏目
+	actionPrevSection     = "prev-section"     // 详情滚动到上一个栏目
+	actionShare           = "share"            // 复制分享当前单词的命令或链接
+	actionScope           = "scope"            // 切换显示的结果范围：精确、前缀、包含、全部
+)
+
+// keyActions 所有可绑定的动作及其默认按键
+var keyActions = []struct {
+	name string
+	key  tcell.Key
+}{
+	{actionQuit, tcell.KeyEsc},
+	{actionFocusNext, tcell.KeyTab},
+	{actionBrowse, tcell.KeyF2},
+	{actionSplit, tcell.KeyF3},
+	{actionPin, tcell.KeyF4},
+	{actionPersonalRanking, tcell.KeyF5},
+	{actionPronounce, tcell.KeyF6},
+	{actionFavorite, tcell.KeyF7},
+	{actionRandomFavorite, tcell.KeyF8},
+	{actionExpand, tcell.KeyF9},
+	{actionHistory, tcell.KeyF10},
+	{actionEmphasis, tcell.KeyF11},
+	{actionPhoneticMode, tcell.KeyF12},
+	{actionCopyPhonetic, tcell.KeyCtrlP},
+	{actionQuiz, tcell.KeyCtrlT},
+	{actionBack, tcell.KeyCtrlO},
+	{actionFamilies, tcell.KeyCtrlG},
+	{actionReload, tcell.KeyCtrlR},
+	{actionWrap, tcell.KeyCtrlL},
+	{actionLock, tcell.KeyCtrlN},
+	{actionFilter, tcell.KeyCtrlS},
+	{actionLeaderboard, tcell.KeyCtrlB},
+	{actionCopyHTML, tcell.KeyCtrlY},
+	{actionSurprise, tcell.KeyF1},
+	{actionNextSection, tcell.KeyCtrlJ},
+	{actionPrevSection, tcell.KeyCtrlK},
+	{actionShare, tcell.KeyCtrlD},
+	{actionScope, tcell.KeyCtrlX},
+}
+
+// reservedKeys 单词列表和输入框自身使用的按键，不能绑定到动作上
+var reservedKeys = map[tcell.Key]bool{
+	tcell.KeyUp: true, tcell.KeyDown: true, tcell.KeyLeft: true, tcell.KeyRight: true,
+	tcell.KeyEnter: true, tcell.KeyBackspace: true, tcell.KeyBackspace2: true, tcell.KeyDelete: true,
+	tcell.KeyHome: true, tcell.KeyEnd: true, tcell.KeyPgUp: true, tcell.KeyPgDn: true,
+	tcell.KeyCtrlC: true, // tview 收到 Ctrl+C 时直接退出程序
+}
+
+// keymapEnter keymap 中的特殊项：值不是按键名，而是在单词列表中按 Enter 时的行为（enterActions 之一）
+const keymapEnter = "enter"
+
+// 在单词列表中按 Enter（或点击）时的行为
+const (
+	enterLoad            = "load"                  // 记入历史并加载详情，焦点留在列表中
+	enterLoadFocusDetail = "load-and-focus-detail" // 记入历史、加载详情并把焦点移到详情面板
+	enterFocusDetail     = "focus-detail"          // 只把焦点移到详情面板，不记入历史
+	enterCopy            = "copy"                  // 把单词复制到剪贴板
+)
+
+// enterActions keymap.enter 可选的行为
+var enterActions = []string{enterLoad, enterLoadFocusDetail, enterFocusDetail, enterCopy}
+
+// enterAction 当前生效的 Enter 行为，启动时根据配置文件设置
+var enterAction = enterLoad
+
+// Keymap 配置文件中的按键绑定：动作名 → 按键名（如 "F7"、"Ctrl+F"），空字符串表示取消该动作的按键
+//
+// 未列出的动作使用默认按键
+type Keymap map[string]string
+
+// bindings 把默认按键和配置中的修改合并为 按键 → 动作 的映射，并检查动作名、按键名和重复绑定
+func (k Keymap) bindings() (map[tcell.Key]string, error) {
+	keys := make(map[string]tcell.Key, len(keyActions))
+	known := make(map[string]bool, len(keyActions))
+	var names []string
+	for _, a := range keyActions {
+		keys[a.name] = a.key
+		known[a.name] = true
+		names = append(names, a.name)
+	}
+
+	for action, name := range k {
+		if action == keymapEnter {
+			continue
+		}
+		if !known[action] {
+			return nil, fmt.Errorf("keymap 中的动作 %q 不存在（可选 %s，或用 enter 设置 Enter 的行为）", action, strings.Join(names, "、"))
+		}
+		if name == "" {
+			delete(keys, action)
+			continue
+		}
+		key, ok := parseKeyName(name)
+		if !ok {
+			return nil, fmt.Errorf("keymap.%s 的按键 %q 无法识别（可用 F1-F12、Ctrl+字母、Esc、Tab 等）", action, name)
+		}
+		if reservedKeys[key] {
+			return nil, fmt.Errorf("keymap.%s 的按键 %s 用于列表和输入框，不能重新绑定", action, keyName(key))
+		}
+		keys[action] = key
+	}
+
+	// 按动作的固定顺序检查，报错信息稳定
+	result := make(map[tcell.Key]string, len(keys))
+	for _, a := range keyActions {
+		key, ok := keys[a.name]
+		if !ok {
+			continue
+		}
+		if other, dup := result[key]; dup {
+			return nil, fmt.Errorf("keymap 中 %s 和 %s 都绑定了 %s", other, a.name, keyName(key))
+		}
+		result[key] = a.name
+	}
+	return result, nil
+}
+
+// enterBehavior 返回 keymap.enter 设置的 Enter 行为，未设置时为 load
+func (k Keymap) enterBehavior() (string, error) {
+	behavior, ok := k[keymapEnter]
+	if !ok {
+		return enterLoad, nil
+	}
+	for _, a := range enterActions {
+		if behavior == a {
+			return behavior, nil
+		}
+	}
+	return "", fmt.Errorf("keymap.enter 的值 %q 无效（可选 %s）", behavior, strings.Join(enterActions, "、"))
+}
+
+// keyBindings 当前生效的按键绑定（按键 → 动作），启动时根据配置文件设置
+var keyBindings, _ = Keymap(nil).bindings()
+
+// parseKeyName 把按键名解析为 tcell 按键，名称不区分大小写，Ctrl+P 和 Ctrl-P 均可
+func parseKeyName(name string) (tcell.Key, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if strings.HasPrefix(name, "ctrl+") {
+		name = "ctrl-" + name[len("ctrl+"):]
+	}
+	for key, n := range tcell.KeyNames {
+		if strings.ToLower(n) == name {
+			return key, true
+		}
+	}
+	return 0, false
+}
+
+// keyName 返回按键在界面和提示中显示的名称，如 F7、Ctrl+P
+func keyName(key tcell.Key) string {
+	return strings.Replace(tcell.KeyNames[key], "Ctrl-", "Ctrl+", 1)
+}
+
+// keyLabel 返回动作当前绑定的按键名称，用于状态栏提示
+func keyLabel(action string) string {
+	for key, a := range keyBindings {
+		if a == action {
+			return keyName(key)
+		}
+	}
+	return "未绑定按键"
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// leaderboardSize 查阅排行界面列出的单词数
+const leaderboardSize = 50
+
+// lookupRank 查阅排行中的一个单词
+type lookupRank struct {
+	word  string
+	count int
+}
+
+// topLookups 返回查阅次数最多的 n 个单词，次数相同时按字母顺序；n 小于等于 0 时返回全部
+func topLookups(n int) []lookupRank {
+	var ranks []lookupRank
+	for word, count := range getLookupCounts() {
+		if count > 0 {
+			ranks = append(ranks, lookupRank{word, count})
+		}
+	}
+	sort.Slice(ranks, func(i, j int) bool {
+		if ranks[i].count != ranks[j].count {
+			return ranks[i].count > ranks[j].count
+		}
+		return ranks[i].word < ranks[j].word
+	})
+	if n > 0 && len(ranks) > n {
+		ranks = ranks[:n]
+	}
+	return ranks
+}
+
+// runTop 执行 --top：按查阅次数列出查得最多的 n 个单词；数据库存在时附上简短释义
+func runTop(n int) error {
+	if err := loadState(); err != nil {
+		return fmt.Errorf("读取用户数据失败: %v", err)
+	}
+	ranks := topLookups(n)
+	if len(ranks) == 0 {
+		consolePrintln("还没有查阅记录")
+		return nil
+	}
+	withPreview := openExistingDatabases() == nil
+	if withPreview {
+		defer closeDatabases()
+	}
+	for i, r := range ranks {
+		line := fmt.Sprintf("%3d. %-20s %4d 次", i+1, r.word, r.count)
+		if withPreview {
+			if preview := previewWord(r.word); preview != "" {
+				line += "  " + preview
+			}
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+var (
+	leaderboardList  *tview.List // 查阅排行界面的列表
+	leaderboardView  *tview.Flex // 查阅排行界面的外层容器
+	leaderboardWords []string    // leaderboardList 每一行对应的单词
+)
+
+// newLeaderboardView 创建查阅排行界面：列表和底部提示
+func newLeaderboardView() *tview.Flex {
+	leaderboardList = tview.NewList().
+		ShowSecondaryText(false).
+		SetWrapAround(false).
+		SetMainTextColor(tcell.ColorWhite).
+		SetSelectedTextColor(tcell.ColorBlack).
+		SetSelectedBackgroundColor(tcell.ColorYellow)
+	leaderboardList.SetSelectedFunc(func(index int, mainText string, secondaryText string, shortcut rune) {
+		if index < len(leaderboardWords) {
+			closeLeaderboardView()
+			searchInput.SetText(leaderboardWords[index])
+		}
+	})
+
+	hint := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText("[gray]Enter 查看单词 · Esc/" + keyLabel(actionLeaderboard) + " 返回[-]")
+
+	leaderboardView = tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(leaderboardList, 0, 1, true).
+		AddItem(hint, 1, 0, false)
+	leaderboardView.SetBorder(true).SetTitle("查阅次数排行")
+	leaderboardView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc || keyBindings[event.Key()] == actionLeaderboard {
+			closeLeaderboardView()
+			return nil
+		}
+		return event
+	})
+	return leaderboardView
+}
+
+// showLeaderboardView 打开查阅排行界面，列出查得最多的单词及其简短释义
+func showLeaderboardView() {
+	ranks := topLookups(leaderboardSize)
+	if len(ranks) == 0 {
+		setStatus("[yellow]还没有查阅记录[-]")
+		return
+	}
+	leaderboardList.Clear()
+	leaderboardWords = nil
+	for i, r := range ranks {
+		text := fmt.Sprintf("%2d. %s [gray]× %d[-]", i+1, tview.Escape(r.word), r.count)
+		if preview := previewWord(r.word); preview != "" {
+			text += "  [gray]" + tview.Escape(preview) + "[-]"
+		}
+		leaderboardWords = append(leaderboardWords, r.word)
+		leaderboardList.AddItem(text, "", 0, nil)
+	}
+	leaderboardView.SetTitle(fmt.Sprintf("查阅次数排行（前 %d 个）", len(ranks)))
+	pages.ShowPage("leaderboard")
+	app.SetFocus(leaderboardList)
+}
+
+// closeLeaderboardView 关闭查阅排行界面，回到主界面
+func closeLeaderboardView() {
+	pages.HidePage("leaderboard")
+	app.SetFocus(searchInput)
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// logLevel 诊断日志的级别，数值越大越严重
+//
+// 诊断日志与 consolePrint 输出的提示信息分开：提示信息是给用户看的初始化和进度说明，
+// 诊断日志记录查询耗时、被跳过的数据等排查问题用的细节，写到标准错误或日志文件
+type logLevel int
+
+const (
+	levelDebug logLevel = iota // 每次查询的 SQL 和耗时
+	levelInfo                  // 打开数据库、HTTP 请求等运行过程
+	levelWarn                  // 不影响继续运行的问题，如跳过了无法写入的记录
+	levelError                 // 操作失败
+)
+
+// logLevelNames 各级别在命令行参数、配置文件和日志中的名称
+var logLevelNames = []string{"debug", "info", "warn", "error"}
+
+// currentLogLevel 当前的日志级别，低于它的日志不记录；实现了 flag.Value，可直接作为 --log-level 参数
+var currentLogLevel = levelWarn
+
+// logger 诊断日志的输出，默认写到标准错误
+var logger = log.New(os.Stderr, "", log.Ltime|log.Lmicroseconds)
+
+// parseLogLevel 解析日志级别名称，不区分大小写
+func parseLogLevel(name string) (logLevel, error) {
+	for i, n := range logLevelNames {
+		if strings.EqualFold(name, n) {
+			return logLevel(i), nil
+		}
+	}
+	return 0, fmt.Errorf("日志级别 %q 无效（可选 %s）", name, strings.Join(logLevelNames, "、"))
+}
+
+// String 返回级别名称
+func (l *logLevel) String() string {
+	if int(*l) < len(logLevelNames) {
+		return logLevelNames[*l]
+	}
+	return ""
+}
+
+// Set 解析命令行参数中的级别名称
+func (l *logLevel) Set(name string) error {
+	level, err := parseLogLevel(name)
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+// lazyLogFile 第一次写入时才创建的日志文件，没有需要记录的内容时不会留下空文件
+type lazyLogFile struct {
+	path string
+	mu   sync.Mutex
+	file *os.File
+}
+
+// Write 写入日志，文件无法打开时丢弃本条日志
+func (f *lazyLogFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return 0, err
+		}
+		f.file = file
+	}
+	return f.file.Write(p)
+}
+
+// Close 关闭已经打开的日志文件
+func (f *lazyLogFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Close()
+}
+
+// setLogFile 把诊断日志写入 path（交互界面占用终端时使用），返回的函数用于关闭日志文件
+//
+// 级别为 debug 或 info 时说明用户需要日志，立即打开文件以便及早报告路径错误；
+// 否则等到第一条警告时才创建文件
+func setLogFile(path string) (func(), error) {
+	if currentLogLevel <= levelInfo {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("无法打开日志文件 %s: %v", path, err)
+		}
+		logger.SetOutput(f)
+		return func() { f.Close() }, nil
+	}
+	f := &lazyLogFile{path: path}
+	logger.SetOutput(f)
+	return func() { f.Close() }, nil
+}
+
+// logf 以指定级别记录一条诊断日志
+func logf(level logLevel, format string, a ...interface{}) {
+	if level < currentLogLevel {
+		return
+	}
+	logger.Printf("%-5s %s", strings.ToUpper(logLevelNames[level]), fmt.Sprintf(format, a...))
+}
+
+// logDebugf 记录调试日志
+func logDebugf(format string, a ...interface{}) { logf(levelDebug, format, a...) }
+
+// logInfof 记录运行过程
+func logInfof(format string, a ...interface{}) { logf(levelInfo, format, a...) }
+
+// logWarnf 记录警告
+func logWarnf(format string, a ...interface{}) { logf(levelWarn, format, a...) }
+
+// logErrorf 记录错误
+func logErrorf(format string, a ...interface{}) { logf(levelError, format, a...) }
+
+// logQuery 在 debug 级别记录一次查询的阶段、耗时、返回行数和 SQL，rows 为 -1 时不显示行数，query 为空时只记录参数
+func logQuery(label string, start time.Time, rows int, query string, args ...interface{}) {
+	if currentLogLevel > levelDebug {
+		return
+	}
+	elapsed := time.Since(start).Round(time.Microsecond)
+	count := ""
+	if rows >= 0 {
+		count = fmt.Sprintf(" %d 行", rows)
+	}
+	if query != "" {
+		query = strings.Join(strings.Fields(query), " ") + " "
+	}
+	logDebugf("[%s] %v%s | %s%s", label, elapsed, count, query, formatArgs(args))
+}
+
+// formatArgs 把查询参数格式化为 ["a", "b", 100] 的形式
+func formatArgs(args []interface{}) string {
+	parts := make([]string, len(args))
+	for i, a := range args {
+		if s, ok := a.(string); ok {
+			parts[i] = fmt.Sprintf("%q", s)
+		} else {
+			parts[i] = fmt.Sprint(a)
+		}
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
@@ -1,40 +1,100 @@
 package main
 
 import (
-	"compress/gzip"
 	"database/sql"
+	"errors"
+	"flag"
 	"fmt"
-	"io"
 	"math/rand"
+	"net/url"
 	"os"
+	"regexp"
+	"runtime"
 	"strings"
 	"sync"
-	"sync/atomic"
 	"time"
 	"unicode"
+	"unicode/utf8"
 
-	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
+	"golang.org/x/term"
 	_ "modernc.org/sqlite"
 )
 
+// initialHistoryCount 搜索框为空时列表中显示的最近历史条数
+const initialHistoryCount = 20
+
+// historyDedupeWindow 同一个单词在此时间内重复记入历史时会被忽略
+const historyDedupeWindow = 10 * time.Second
+
 var (
 	englishDB      *sql.DB
 	chineseDB      *sql.DB
 	app            *tview.Application
 	searchHistory  []string   // 搜索历史
 	historyMutex   sync.Mutex // 保护搜索历史的并发访问
-	maxHistorySize = 20       // 最多保存20条历史
+	maxHistorySize = 1000     // 最多保存的历史条数
 	searchVersion  int64      // 搜索版本号，用于防止旧搜索结果覆盖新搜索结果
+	searchLimit    = 100      // 每次搜索最多返回的结果数
+
+	containsMinLength = 3 // 英文查询少于这么多个字符时不做包含匹配，0 表示总是做
+
+	lastHistoryWord string    // 最近一次记入历史的单词
+	lastHistoryTime time.Time // 最近一次记入历史的时间
+
+	hasChineseCharIndex bool // 中文数据库是否包含汉字倒排索引（旧版本数据库没有）
+	hasExamples         bool // 英文数据库是否导入了例句
+	hasPinyin           bool // 中文数据库是否包含拼音
+	hasSources          bool // 英文数据库是否记录了词典来源（旧版本数据库没有）
+	multipleSources     bool // 英文数据库是否合并了多个词典，只有一个词典时不显示来源
+	hasTags             bool // 英文数据库是否保存了考试标签（旧版本数据库没有）
+	hasSplitPhonetics   bool // 英文数据库是否有单独的美式、英式音标列（旧版本数据库没有）
 )
 
 // Word 表示一个单词的完整信息
 type Word struct {
 	Word        string
 	Phonetic    string
+	PhoneticUS  string // 美式音标，词典没有单独提供时为空
+	PhoneticUK  string // 英式音标
 	Definition  string
 	Translation string
 	Bnc         string
+	Source      string // 释义所属的词典，如 ECDICT
+}
+
+// rowScanner *sql.Row 和 *sql.Rows 共有的 Scan 方法
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanWord 按 word, phonetic, definition, translation, bnc 的顺序读取一行，
+// withSplitPhonetics 为 true 时接着是 phonetic_us, phonetic_uk，withSource 为 true 时最后还有来源列
+//
+// 其他工具生成的数据库中可选的列可能是 NULL，这里按空字符串处理，而不是让整个查询出错
+func scanWord(row rowScanner, withSplitPhonetics, withSource bool) (Word, error) {
+	var word string
+	var phonetic, definition, translation, bnc, phoneticUS, phoneticUK, source sql.NullString
+	dest := []interface{}{&word, &phonetic, &definition, &translation, &bnc}
+	if withSplitPhonetics {
+		dest = append(dest, &phoneticUS, &phoneticUK)
+	}
+	if withSource {
+		dest = append(dest, &source)
+	}
+	if err := row.Scan(dest...); err != nil {
+		return Word{}, err
+	}
+	return Word{
+		Word:        word,
+		Phonetic:    phonetic.String,
+		PhoneticUS:  phoneticUS.String,
+		PhoneticUK:  phoneticUK.String,
+		Definition:  definition.String,
+		Translation: translation.String,
+		Bnc:         bnc.String,
+		Source:      source.String,
+	}, nil
 }
 
 // cleanNewlines 清除字符串中的换行符
@@ -49,406 +109,428 @@ func init() {
 	rand.Seed(time.Now().UnixNano())
 }
 
-// decompressGzipFile 解压缩gzip文件
-func decompressGzipFile(gzFile, outputFile string) error {
-	// 打开.gz文件
-	f, err := os.Open(gzFile)
+func main() {
+	// 读取配置文件，配置项作为命令行参数的默认值
+	var err error
+	config, err = loadConfig(configFile)
 	if err != nil {
-		return fmt.Errorf("无法打开文件 %s: %v", gzFile, err)
+		consolePrintf("❌ %v\n", err)
+		return
 	}
-	defer f.Close()
-
-	// 创建gzip.Reader
-	gr, err := gzip.NewReader(f)
-	if err != nil {
-		return fmt.Errorf("无法创建gzip.Reader: %v", err)
+	if config.DetailSections != nil {
+		detailSections = config.DetailSections
+	}
+	// 以下三项已在 loadConfig 中检查过
+	keyBindings, _ = config.Keymap.bindings()
+	enterAction, _ = config.Keymap.enterBehavior()
+	currentLogLevel, _ = parseLogLevel(config.LogLevel)
+	queryTimeout = time.Duration(config.QueryTimeout) * time.Second
+	bncDisplay = config.BNCDisplay
+	if err := loadUserWords(config.UserWords); err != nil {
+		consolePrintf("⚠️  %v\n", err)
 	}
-	defer gr.Close()
 
-	// 创建输出文件
-	out, err := os.Create(outputFile)
-	if err != nil {
-		return fmt.Errorf("无法创建文件 %s: %v", outputFile, err)
+	// dict lookup/build/serve/export 等子命令各自解析参数，不启动交互界面
+	if runSubcommand(os.Args[1:]) {
+		return
 	}
-	defer out.Close()
 
-	// 解压缩并写入
-	if _, err := io.Copy(out, gr); err != nil {
-		return fmt.Errorf("解压缩失败: %v", err)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "用法: dict [选项]\n\n选项:\n")
+		flag.PrintDefaults()
+		printSubcommandUsage()
+	}
+	flag.BoolVar(&plainOutput, "plain", config.Plain, "只输出 ASCII 符号，不显示 emoji 和方块进度条")
+	flag.BoolVar(&plainOutput, "no-emoji", config.Plain, "同 -plain")
+	flag.BoolVar(&vimMode, "vim", config.Vim, "启用 vim 风格按键：j/k 移动、gg/G 跳到首尾、/ 聚焦搜索框")
+	flag.BoolVar(&accessibleMode, "accessible", config.Accessible, "无障碍模式：界面只用黑白两色、不靠颜色传达信息，单词列表的选中行前显示 >")
+	personal := flag.Bool("personal-ranking", config.PersonalRanking, "按查阅次数调整搜索结果排序（"+keyLabel(actionPersonalRanking)+" 可临时切换）")
+	flag.IntVar(&searchLimit, "limit", config.Limit, "每次搜索最多返回的结果数")
+	flag.IntVar(&maxHistorySize, "history-size", config.HistorySize, "最多保存的搜索历史条数")
+	flag.StringVar(&csvEncoding, "encoding", config.Encoding, "词典 CSV 的字符编码：auto、utf-8、gbk、gb18030、big5")
+	flag.BoolVar(&lowPowerMode, "low-power", config.LowPower, "首次运行生成数据库时使用低功耗模式：单协程写入并限制为单核，较慢但发热更低")
+	flag.BoolVar(&vacuumAfterBuild, "vacuum", config.Vacuum, "首次运行生成数据库后执行 VACUUM 缩小数据库文件")
+	flag.StringVar(&profileName, "profile", config.Profile, "用户档案名，不同档案分别保存历史记录等学习数据")
+	flag.Var(&currentLogLevel, "log-level", "诊断日志的`级别`：debug（含每次查询的 SQL 和耗时）、info、warn、error")
+	debug := flag.Bool("debug", false, "同 -log-level debug")
+	logFile := flag.String("log-file", "debug.log", "诊断日志文件（界面占用终端，日志不能输出到屏幕），有日志时才创建")
+	flag.StringVar(logFile, "debug-log", "debug.log", "同 -log-file")
+	families := flag.Bool("group-families", config.GroupFamilies, "把同一词族的单词（如 nation、national、nationality）集中显示在词根之下（"+keyLabel(actionFamilies)+" 可临时切换）")
+	flag.BoolVar(&crossLanguageFallback, "cross-language", config.CrossLanguageFallback, "搜索没有结果时到另一种语言的释义中查找")
+	flag.BoolVar(&dualSearch, "dual", config.DualSearch, "每次搜索同时查找两种语言：本语言的匹配和另一种语言中释义提到它的词条")
+	flag.BoolVar(&combinedSearch, "combined", config.CombinedSearch, "一次搜索同时匹配英文单词和中文释义：如 app 苹果 查找以 app 开头且释义含有「苹果」的单词")
+	flag.BoolVar(&chineseSubphrases, "subphrases", config.ChineseSubphrases, "中文搜索还列出词库中被查询包含的词，如 人工智能 → 人工、智能，排在精确匹配之后、含有查询的词之前")
+	flag.IntVar(&containsMinLength, "contains-min", config.ContainsMinLength, "英文查询至少多少个字符时才做包含匹配，更短的查询只列出精确和前缀匹配（0 表示不限）")
+	exportFile := flag.String("export-progress", "", "把当前档案的搜索历史、收藏和查阅次数导出到该 JSON 文件后退出")
+	importFile := flag.String("import-progress", "", "从 -export-progress 导出的文件导入学习进度，与当前档案的数据合并后退出")
+	flag.StringVar(&openWord, "open", "", "启动后直接搜索该单词，选中第一个结果并显示详情")
+	replMode := flag.Bool("repl", false, "不启动交互界面，改为逐行输入单词、输出纯文本的查询结果（:q 退出），适合界面显示不正常的 SSH 会话")
+	importList := flag.String("import-list", "", "把每行一个单词的文本文件导入为学习列表，列出词典中没有的单词后退出")
+	listName := flag.String("list-name", "", "与 -import-list 一起使用，学习列表的名称（默认为不带扩展名的文件名）")
+	flag.StringVar(&studyListName, "study", "", "启动后直接开始只使用该学习列表中单词的测验")
+	diffFile := flag.String("diff", "", "比较两个版本的词典 CSV：-diff 旧文件 新文件，输出新增、删除和释义有变化的单词后退出")
+	diffList := flag.Bool("diff-list", false, "与 -diff 一起使用，按词频列出全部变化而不只是摘要")
+	top := flag.Int("top", 0, "按查阅次数列出查得最多的 N 个单词后退出")
+	rankRange := flag.String("rank", "", "按 BNC 排名顺序列出排名在该范围内的单词（如 2000-3000）后退出")
+	selfTest := flag.Bool("selftest", false, "检查已生成的数据库能否正常查询（精确匹配、中文释义、反查和随机推荐），有检查未通过时以非零状态退出")
+	healthCheck := flag.Bool("check", false, "检查两个数据库能否打开和查询，以 JSON 输出词条数、schema 版本和整体状态（ok 或 degraded），不是 ok 时以非零状态退出")
+	flag.Parse()
+	// -diff 的第二个文件是位置参数，之后的选项（如 -diff-list）需要再解析一次
+	var diffNew string
+	if *diffFile != "" && flag.NArg() > 0 {
+		diffNew = flag.Arg(0)
+		flag.CommandLine.Parse(flag.Args()[1:])
 	}
+	personalRanking.Store(*personal)
+	groupFamilies.Store(*families)
 
-	return nil
-}
+	if searchLimit <= 0 {
+		consolePrintln("❌ -limit 必须大于 0")
+		return
+	}
+	openWord = normalizeQuery(openWord)
+	if containsMinLength < 0 {
+		consolePrintln("❌ -contains-min 不能小于 0")
+		return
+	}
+	if maxHistorySize <= 0 {
+		consolePrintln("❌ -history-size 必须大于 0")
+		return
+	}
+	if err := validateEncoding(csvEncoding); err != nil {
+		consolePrintf("❌ %v\n", err)
+		return
+	}
+	if err := validateProfileName(profileName); err != nil {
+		consolePrintf("❌ %v\n", err)
+		return
+	}
 
-func main() {
-	// 检查并初始化数据库
-	csvFile := "ecdict.csv"
-	gzFile := "ecdict.csv.gz"
-	englishDBFile := "english_chinese.db"
-	chineseDBFile := "chinese_english.db"
+	if *debug {
+		currentLogLevel = levelDebug
+	}
+	closeLog, err := setLogFile(*logFile)
+	if err != nil {
+		consolePrintf("❌ %v\n", err)
+		return
+	}
+	defer closeLog()
 
-	// 检查数据库文件是否存在
-	_, errEnglish := os.Stat(englishDBFile)
-	_, errChinese := os.Stat(chineseDBFile)
+	// 输出不是终端（例如重定向到日志文件）时自动使用纯文本
+	if !term.IsTerminal(int(os.Stdout.Fd())) {
+		plainOutput = true
+	}
 
-	if os.IsNotExist(errEnglish) || os.IsNotExist(errChinese) {
-		fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-		fmt.Println("  📚 欢迎使用中英文词典")
-		fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-		fmt.Println()
-		fmt.Println("✨ 检测到这是首次运行，需要初始化数据库")
-		fmt.Println("⏱️  预计需要 1-2 分钟，请耐心等待...")
-		fmt.Println("💡 此操作仅需执行一次，后续启动将秒开！")
-		fmt.Println()
-
-		// 检查CSV文件是否存在
-		if _, err := os.Stat(csvFile); os.IsNotExist(err) {
-			// CSV文件不存在，检查.gz文件
-			if _, err := os.Stat(gzFile); os.IsNotExist(err) {
-				fmt.Printf("❌ 错误: 找不到 %s 或 %s 文件\n", csvFile, gzFile)
-				return
-			}
+	// 导出和导入学习进度只涉及用户数据，不需要数据库
+	if *exportFile != "" || *importFile != "" {
+		if err := transferProgress(*exportFile, *importFile); err != nil {
+			consolePrintf("❌ %v\n", err)
+		}
+		return
+	}
 
-			// 解压缩.gz文件
-			fmt.Println("📦 步骤 1/3: 解压缩词典数据...")
-			if err := decompressGzipFile(gzFile, csvFile); err != nil {
-				fmt.Printf("❌ 解压缩失败: %v\n", err)
-				return
-			}
-			fmt.Println("✅ 解压缩完成")
-			fmt.Println()
+	if *top > 0 {
+		if err := runTop(*top); err != nil {
+			consolePrintf("❌ %v\n", err)
 		}
+		return
+	}
 
-		// 生成数据库
-		fmt.Println("🔨 步骤 2/3: 生成数据库文件...")
-		fmt.Println()
-		if err := RunConverter(csvFile); err != nil {
-			fmt.Printf("❌ 生成数据库失败: %v\n", err)
-			return
+	if *rankRange != "" {
+		if err := runRankRange(*rankRange); err != nil {
+			consolePrintf("❌ %v\n", err)
 		}
-		fmt.Println()
-		fmt.Println("✅ 步骤 3/3: 数据库初始化完成！")
-		fmt.Println()
-		fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-		fmt.Println("  🎉 初始化成功！正在启动词典...")
-		fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-		fmt.Println()
-	} else {
-		fmt.Println("✅ 数据库已就绪，正在启动...")
+		return
 	}
 
-	// 初始化数据库
-	var err error
-	englishDB, err = sql.Open("sqlite", englishDBFile)
-	if err != nil {
-		fmt.Printf("无法打开英文数据库: %v\n", err)
+	if *importList != "" {
+		if err := runImportStudyList(*importList, *listName); err != nil {
+			consolePrintf("❌ %v\n", err)
+		}
 		return
 	}
-	defer englishDB.Close()
 
-	chineseDB, err = sql.Open("sqlite", chineseDBFile)
-	if err != nil {
-		fmt.Printf("无法打开中文数据库: %v\n", err)
+	// 比较词典版本只读取 CSV，不需要数据库
+	if *diffFile != "" {
+		if diffNew == "" {
+			consolePrintln("❌ -diff 需要两个文件：-diff 旧文件 新文件")
+			return
+		}
+		if err := runDiff(*diffFile, diffNew, *diffList); err != nil {
+			consolePrintf("❌ %v\n", err)
+		}
 		return
 	}
-	defer chineseDB.Close()
-
-	// 创建应用
-	app = tview.NewApplication()
-
-	// 用于存储搜索结果（需要在创建UI组件之前声明）
-	var searchResults []string
-	var searchMutex sync.Mutex // 保护搜索结果的并发访问
-	var inputTimer *time.Timer // 输入后的自动切换定时器
-
-	// 创建UI组件
-	searchInput := tview.NewInputField().
-		SetLabel("搜索: ").
-		SetFieldWidth(0).
-		SetPlaceholder("输入中文或英文...")
-
-	wordList := tview.NewList().
-		ShowSecondaryText(false).
-		SetWrapAround(false). // 禁止循环滚动
-		SetMainTextColor(tcell.ColorWhite).
-		SetSelectedTextColor(tcell.ColorBlack).
-		SetSelectedBackgroundColor(tcell.ColorYellow)
-
-	detailView := tview.NewTextView().
-		SetDynamicColors(true).
-		SetScrollable(true).
-		SetWordWrap(true)
-	detailView.SetBorder(true).SetTitle("详细信息")
-
-	// 需求2: 监听列表选择变化，按上下键时立即显示详情
-	wordList.SetChangedFunc(func(index int, mainText string, secondaryText string, shortcut rune) {
-		// 只有当焦点在列表上时才响应
-		if app.GetFocus() == wordList && index >= 0 {
-			searchMutex.Lock()
-			if index < len(searchResults) {
-				selectedWord := searchResults[index]
-				searchMutex.Unlock()
-
-				// 异步加载详细信息（不添加到历史记录）
-				go func(sw string) {
-					var detail string
-					if isChinese(sw) {
-						detail = showChineseDetail(sw)
-					} else {
-						detail = showEnglishDetail(sw)
-					}
-
-					app.QueueUpdateDraw(func() {
-						detailView.SetText(detail)
-					})
-				}(selectedWord)
-			} else {
-				searchMutex.Unlock()
-			}
+
+	if *selfTest {
+		if err := runSelfTest(); err != nil {
+			consolePrintf("❌ %v\n", err)
+			closeLog()
+			os.Exit(1)
 		}
-	})
+		return
+	}
 
-	// 显示初始单词列表的函数
-	showInitialWords := func() {
-		go func() {
-			var results []string
-			history := getSearchHistory()
-
-			if len(history) == 0 {
-				// 没有历史记录，显示随机单词
-				results = getRandomWords(20)
-			} else {
-				// 显示搜索历史
-				results = history
-			}
+	if *healthCheck {
+		ok := runHealthCheck()
+		closeLog()
+		if !ok {
+			os.Exit(1)
+		}
+		return
+	}
 
-			searchMutex.Lock()
-			searchResults = results
-			searchMutex.Unlock()
-
-			app.QueueUpdateDraw(func() {
-				wordList.Clear()
-				for i, word := range results {
-					index := i
-					// 不使用颜色标记，避免影响选中颜色
-					displayText := word
-					if len(history) > 0 && i < len(history) {
-						displayText = "★ " + word // 使用星号标记历史
-					}
-					wordList.AddItem(displayText, "", 0, func() {
-						searchMutex.Lock()
-						selectedWord := searchResults[index]
-						searchMutex.Unlock()
-
-						// 点击时不添加到历史记录，避免卡顿
-						// 只在查询详情
-						go func(sw string) {
-							var detail string
-							if isChinese(sw) {
-								detail = showChineseDetail(sw)
-							} else {
-								detail = showEnglishDetail(sw)
-							}
-
-							app.QueueUpdateDraw(func() {
-								detailView.SetText(detail)
-							})
-						}(selectedWord)
-					})
-				}
-			})
-		}()
+	// 没有可用的终端时不启动界面：指定了 --open 就像 dict lookup 一样输出结果，否则说明原因
+	interactive, reason := interactiveTerminal()
+	if !interactive && openWord == "" && !*replMode {
+		consolePrintf("❌ 交互界面需要在终端中运行（%s）\n", reason)
+		consolePrintln("   查询单词可以使用 ./dict lookup <单词>，或 ./dict --open <单词> 在非终端环境中直接输出结果")
+		return
 	}
 
-	// 搜索功能（异步查询）
-	searchInput.SetChangedFunc(func(text string) {
-		searchText := strings.TrimSpace(text)
-		wordList.Clear()
-		detailView.Clear()
+	// 检查并初始化数据库
+	if err := ensureDatabases(); err != nil {
+		consolePrintf("❌ %v\n", err)
+		return
+	}
+	if err := openDatabases(); err != nil {
+		consolePrintf("❌ %v\n", err)
+		return
+	}
+	defer closeDatabases()
 
-		// 取消之前的定时器
-		if inputTimer != nil {
-			inputTimer.Stop()
-			inputTimer = nil
+	if *replMode {
+		if err := runREPL(os.Stdin); err != nil {
+			consolePrintf("❌ 读取输入失败: %v\n", err)
 		}
+		return
+	}
+	if !interactive {
+		if err := printLookup(openWord); err != nil {
+			consolePrintf("❌ %v\n", err)
+		}
+		return
+	}
 
-		// 每次输入都递增版本号
-		currentVersion := atomic.AddInt64(&searchVersion, 1)
-
-		if searchText == "" {
-			searchMutex.Lock()
-			searchResults = []string{}
-			searchMutex.Unlock()
-			// 显示初始单词列表（历史或随机）
-			showInitialWords()
+	// 读取搜索历史等用户数据
+	if err := loadState(); err != nil {
+		consolePrintf("⚠️  读取用户数据失败: %v\n", err)
+	}
+	if studyListName != "" {
+		if err := checkStudyList(studyListName); err != nil {
+			consolePrintf("❌ %v\n", err)
 			return
 		}
+	}
 
-		// 在新的 goroutine 中异步查询
-		go func(query string, version int64) {
-			var results []string
+	// 定时在后台保存用户数据，防止程序崩溃时丢失
+	stopAutoSave := startAutoSave(time.Duration(config.AutoSaveInterval)*time.Second, func(err error) {
+		logErrorf("自动保存用户数据失败: %v", err)
+		app.QueueUpdateDraw(func() {
+			showError(fmt.Errorf("保存用户数据失败: %v", err))
+		})
+	})
 
-			// 判断是中文还是英文
-			if isChinese(query) {
-				results = searchChinese(query)
-			} else {
-				results = searchEnglish(query)
-			}
+	// 另一个进程重新生成数据库后自动切换到新数据
+	stopWatch := watchDatabaseRebuild(dbWatchInterval, onRebuildStarted, onRebuildFinished)
+
+	// 运行应用
+	runErr := runTUI()
 
-			// 检查是否是最新版本，如果不是则放弃更新
-			if atomic.LoadInt64(&searchVersion) != version {
-				return
+	// 退出前停止定时保存并做最后一次保存
+	stopWatch()
+	stopAutoSave()
+	if err := saveState(); err != nil {
+		consolePrintf("❌ 保存用户数据失败: %v\n", err)
+	}
+
+	if runErr != nil {
+		// 终端无法初始化（例如没有 /dev/tty 或终端类型不受支持）
+		consolePrintf("❌ 无法启动交互界面（TERM=%s）: %v\n", os.Getenv("TERM"), runErr)
+		if openWord != "" {
+			if err := printLookup(openWord); err != nil {
+				consolePrintf("❌ %v\n", err)
 			}
+		} else {
+			consolePrintln("   查询单词可以使用 ./dict lookup <单词>")
+		}
+		return
+	}
+	stats.printSummary()
+}
 
-			// 更新搜索结果
-			searchMutex.Lock()
-			searchResults = results
-			searchMutex.Unlock()
+// 词典数据文件，数据库不存在时从这里生成
+const (
+	dictCSVFile = "ecdict.csv"
+	dictGzFile  = "ecdict.csv.gz"
+)
 
-			// 在主线程中更新UI
-			app.QueueUpdateDraw(func() {
-				// 再次检查版本，确保UI更新时也是最新的
-				if atomic.LoadInt64(&searchVersion) != version {
-					return
-				}
+// ensureDatabases 检查数据库文件，首次运行时从词典数据生成
+func ensureDatabases() error {
+	_, errEnglish := os.Stat(englishDBFile)
+	_, errChinese := os.Stat(chineseDBFile)
 
-				wordList.Clear()
-				for i, word := range results {
-					index := i // 捕获循环变量
-					wordList.AddItem(word, "", 0, func() {
-						searchMutex.Lock()
-						selectedWord := searchResults[index]
-						searchMutex.Unlock()
-
-						// 添加到历史记录
-						addToHistory(selectedWord)
-
-						// 异步加载详细信息
-						go func(sw string) {
-							var detail string
-							if isChinese(sw) {
-								detail = showChineseDetail(sw)
-							} else {
-								detail = showEnglishDetail(sw)
-							}
-
-							// 在主线程中更新详细信息
-							app.QueueUpdateDraw(func() {
-								detailView.SetText(detail)
-							})
-						}(selectedWord)
-					})
-				}
+	if !os.IsNotExist(errEnglish) && !os.IsNotExist(errChinese) {
+		if err := upgradeDatabases(); err != nil {
+			return err
+		}
+		consolePrintln("✅ 数据库已就绪，正在启动...")
+		return nil
+	}
 
-				// 需求1: 如果有搜索结果，自动选中第一项并显示详情
-				if len(results) > 0 {
-					wordList.SetCurrentItem(0)
-					firstWord := results[0]
-
-					// 异步加载第一个词的详细信息
-					go func(sw string) {
-						var detail string
-						if isChinese(sw) {
-							detail = showChineseDetail(sw)
-						} else {
-							detail = showEnglishDetail(sw)
-						}
-
-						app.QueueUpdateDraw(func() {
-							detailView.SetText(detail)
-						})
-					}(firstWord)
-				}
-			})
-		}(searchText, currentVersion)
-
-		// 新需求: 5秒后自动将焦点切换到单词列表，并将搜索词添加到历史
-		// 重要：这个定时器在每次输入时都会被重置，只有停止输入5秒后才会触发
-		inputTimer = time.AfterFunc(5*time.Second, func() {
-			app.QueueUpdateDraw(func() {
-				if app.GetFocus() == searchInput && searchInput.GetText() != "" {
-					// 添加当前选中的词到历史记录
-					searchMutex.Lock()
-					if len(searchResults) > 0 {
-						currentIndex := wordList.GetCurrentItem()
-						if currentIndex >= 0 && currentIndex < len(searchResults) {
-							addToHistory(searchResults[currentIndex])
-						}
-					}
-					searchMutex.Unlock()
-
-					// 切换焦点到单词列表
-					app.SetFocus(wordList)
-				}
-			})
-		})
-	})
+	consolePrintln("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	consolePrintln("  📚 欢迎使用中英文词典")
+	consolePrintln("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	consolePrintln()
+	consolePrintln("✨ 检测到这是首次运行，需要初始化数据库")
+	consolePrintln("⏱️  预计需要 1-2 分钟，请耐心等待...")
+	consolePrintln("💡 此操作仅需执行一次，后续启动将秒开！")
+	consolePrintln()
+
+	if err := buildDatabases(dictCSVFile); err != nil {
+		return err
+	}
+
+	consolePrintln("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	consolePrintln("  🎉 初始化成功！正在启动词典...")
+	consolePrintln("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	consolePrintln()
+	return nil
+}
+
+// resolveDictCSV 返回实际要读取的词典文件：csvFile 存在时就是它，否则为同名的 .gz 文件
+func resolveDictCSV(csvFile string) (string, error) {
+	if _, err := os.Stat(csvFile); !os.IsNotExist(err) {
+		return csvFile, nil
+	}
+	gzFile := csvFile + ".gz"
+	if _, err := os.Stat(gzFile); os.IsNotExist(err) {
+		return "", fmt.Errorf("错误: 找不到 %s 或 %s 文件", csvFile, gzFile)
+	}
+	return gzFile, nil
+}
 
-	// 设置输入框快捷键
-	searchInput.SetDoneFunc(func(key tcell.Key) {
-		if key == tcell.KeyEnter || key == tcell.KeyDown {
-			app.SetFocus(wordList)
+// buildDatabases 从词典 CSV 生成两个数据库，CSV 不存在时直接读取同名的 .gz 文件，边读边解压
+func buildDatabases(csvFile string) error {
+	file, err := resolveDictCSV(csvFile)
+	if err != nil && csvFile == dictCSVFile && config.DictURL != "" {
+		// 默认的词典数据不存在时按配置下载压缩包
+		if err = downloadDict(dictGzFile); err == nil {
+			file = dictGzFile
 		}
-	})
+	}
+	if err != nil {
+		return err
+	}
+	if file != csvFile {
+		consolePrintf("📦 步骤 1/3: 直接读取压缩的词典数据 %s，不解压到磁盘\n", file)
+		consolePrintln()
+		csvFile = file
+	}
 
-	// 左侧面板（搜索框和列表）
-	leftPanel := tview.NewFlex().
-		SetDirection(tview.FlexRow).
-		AddItem(searchInput, 1, 0, true).
-		AddItem(wordList, 0, 1, false)
-	leftPanel.SetBorder(true).SetTitle("单词列表")
-
-	// 主布局（左右分栏）
-	mainLayout := tview.NewFlex().
-		AddItem(leftPanel, 0, 3, true).
-		AddItem(detailView, 0, 7, false)
-
-	// 设置全局快捷键
-	mainLayout.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		if event.Key() == tcell.KeyEsc {
-			app.Stop()
-		} else if event.Key() == tcell.KeyTab {
-			// Tab切换焦点
-			if app.GetFocus() == searchInput {
-				app.SetFocus(wordList)
-			} else if app.GetFocus() == wordList {
-				app.SetFocus(detailView)
-			} else {
-				app.SetFocus(searchInput)
-			}
-			return nil
-		} else if event.Key() == tcell.KeyUp || event.Key() == tcell.KeyDown {
-			// 新需求: 无论焦点在哪里，按上下键时切换到单词列表
-			if app.GetFocus() != wordList {
-				app.SetFocus(wordList)
-				// 让单词列表处理这个按键事件
-				return event
-			}
-			return event
-		} else if event.Rune() != 0 && app.GetFocus() != searchInput {
-			// 需求3: 当焦点不在搜索框时，敲击键盘自动切换到搜索框并清空内容
-			searchInput.SetText("")
-			app.SetFocus(searchInput)
-			// 让搜索框处理这个字符
-			return event
-		}
-		return event
-	})
+	// 生成数据库
+	consolePrintln("🔨 步骤 2/3: 生成数据库文件...")
+	consolePrintln()
+	if err := RunConverter(csvFile); err != nil {
+		return fmt.Errorf("生成数据库失败: %v", err)
+	}
+	consolePrintln()
+	consolePrintln("✅ 步骤 3/3: 数据库初始化完成！")
+	consolePrintln()
+	return nil
+}
 
-	// 初始显示随机单词或历史记录
-	showInitialWords()
+// openDatabases 打开两个数据库文件并检测可用的功能，使用完后调用 closeDatabases
+func openDatabases() error {
+	english, err := openReadDB(englishDBFile)
+	if err != nil {
+		return fmt.Errorf("无法打开英文数据库: %v", err)
+	}
+	chinese, err := openReadDB(chineseDBFile)
+	if err != nil {
+		english.Close()
+		return fmt.Errorf("无法打开中文数据库: %v", err)
+	}
+	useDatabases(english, chinese)
+	logInfof("已打开数据库 %s、%s", englishDBFile, chineseDBFile)
+	return nil
+}
 
-	// 运行应用
-	if err := app.SetRoot(mainLayout, true).EnableMouse(true).Run(); err != nil {
-		panic(err)
+// readPoolSize 运行时每个数据库连接池的大小
+//
+// 快速滚动列表时会同时发起多个详情查询，默认只保留 2 个空闲连接，多出的连接用完就关闭、下次再重新打开；
+// 空闲连接数与最大连接数相同，连接打开后一直复用
+func readPoolSize() int {
+	if n := runtime.NumCPU(); n > 4 {
+		return n
+	}
+	return 4
+}
+
+// openReadDB 以只读方式打开运行时查询用的数据库，与 converter 中单连接写入的设置不同：
+// 连接池允许多个查询并行；mode=ro 让 SQLite 拒绝一切写入，也不会创建日志文件，词典数据可以放在只读介质上。
+// 用户数据保存在 userdata 目录中，不写入这两个数据库
+//
+// 没有使用 cache=shared：共享缓存的连接之间要争用表锁，只读查询并不会因此更快（见 BenchmarkParallelSearch）
+func openReadDB(file string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", readOnlyDSN(file))
+	if err != nil {
+		return nil, err
+	}
+	// sql.Open 不会真正打开文件，这里先连接一次，文件不存在或无法读取时立即报错
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	size := readPoolSize()
+	db.SetMaxOpenConns(size)
+	db.SetMaxIdleConns(size)
+	return db, nil
+}
+
+// readOnlyDSN 返回以只读方式打开 file 的 SQLite URI
+//
+// 文件本身不可写（只读介质或没有写权限）时再加上 immutable=1：此时没有进程能修改它，
+// SQLite 不必加锁和检查文件变化；文件可写时保留加锁，万一有其他程序直接修改文件也不会读到不完整的数据。
+// dict build 总是生成新文件后重命名替换，已打开的连接继续读旧文件，不受 immutable 影响
+func readOnlyDSN(file string) string {
+	query := "mode=ro"
+	if f, err := os.OpenFile(file, os.O_WRONLY, 0); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			query += "&immutable=1"
+		}
+	} else {
+		f.Close()
 	}
+	// 路径中的 ?、# 和 % 等字符需要转义，否则会被当作 URI 的一部分
+	return "file:" + (&url.URL{Path: file}).EscapedPath() + "?" + query
 }
 
-// 添加到搜索历史
+// closeDatabases 关闭 openDatabases 打开的数据库
+func closeDatabases() {
+	englishDB.Close()
+	chineseDB.Close()
+}
+
+// addToHistory 把有意查询的单词加入搜索历史（需在主线程中调用）
+//
+// 只有这几处调用：在列表中按 Enter 或点击、打开详情中的链接、输入后停留 5 秒。
+// 用方向键等在列表中移动时（listChanging）只显示详情，即使经由其他代码调用到这里也不记录
 func addToHistory(word string) {
+	if listChanging {
+		return
+	}
+
 	historyMutex.Lock()
 	defer historyMutex.Unlock()
 
+	// 点击和自动聚焦定时器可能在短时间内记录同一个单词，只算一次
+	if word == lastHistoryWord && time.Since(lastHistoryTime) < historyDedupeWindow {
+		return
+	}
+	lastHistoryWord = word
+	lastHistoryTime = time.Now()
+
 	// 如果已存在，先移除
 	for i, w := range searchHistory {
 		if w == word {
@@ -464,15 +546,75 @@ func addToHistory(word string) {
 	if len(searchHistory) > maxHistorySize {
 		searchHistory = searchHistory[:maxHistorySize]
 	}
+	markStateDirty()
+	stats.recordSearch(word, getLookupCount(word) > 0)
+	recordLookup(word)
+}
+
+// 初始界面随机推荐的单词的 BNC 词频排名范围
+const (
+	randomMinBNC = 1
+	randomMaxBNC = 999
+)
+
+// randomRecommendOptions 返回初始界面随机推荐使用的抽取条件
+func randomRecommendOptions() []RandomOption {
+	opts := []RandomOption{WithBNCRange(randomMinBNC, randomMaxBNC)}
+	if config.HideProperNouns {
+		opts = append(opts, WithoutProperNouns())
+	}
+	return opts
 }
 
 // 获取随机单词（bnc > 0 且 < 1000）
-func getRandomWords(count int) []string {
-	query := `SELECT word FROM words WHERE CAST(bnc AS INTEGER) > 0 AND CAST(bnc AS INTEGER) < 1000 ORDER BY RANDOM() LIMIT ?`
+func getRandomWords(count int) ([]string, error) {
+	// 多抽一倍，去掉 ignoreWords 排除的单词后仍能凑够数量
+	fetch := count
+	if len(config.IgnoreWords) > 0 {
+		fetch *= 2
+	}
+	words, err := RandomWords(fetch, randomRecommendOptions()...)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []string
+	for _, w := range words {
+		if len(results) == count {
+			break
+		}
+		if !isIgnored(w.Word) {
+			results = append(results, w.Word)
+		}
+	}
+	return results, nil
+}
+
+// browsePageSize 浏览模式每页显示的单词数
+const browsePageSize = 50
+
+// properNounSQL 判断专有名词和缩写的 SQL 条件：含大写字母且没有中文翻译，或全部为大写字母（如 NASA）
+const properNounSQL = `((word GLOB '*[A-Z]*' AND COALESCE(translation, '') = '') OR (word GLOB '[A-Z][A-Z]*' AND word NOT GLOB '*[^A-Z]*'))`
 
-	rows, err := englishDB.Query(query, count)
+// properNounFilter 返回排除专有名词和缩写的查询条件，hide 为 false 时返回空字符串
+func properNounFilter(hide bool) string {
+	if !hide {
+		return ""
+	}
+	return " AND NOT " + properNounSQL
+}
+
+// getBrowseWords 获取以 prefix 开头的单词，按BNC词频排序（无词频的排在最后），page 从 0 开始
+func getBrowseWords(prefix string, page int) ([]string, error) {
+	query := `SELECT word FROM words WHERE word LIKE ? ESCAPE '\'
+	          ORDER BY CASE WHEN CAST(bnc AS INTEGER) > 0 THEN CAST(bnc AS INTEGER) ELSE 1073741824 END, word
+	          LIMIT ? OFFSET ?`
+
+	ctx, cancel := queryContext()
+	defer cancel()
+	rows, err := englishDB.QueryContext(ctx, query, escapeLike(prefix)+"%", browsePageSize, page*browsePageSize)
 	if err != nil {
-		return []string{"查询出错: " + err.Error()}
+		return nil, timeoutError(ctx, err)
 	}
 	defer rows.Close()
 
@@ -484,7 +626,21 @@ func getRandomWords(count int) []string {
 		}
 		results = append(results, word)
 	}
-	return results
+	return results, timeoutError(ctx, rows.Err())
+}
+
+// removeFromHistory 从搜索历史中删除单词
+func removeFromHistory(word string) {
+	historyMutex.Lock()
+	defer historyMutex.Unlock()
+
+	for i, w := range searchHistory {
+		if w == word {
+			searchHistory = append(searchHistory[:i], searchHistory[i+1:]...)
+			markStateDirty()
+			return
+		}
+	}
 }
 
 // 获取搜索历史
@@ -507,217 +663,978 @@ func isChinese(text string) bool {
 	return false
 }
 
-func searchEnglish(keyword string) []string {
-	var results []string
+// MatchType 表示搜索结果的匹配方式
+type MatchType int
+
+const (
+	MatchExact              MatchType = iota // 精确匹配
+	MatchLemma                               // 去掉词尾后的原形匹配（如 dogs → dog）
+	MatchPrefix                              // 前缀匹配
+	MatchContains                            // 包含匹配
+	MatchPinyin                              // 拼音匹配
+	MatchCrossLanguage                       // 在另一种语言的释义中找到（跨语言回退或双向搜索）
+	MatchSpelling                            // 只差撇号、连字符或空格的其他写法（如 dont → don't）
+	MatchSuffix                              // 后缀匹配（通配符查询 *tion）
+	MatchRank                                // 按 BNC 排名范围列出（rank:2000-3000）
+	MatchWordAndTranslation                  // 单词以英文部分开头且中文释义含有中文部分（合并搜索 app 苹果）
+	MatchTranslation                         // 中文释义中含有查询的中文词（合并搜索）
+	MatchSubphrase                           // 词库中被中文查询包含的词（人工智能 → 智能）
+	MatchRootDerived                         // root: 查询中词根加词尾的派生词（structure）
+	MatchRootPrefixed                        // root: 查询中带前缀的同根词（construct、instruction）
+)
+
+// Label 返回匹配方式在结果列表中显示的分组标题
+func (m MatchType) Label() string {
+	switch m {
+	case MatchExact:
+		return "精确匹配"
+	case MatchLemma:
+		return "原形"
+	case MatchPrefix:
+		return "前缀匹配"
+	case MatchPinyin:
+		return "拼音匹配"
+	case MatchCrossLanguage:
+		return "释义中提到"
+	case MatchSpelling:
+		return "其他写法"
+	case MatchSuffix:
+		return "后缀匹配"
+	case MatchRank:
+		return "词频排名"
+	case MatchWordAndTranslation:
+		return "单词和释义"
+	case MatchTranslation:
+		return "释义匹配"
+	case MatchSubphrase:
+		return "查询中包含的词"
+	case MatchRootDerived:
+		return "派生词"
+	case MatchRootPrefixed:
+		return "带前缀的同根词"
+	default:
+		return "包含匹配"
+	}
+}
+
+// SearchResult 表示一条搜索结果及其匹配方式
+type SearchResult struct {
+	Word    string
+	Match   MatchType
+	Root    string // 按词族分组时归入的词根，为空表示不是派生词
+	User    bool   // 来自用户词表
+	Ignored bool   // 匹配 ignoreWords，但作为精确匹配仍然保留（keepExactMatch）
+	Senses  int    // 中文释义的义项数，只在开启 senseCounts 时由单词列表填入
+}
+
+// collectMatches 执行查询，把尚未出现过的结果以指定的匹配方式追加到 results
+func collectMatches(db *sql.DB, match MatchType, results []SearchResult, seen map[string]bool, query string, args ...interface{}) ([]SearchResult, error) {
+	start := time.Now()
+	ctx, cancel := queryContext()
+	defer cancel()
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return results, timeoutError(ctx, err)
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var word string
+		if err := rows.Scan(&word); err == nil && !seen[word] {
+			results = append(results, SearchResult{Word: word, Match: match})
+			seen[word] = true
+		}
+		count++
+	}
+	logQuery(match.Label(), start, count, query, args...)
+	return results, timeoutError(ctx, rows.Err())
+}
+
+// normalizeQuery 去除首尾空白并把词组内部连续的空白合并为一个空格，弯引号和各种破折号换成 ' 和 -
+func normalizeQuery(text string) string {
+	return strings.Join(strings.Fields(punctuationReplacer.Replace(text)), " ")
+}
+
+func searchEnglish(keyword string) ([]SearchResult, error) {
+	var results []SearchResult
+	var err error
 	seen := make(map[string]bool) // 用于去重
-	limit := 100
+	limit := searchLimit
 
-	// 1. 精确匹配
-	query := `SELECT word FROM words WHERE word = ? LIMIT ?`
-	rows, err := englishDB.Query(query, keyword, limit)
-	if err == nil {
-		for rows.Next() {
-			var word string
-			if err := rows.Scan(&word); err == nil && !seen[word] {
-				results = append(results, word)
-				seen[word] = true
+	// 词组（如 "give up"）按规范化后的形式与词库中存储的词组匹配
+	keyword = normalizeQuery(keyword)
+
+	// 开头或结尾带 * 的通配符查询只按 * 的位置匹配
+	if config.Wildcards {
+		if stem, leading, trailing, ok := parseWildcard(keyword); ok {
+			return searchWildcard(stem, leading, trailing)
+		}
+	}
+
+	// rank:2000-3000 按 BNC 排名顺序列出范围内的单词
+	if min, max, ok, err := parseRankQuery(keyword); ok {
+		if err != nil {
+			return nil, fmt.Errorf("rank: 查询的%v", err)
+		}
+		return searchRankRange(min, max)
+	}
+
+	// root:struct 列出由这个词根加上前缀、词尾构成的单词
+	if root, ok, err := parseRootQuery(keyword); ok {
+		if err != nil {
+			return nil, fmt.Errorf("root: 查询的%v", err)
+		}
+		return searchRoot(root)
+	}
+
+	// 1. 精确匹配（大小写完全一致的排在前面，其次是全小写形式）
+	if results, err = collectMatches(englishDB, MatchExact, results, seen,
+		`SELECT word FROM words WHERE word = ? LIMIT ?`, keyword, limit); err != nil {
+		return nil, err
+	}
+	if lower := strings.ToLower(keyword); lower != keyword {
+		if results, err = collectMatches(englishDB, MatchExact, results, seen,
+			`SELECT word FROM words WHERE word = ? LIMIT ?`, lower, limit-len(results)); err != nil {
+			return nil, err
+		}
+	}
+
+	// 2. 没有精确匹配时，尝试去掉常见词尾后查找原形（dogs → dog、stopped → stop）
+	if len(results) == 0 {
+		for _, lemma := range lemmaCandidates(strings.ToLower(keyword)) {
+			if results, err = collectMatches(englishDB, MatchLemma, results, seen,
+				`SELECT word FROM words WHERE word = ? LIMIT 1`, lemma); err != nil {
+				return nil, err
+			}
+			if len(results) > 0 {
+				break
+			}
+		}
+	}
+
+	// 3. 仍然没有结果时，尝试只差撇号、连字符或空格的写法（dont → don't、mother in law → mother-in-law）
+	if len(results) == 0 {
+		if variants := spellingVariants(keyword); len(variants) > 0 {
+			args := make([]interface{}, 0, len(variants)+1)
+			for _, v := range variants {
+				args = append(args, v)
+			}
+			if results, err = collectMatches(englishDB, MatchSpelling, results, seen,
+				`SELECT word FROM words WHERE word IN (?`+strings.Repeat(", ?", len(variants)-1)+`)
+				 ORDER BY CASE WHEN CAST(bnc AS INTEGER) > 0 THEN CAST(bnc AS INTEGER) ELSE 1073741824 END, word
+				 LIMIT ?`, append(args, limit)...); err != nil {
+				return nil, err
 			}
 		}
-		rows.Close()
 	}
 
 	// 如果已经达到限制，直接返回
 	if len(results) >= limit {
-		return results
+		return results, nil
 	}
 
-	// 2. 前缀匹配（排除已匹配的）
-	remaining := limit - len(results)
-	query = `SELECT word FROM words WHERE word LIKE ? AND word != ? LIMIT ?`
-	rows, err = englishDB.Query(query, keyword+"%", keyword, remaining)
-	if err == nil {
-		for rows.Next() {
-			var word string
-			if err := rows.Scan(&word); err == nil && !seen[word] {
-				results = append(results, word)
-				seen[word] = true
-			}
-		}
-		rows.Close()
+	// 4. 前缀匹配（排除已匹配的）
+	if results, err = collectMatches(englishDB, MatchPrefix, results, seen,
+		`SELECT word FROM words WHERE word LIKE ? ESCAPE '\' AND word != ?`+properNounFilter(config.HideProperNounsInSearch)+` LIMIT ?`,
+		escapeLike(keyword)+"%", keyword, limit-len(results)); err != nil {
+		return nil, err
 	}
 
 	// 如果已经达到限制，直接返回
 	if len(results) >= limit {
-		return results
+		return results, nil
 	}
 
-	// 3. 包含匹配（排除已匹配的）
-	remaining = limit - len(results)
-	query = `SELECT word FROM words WHERE word LIKE ? AND word NOT LIKE ? LIMIT ?`
-	rows, err = englishDB.Query(query, "%"+keyword+"%", keyword+"%", remaining)
-	if err == nil {
-		for rows.Next() {
-			var word string
-			if err := rows.Scan(&word); err == nil && !seen[word] {
-				results = append(results, word)
-				seen[word] = true
+	// 5. 包含匹配（排除已匹配的）
+	// 两个字母的查询几乎能包含在任何单词里，结果都是无关的词，此时只保留精确和前缀匹配
+	if utf8.RuneCountInString(keyword) < containsMinLength {
+		return results, nil
+	}
+	query, args := containsQuery(keyword, properNounFilter(config.HideProperNounsInSearch), limit-len(results))
+	if results, err = collectMatches(englishDB, MatchContains, results, seen, query, args...); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// lemmaSuffixes 常见英文词尾及还原时替换成的内容，按优先顺序排列
+var lemmaSuffixes = []struct {
+	suffix, replace string
+}{
+	{"ies", "y"}, // studies → study
+	{"es", ""},   // boxes → box
+	{"s", ""},    // dogs → dog
+	{"ied", "y"}, // studied → study
+	{"ed", ""},   // walked → walk
+	{"ed", "e"},  // liked → like
+	{"ing", ""},  // walking → walk
+	{"ing", "e"}, // making → make
+	{"ily", "y"}, // happily → happy
+	{"ly", ""},   // quickly → quick
+}
+
+// lemmaCandidates 返回去掉常见词尾后可能的原形，按可能性排列；
+// 同时处理重复辅音字母的情况（stopped → stop、running → run）
+func lemmaCandidates(word string) []string {
+	if len(word) < 4 || strings.ContainsAny(word, " -'") {
+		return nil
+	}
+
+	var candidates []string
+	seen := make(map[string]bool)
+	add := func(c string) {
+		if len(c) >= 2 && !seen[c] {
+			seen[c] = true
+			candidates = append(candidates, c)
+		}
+	}
+
+	for _, rule := range lemmaSuffixes {
+		if !strings.HasSuffix(word, rule.suffix) {
+			continue
+		}
+		stem := strings.TrimSuffix(word, rule.suffix)
+		add(stem + rule.replace)
+
+		// 重复的辅音字母：stopp → stop
+		if rule.replace == "" && (rule.suffix == "ed" || rule.suffix == "ing") {
+			if n := len(stem); n >= 3 && stem[n-1] == stem[n-2] && !strings.ContainsRune("aeiou", rune(stem[n-1])) {
+				add(stem[:n-1])
 			}
 		}
-		rows.Close()
 	}
+	return candidates
+}
 
-	return results
+// tableExists 检查数据库中是否存在指定的表
+func tableExists(db *sql.DB, name string) bool {
+	var n int
+	err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, name).Scan(&n)
+	return err == nil && n > 0
 }
 
-func searchChinese(keyword string) []string {
-	var results []string
+// useDatabases 设置搜索和详情查询使用的数据库，并检测数据库支持的可选功能
+func useDatabases(english, chinese *sql.DB) {
+	englishDB = english
+	chineseDB = chinese
+	hasChineseCharIndex = tableExists(chineseDB, "chinese_chars")
+	hasExamples = tableExists(englishDB, "examples")
+	hasTrigramIndex = tableExists(englishDB, "trigram_counts")
+	hasBNCPercentiles = tableExists(englishDB, "bnc_percentiles")
+	hasSources = tableExists(englishDB, "sources")
+	multipleSources = false
+	if hasSources {
+		var n int
+		multipleSources = englishDB.QueryRow(`SELECT COUNT(*) FROM sources`).Scan(&n) == nil && n > 1
+	}
+	hasPinyin = pinyinAvailable(chineseDB)
+	hasTags = columnExists(englishDB, "words", "tag")
+	hasSplitPhonetics = columnExists(englishDB, "words", "phonetic_us") && columnExists(englishDB, "words", "phonetic_uk")
+}
+
+// columnExists 检查表中是否有指定的列
+func columnExists(db *sql.DB, table, column string) bool {
+	var n int
+	err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, table, column).Scan(&n)
+	return err == nil && n > 0
+}
+
+// pinyinAvailable 检查中文数据库是否写入了拼音（旧版本数据库没有拼音列）
+func pinyinAvailable(db *sql.DB) bool {
+	var exists bool
+	err := db.QueryRow(`SELECT EXISTS(SELECT 1 FROM chinese_words WHERE pinyin > '')`).Scan(&exists)
+	return err == nil && exists
+}
+
+// search 根据输入的语言选择查询方式，双向搜索模式下同时查找另一种语言
+func search(query string) ([]SearchResult, error) {
+	if err := databaseReady(); err != nil {
+		return nil, err
+	}
+
+	var results []SearchResult
+	var err error
+	start := time.Now()
+
+	if combinedSearch {
+		results, err = searchCombined(query)
+	} else if dualSearch {
+		results, err = searchBothLanguages(query)
+	} else {
+		results, err = searchSameLanguage(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	results = mergeUserWords(query, results)
+
+	// 没有任何结果时，按需到另一种语言的释义中查找（双向搜索已经查过，不再重复）
+	if len(results) == 0 && crossLanguageFallback && !dualSearch {
+		if results, err = searchOtherLanguage(query); err != nil {
+			return nil, err
+		}
+	}
+
+	results = removeIgnored(results)
+	if personalRanking.Load() {
+		rankByLookups(results)
+	}
+	if groupFamilies.Load() {
+		results = groupByFamily(results)
+	}
+	logQuery("搜索合计", start, len(results), "", query)
+	return results, nil
+}
+
+// searchSameLanguage 在输入所属语言的数据库中查找：中文查中文数据库，
+// 其他输入查英文数据库，有拼音数据时再附加拼音匹配的中文词
+func searchSameLanguage(query string) ([]SearchResult, error) {
+	if isChinese(query) {
+		return searchChinese(query)
+	}
+	results, err := searchEnglish(query)
+	if err == nil && hasPinyin && isPinyinQuery(query) {
+		var pinyinResults []SearchResult
+		if pinyinResults, err = searchPinyin(query); err == nil {
+			results = append(results, pinyinResults...)
+		}
+	}
+	return results, err
+}
+
+// dualSearch 为 true 时每次搜索同时查找两种语言
+var dualSearch bool
+
+// searchBothLanguages 并发执行本语言的搜索和另一种语言的释义查找，合并为一个列表
+//
+// 两个查询使用不同的数据库，各自写入自己的结果切片，全部完成后再合并：本语言的结果在前，
+// 另一种语言的结果归在「释义中提到」分组下，已经出现过的词条（如拼音匹配到的中文词）不再重复
+func searchBothLanguages(query string) ([]SearchResult, error) {
+	var other []SearchResult
+	var otherErr error
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		other, otherErr = searchOtherLanguage(query)
+	}()
+
+	results, err := searchSameLanguage(query)
+	wg.Wait()
+	if err != nil {
+		return nil, err
+	}
+	if otherErr != nil {
+		return nil, otherErr
+	}
+
+	seen := make(map[string]bool, len(results))
+	for _, r := range results {
+		seen[r.Word] = true
+	}
+	for _, r := range other {
+		if !seen[r.Word] {
+			results = append(results, r)
+		}
+	}
+	return results, nil
+}
+
+// crossLanguageFallback 为 true 时，搜索没有结果会改为在另一种语言的释义中查找
+var crossLanguageFallback bool
+
+// searchOtherLanguage 在另一种语言的数据库中查找释义提到 query 的词条
+//
+// 英文关键词在中文词对应的英文单词及其释义中查找（只匹配完整的单词，避免 cat 匹配到 education），
+// 返回中文词；中文关键词在英文单词的中文释义中查找，返回英文单词。两者都需要扫描整张表，
+// 因此只在正常搜索没有结果时使用
+func searchOtherLanguage(query string) ([]SearchResult, error) {
+	seen := make(map[string]bool)
+	if isChinese(query) {
+		return collectMatches(englishDB, MatchCrossLanguage, nil, seen,
+			`SELECT word FROM words WHERE translation LIKE ? ESCAPE '\' LIMIT ?`, "%"+escapeLike(query)+"%", searchLimit)
+	}
+
+	wholeWord, err := regexp.Compile(`(?i)(^|[^a-z])` + regexp.QuoteMeta(query) + `($|[^a-z])`)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	sqlQuery := `SELECT chinese, english_words FROM chinese_words WHERE english_words LIKE ? ESCAPE '\'`
+	pattern := "%" + escapeLike(query) + "%"
+	ctx, cancel := queryContext()
+	defer cancel()
+	rows, err := chineseDB.QueryContext(ctx, sqlQuery, pattern)
+	if err != nil {
+		return nil, timeoutError(ctx, err)
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() && len(results) < searchLimit {
+		var chinese, englishWords string
+		if err := rows.Scan(&chinese, &englishWords); err != nil || seen[chinese] {
+			continue
+		}
+		if wholeWord.MatchString(englishWords) {
+			results = append(results, SearchResult{Word: chinese, Match: MatchCrossLanguage})
+			seen[chinese] = true
+		}
+	}
+	logQuery(MatchCrossLanguage.Label(), start, len(results), sqlQuery, pattern)
+	return results, timeoutError(ctx, rows.Err())
+}
+
+// firstHan 返回字符串中的第一个汉字
+func firstHan(text string) (rune, bool) {
+	for _, r := range text {
+		if unicode.Is(unicode.Han, r) {
+			return r, true
+		}
+	}
+	return 0, false
+}
+
+func searchChinese(keyword string) ([]SearchResult, error) {
+	var results []SearchResult
+	var err error
 	seen := make(map[string]bool) // 用于去重
-	limit := 100
+	limit := searchLimit
 
 	// 1. 精确匹配
-	query := `SELECT DISTINCT chinese FROM chinese_words WHERE chinese = ? LIMIT ?`
-	rows, err := chineseDB.Query(query, keyword, limit)
-	if err == nil {
-		for rows.Next() {
-			var chinese string
-			if err := rows.Scan(&chinese); err == nil && !seen[chinese] {
-				results = append(results, chinese)
-				seen[chinese] = true
-			}
-		}
-		rows.Close()
+	if results, err = collectMatches(chineseDB, MatchExact, results, seen,
+		`SELECT DISTINCT chinese FROM chinese_words WHERE chinese = ? LIMIT ?`, keyword, limit); err != nil {
+		return nil, err
 	}
 
 	// 如果已经达到限制，直接返回
 	if len(results) >= limit {
-		return results
+		return results, nil
 	}
 
-	// 2. 前缀匹配（排除已匹配的）
-	remaining := limit - len(results)
-	query = `SELECT DISTINCT chinese FROM chinese_words WHERE chinese LIKE ? AND chinese != ? LIMIT ?`
-	rows, err = chineseDB.Query(query, keyword+"%", keyword, remaining)
-	if err == nil {
-		for rows.Next() {
-			var chinese string
-			if err := rows.Scan(&chinese); err == nil && !seen[chinese] {
-				results = append(results, chinese)
-				seen[chinese] = true
-			}
+	// 开启 chineseSubphrases 时，被查询包含的词排在精确匹配之后、包含查询的词之前
+	if chineseSubphrases {
+		if results, err = searchSubphrases(keyword, results, seen, limit); err != nil {
+			return nil, err
+		}
+		if len(results) >= limit {
+			return results, nil
 		}
-		rows.Close()
+	}
+
+	// 2. 前缀匹配（排除已匹配的）
+	// 使用范围条件代替 LIKE，使查询可以走 idx_chinese 索引
+	if results, err = collectMatches(chineseDB, MatchPrefix, results, seen,
+		`SELECT DISTINCT chinese FROM chinese_words WHERE chinese > ? AND chinese < ? LIMIT ?`, keyword, keyword+"\U0010FFFF", limit-len(results)); err != nil {
+		return nil, err
 	}
 
 	// 如果已经达到限制，直接返回
 	if len(results) >= limit {
-		return results
+		return results, nil
 	}
 
 	// 3. 包含匹配（排除已匹配的）
-	remaining = limit - len(results)
-	query = `SELECT DISTINCT chinese FROM chinese_words WHERE chinese LIKE ? AND chinese NOT LIKE ? LIMIT ?`
-	rows, err = chineseDB.Query(query, "%"+keyword+"%", keyword+"%", remaining)
-	if err == nil {
-		for rows.Next() {
-			var chinese string
-			if err := rows.Scan(&chinese); err == nil && !seen[chinese] {
-				results = append(results, chinese)
-				seen[chinese] = true
+	// 有汉字倒排索引时只检查包含关键词第一个汉字的词，否则退回全表扫描；关键词中的 % 和 _ 按字面匹配
+	escaped := escapeLike(keyword)
+	if ch, ok := firstHan(keyword); ok && hasChineseCharIndex {
+		if results, err = collectMatches(chineseDB, MatchContains, results, seen, chineseCharContainsSQL,
+			string(ch), "%"+escaped+"%", escaped+"%", limit-len(results)); err != nil {
+			return nil, err
+		}
+	} else {
+		if results, err = collectMatches(chineseDB, MatchContains, results, seen,
+			`SELECT DISTINCT chinese FROM chinese_words WHERE chinese LIKE ? ESCAPE '\' AND chinese NOT LIKE ? ESCAPE '\' LIMIT ?`, "%"+escaped+"%", escaped+"%", limit-len(results)); err != nil {
+			return nil, err
+		}
+	}
+
+	return results, nil
+}
+
+// chineseCharContainsSQL 用汉字倒排索引做中文包含匹配：参数依次为关键词的第一个汉字、包含和排除前缀的 LIKE 模式、数量限制
+const chineseCharContainsSQL = `SELECT c.chinese FROM chinese_chars cc JOIN chinese_words c ON c.id = cc.word_id
+	WHERE cc.ch = ? AND c.chinese LIKE ? ESCAPE '\' AND c.chinese NOT LIKE ? ESCAPE '\' LIMIT ?`
+
+// renderDetail 根据单词语言查询并渲染 key.word 的详细信息，显示方式只取自 key，不读取界面的全局状态，
+// 因此可以在后台协程中渲染，结果与键一致
+//
+// key.split 为 true 时英文单词的中文释义单独放在 side 中返回，用于分栏显示；
+// 中文词没有可拆分的部分，side 始终为空。key.full 为 false 时中文词最多列出 maxChineseEntries 个英文单词，
+// 交互界面按展开键后以 true 重新渲染；命令行和 HTTP 服务总是输出完整列表
+func renderDetail(key detailKey) (main string, side string, err error) {
+	if err := databaseReady(); err != nil {
+		return "", "", err
+	}
+	if isChinese(key.word) {
+		limit := config.MaxChineseEntries
+		if key.full {
+			limit = 0
+		}
+		main, err = showChineseDetail(key.word, limit, key.emphasis)
+		if key.firstLookup != "" && err == nil {
+			main += "\n\n" + key.firstLookup
+		}
+		return main, "", err
+	}
+
+	w, err := lookupEnglishWord(key.word)
+	if err != nil {
+		return "", "", err
+	}
+	main, side = formatEnglishDetail(w, key)
+	if key.firstLookup != "" && !key.phonetic {
+		main += "\n\n" + key.firstLookup
+	}
+	return main, side, nil
+}
+
+// truncateDetail 把详情文本限制在约 max 个字符以内，超出时在整行处截断并附上提示；max 为 0 表示不限制
+//
+// 只在行边界截断，避免把 tview 的颜色标签截成两半
+func truncateDetail(text string, max int) (string, bool) {
+	if max <= 0 || utf8.RuneCountInString(text) <= max {
+		return text, false
+	}
+
+	var kept []string
+	count := 0
+	for _, line := range strings.Split(text, "\n") {
+		n := utf8.RuneCountInString(line) + 1
+		if count+n > max && len(kept) > 0 {
+			break
+		}
+		kept = append(kept, line)
+		count += n
+	}
+	kept = append(kept, "", "[gray]…(已截断，按 "+keyLabel(actionExpand)+" 查看完整)[-]")
+	return strings.Join(kept, "\n"), true
+}
+
+// lookupEnglishWord 查询英文单词的基本信息
+func lookupEnglishWord(word string) (Word, error) {
+	// 用户词表中的词条优先于词库中的同名词条
+	if w, ok := lookupUserWord(word); ok {
+		return w, nil
+	}
+
+	columns := `w.word, w.phonetic, w.definition, w.translation, w.bnc`
+	if hasSplitPhonetics {
+		columns += `, w.phonetic_us, w.phonetic_uk`
+	}
+	query := `SELECT ` + columns + ` FROM words w WHERE w.word = ?`
+
+	start := time.Now()
+	ctx, cancel := queryContext()
+	defer cancel()
+	if hasSources {
+		query = `SELECT ` + columns + `, COALESCE(s.name, '')
+		         FROM words w LEFT JOIN sources s ON s.id = w.source_id WHERE w.word = ?`
+	}
+	w, err := scanWord(englishDB.QueryRowContext(ctx, query, word), hasSplitPhonetics, hasSources)
+	logQuery("单词详情", start, -1, query, word)
+
+	if err != nil {
+		return Word{}, detailError(word, timeoutError(ctx, err))
+	}
+	return w, nil
+}
+
+// 英文单词详情中可以调整顺序或隐藏的栏目
+const (
+	sectionPhonetic     = "phonetic"     // 音标
+	sectionDefinition   = "definition"   // 英文释义
+	sectionTranslation  = "translation"  // 中文释义
+	sectionExamples     = "examples"     // 例句
+	sectionBNC          = "bnc"          // BNC词频
+	sectionSyllables    = "syllables"    // 音节拆分
+	sectionAntonyms     = "antonyms"     // 反义词（推测）
+	sectionCollocations = "collocations" // 常见搭配（推测）
+	sectionDifficulty   = "difficulty"   // 字母数和难度估计
+)
+
+// defaultDetailSections 默认的栏目顺序
+var defaultDetailSections = []string{sectionPhonetic, sectionDefinition, sectionTranslation, sectionExamples, sectionBNC, sectionDifficulty}
+
+// knownDetailSections 所有可用的栏目，音节拆分、反义词和常见搭配是启发式结果，默认不显示
+var knownDetailSections = append(append([]string{}, defaultDetailSections...), sectionSyllables, sectionAntonyms, sectionCollocations)
+
+// detailSections 当前显示的栏目及其顺序，未列出的栏目不显示
+var detailSections = defaultDetailSections
+
+// validateDetailSections 检查栏目名称是否有效且没有重复
+func validateDetailSections(sections []string) error {
+	seen := make(map[string]bool)
+	for _, name := range sections {
+		known := false
+		for _, d := range knownDetailSections {
+			if name == d {
+				known = true
 			}
 		}
-		rows.Close()
+		if !known {
+			return fmt.Errorf("未知的详情栏目 %q（可选 %s）", name, strings.Join(knownDetailSections, "、"))
+		}
+		if seen[name] {
+			return fmt.Errorf("详情栏目 %q 重复", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// labeledPhonetic 带有英美标注的一个音标
+type labeledPhonetic struct {
+	label string // 英 或 美
+	text  string
+}
+
+// splitPhonetics 返回单词单独提供的英式和美式音标，两者相同时只保留一个不加区分的音标
+func splitPhonetics(w Word) []labeledPhonetic {
+	if w.PhoneticUK != "" && w.PhoneticUK == w.PhoneticUS {
+		return []labeledPhonetic{{"英/美", w.PhoneticUK}}
+	}
+	var result []labeledPhonetic
+	if w.PhoneticUK != "" {
+		result = append(result, labeledPhonetic{"英", w.PhoneticUK})
+	}
+	if w.PhoneticUS != "" {
+		result = append(result, labeledPhonetic{"美", w.PhoneticUS})
+	}
+	return result
+}
+
+// phoneticText 返回详情中显示的音标：有单独的英式、美式音标时分别标注（英 /…/  美 /…/），否则为词典中的单个音标
+func phoneticText(w Word) string {
+	phonetics := splitPhonetics(w)
+	if len(phonetics) == 0 {
+		return w.Phonetic
+	}
+	parts := make([]string, len(phonetics))
+	for i, p := range phonetics {
+		parts[i] = "[gray]" + p.label + "[-] /" + p.text + "/"
+	}
+	return strings.Join(parts, "  ")
+}
+
+// englishSection 渲染单词详情中的一个栏目，内容为空时返回 nil
+func englishSection(name string, w Word) []string {
+	var lines []string
+	switch name {
+	case sectionPhonetic:
+		if text := phoneticText(w); text != "" {
+			lines = append(lines, "[yellow]音标:[-] "+text)
+		}
+	case sectionDefinition:
+		if w.Definition != "" {
+			lines = append(lines, "[yellow]英文释义:[-]")
+			lines = append(lines, definitionLines(w.Definition)...)
+		}
+	case sectionTranslation:
+		if w.Translation != "" {
+			lines = append(lines, "[yellow]中文释义:[-]")
+			lines = append(lines, senseLines(w.Translation)...)
+		}
+	case sectionExamples:
+		if hasExamples {
+			if examples := getExamples(w.Word); len(examples) > 0 {
+				lines = append(lines, "[yellow]例句:[-]")
+				for _, ex := range examples {
+					lines = append(lines, "  [green]•[-] "+ex)
+				}
+			}
+		}
+	case sectionBNC:
+		if bnc := formatBNC(w.Bnc); bnc != "" {
+			lines = append(lines, "[yellow]BNC词频:[-] "+bnc)
+		}
+	case sectionDifficulty:
+		if level := wordDifficulty(w.Word, w.Bnc); level != "" {
+			lines = append(lines, fmt.Sprintf("[yellow]难度:[-] %s · %d 个字母", level, letterCount(w.Word)))
+		} else {
+			lines = append(lines, fmt.Sprintf("[yellow]长度:[-] %d 个字母", letterCount(w.Word)))
+		}
+	case sectionSyllables:
+		if split := splitSyllables(w.Word); split != w.Word {
+			lines = append(lines, "[yellow]音节:[-] "+split)
+		}
+	case sectionAntonyms:
+		if antonyms := findAntonyms(w); len(antonyms) > 0 {
+			lines = append(lines, "[yellow]反义词:[-] "+strings.Join(antonyms, ", ")+" [gray]（根据前缀和常见词对推测，仅供参考）[-]")
+		}
+	case sectionCollocations:
+		if collocations := findCollocations(w); len(collocations) > 0 {
+			lines = append(lines, "[yellow]常见搭配:[-] "+strings.Join(collocations, ", ")+" [gray]（根据词库中的词组推测，仅供参考）[-]")
+		}
+	}
+	if lines == nil {
+		return nil
+	}
+	return append(lines, "")
+}
+
+// formatEnglishDetail 按 detailSections 的顺序把单词信息格式化为详情文本，显示方式取自 key（不使用其中的 word），
+// key.split 为 true 时中文释义单独返回
+func formatEnglishDetail(w Word, key detailKey) (main string, translation string) {
+	if key.phonetic {
+		return formatPhoneticOnly(w), ""
+	}
+
+	var details []string
+	details = append(details, withSenseCount(headword("单词", w.Word, key.emphasis), w.Translation, key.emphasis)...)
+
+	var trans []string
+	for _, name := range detailSections {
+		lines := englishSection(name, w)
+		if key.split && name == sectionTranslation {
+			trans = lines
+			continue
+		}
+		details = append(details, anchorSection(name, lines)...)
+	}
+
+	// 只有一个词典时来源都相同，不必显示；用户词表的词条总是标明来源
+	if (multipleSources || w.Source == userWordSource) && w.Source != "" {
+		details = append(details, "[gray]来源: "+tview.Escape(w.Source)+"[-]")
+	}
+
+	return joinDetail(details), joinDetail(trans)
+}
+
+// emphasisMode 为 true 时详情中的词头加宽显示（F11 切换），只在主线程中读写，渲染时经由 detailKey 传入
+var emphasisMode bool
+
+// headword 渲染详情开头的词头及其后的空行，emphasis 为 true 时加强显示
+//
+// 终端无法调大字号，加强显示时用字间距、粗体加下划线和一条同宽的横线让词头更醒目
+func headword(label, word string, emphasis bool) []string {
+	if !emphasis {
+		return []string{"[yellow]" + label + ":[-] [white::b]" + word + "[-]", ""}
 	}
 
-	return results
+	spaced := strings.Join(strings.Split(word, ""), " ")
+	rule := strings.Repeat("━", tview.TaggedStringWidth(spaced))
+	return []string{"", "  [white::bu]" + spaced + "[-:-:-]", "  [yellow]" + rule + "[-]", ""}
 }
 
-func showEnglishDetail(word string) string {
-	query := `SELECT word, phonetic, definition, translation, bnc 
-	          FROM words WHERE word = ?`
+// phoneticMode 为 true 时英文详情只显示单词和音标，用于练习发音（F12 切换），只在主线程中读写，渲染时经由 detailKey 传入
+var phoneticMode bool
 
-	var w Word
-	err := englishDB.QueryRow(query, word).Scan(
-		&w.Word, &w.Phonetic, &w.Definition, &w.Translation, &w.Bnc)
+// formatPhoneticOnly 以加强显示的方式只渲染单词和音标
+func formatPhoneticOnly(w Word) string {
+	lines := []string{"", "  [white::bu]" + strings.Join(strings.Split(w.Word, ""), " ") + "[-:-:-]", ""}
+	if w.PhoneticUS != "" || w.PhoneticUK != "" {
+		for _, p := range splitPhonetics(w) {
+			lines = append(lines, "  [gray]"+p.label+"[-] [yellow::b]/ "+p.text+" /[-:-:-]")
+		}
+	} else if w.Phonetic != "" {
+		lines = append(lines, "  [yellow::b]/ "+w.Phonetic+" /[-:-:-]")
+	} else {
+		lines = append(lines, "  [gray]没有音标[-]")
+	}
+	return joinDetail(lines)
+}
+
+// joinDetail 把详情各行连接起来，并去掉末尾多余的空行
+func joinDetail(lines []string) string {
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
 
+// renderComparison 查询两个英文单词并按栏目对照渲染，split 含义同 renderDetail
+func renderComparison(pinned, word string, split bool) (main string, side string, err error) {
+	a, err := lookupEnglishWord(pinned)
 	if err != nil {
-		return "查询出错: " + err.Error()
+		return "", "", err
 	}
+	b, err := lookupEnglishWord(word)
+	if err != nil {
+		return "", "", err
+	}
+	main, side = formatComparison(a, b, split)
+	return main, side, nil
+}
 
+// formatComparison 把两个单词的同一栏目放在一起显示，便于对比近义词或易混词
+func formatComparison(a, b Word, split bool) (main string, translation string) {
 	var details []string
-	details = append(details, "[yellow]单词:[-] [white::b]"+w.Word+"[-]")
+	details = append(details, "[yellow]对比:[-] "+compareName(a)+" [gray]↔[-] "+compareName(b))
 	details = append(details, "")
 
-	if w.Phonetic != "" {
-		details = append(details, "[yellow]音标:[-] "+w.Phonetic)
-		details = append(details, "")
+	var trans []string
+	for _, name := range detailSections {
+		lines := comparisonSection(name, a, b)
+		if split && name == sectionTranslation {
+			trans = lines
+			continue
+		}
+		details = append(details, lines...)
 	}
 
-	if w.Definition != "" {
-		details = append(details, "[yellow]英文释义:[-]")
-		// 将 \n 替换为实际换行，然后按行分割
-		defText := strings.ReplaceAll(w.Definition, "\\n", "\n")
-		defs := strings.Split(defText, "\n")
-		for _, def := range defs {
-			if strings.TrimSpace(def) != "" {
-				details = append(details, "  [green]•[-] "+strings.TrimSpace(def))
-			}
+	return joinDetail(details), joinDetail(trans)
+}
+
+// compareName 对比视图中单词名称的显示形式
+func compareName(w Word) string {
+	return "[cyan::b]" + w.Word + "[-::-]"
+}
+
+// comparisonSection 渲染对比视图中的一个栏目，两个单词都没有内容时返回 nil，例句不参与对比
+func comparisonSection(name string, a, b Word) []string {
+	pair := []Word{a, b}
+
+	bullets := func(title string, text func(Word) []string) []string {
+		if len(text(a)) == 0 && len(text(b)) == 0 {
+			return nil
+		}
+		lines := []string{"[yellow]" + title + ":[-]"}
+		for _, w := range pair {
+			lines = append(lines, "  "+compareName(w))
+			lines = append(lines, text(w)...)
 		}
-		details = append(details, "")
+		return append(lines, "")
 	}
 
-	if w.Translation != "" {
-		details = append(details, "[yellow]中文释义:[-]")
-		// 将 \n 替换为实际换行，然后按行分割
-		transText := strings.ReplaceAll(w.Translation, "\\n", "\n")
-		trans := strings.Split(transText, "\n")
-		for _, tran := range trans {
-			if strings.TrimSpace(tran) != "" {
-				details = append(details, "  [green]•[-] "+strings.TrimSpace(tran))
+	switch name {
+	case sectionPhonetic:
+		if phoneticText(a) == "" && phoneticText(b) == "" {
+			return nil
+		}
+		lines := []string{"[yellow]音标:[-]"}
+		for _, w := range pair {
+			lines = append(lines, "  "+compareName(w)+" "+phoneticText(w))
+		}
+		return append(lines, "")
+	case sectionDefinition:
+		return bullets("英文释义", func(w Word) []string { return definitionLines(w.Definition) })
+	case sectionTranslation:
+		return bullets("中文释义", func(w Word) []string { return senseLines(w.Translation) })
+	case sectionBNC:
+		if (a.Bnc == "" || a.Bnc == "0") && (b.Bnc == "" || b.Bnc == "0") {
+			return nil
+		}
+		lines := []string{"[yellow]BNC词频:[-]"}
+		for _, w := range pair {
+			bnc := formatBNC(w.Bnc)
+			if bnc == "" {
+				bnc = "-"
 			}
+			lines = append(lines, "  "+compareName(w)+" "+bnc)
 		}
-		details = append(details, "")
+		return append(lines, "")
 	}
+	return nil
+}
+
+// bulletLines 把数据库中以 \n 分隔的释义拆成带圆点的行，忽略空行
+func bulletLines(text string) []string {
+	// 将 \n 替换为实际换行，然后按行分割
+	text = strings.ReplaceAll(text, "\\n", "\n")
 
-	if w.Bnc != "" && w.Bnc != "0" {
-		details = append(details, "[yellow]BNC词频:[-] "+w.Bnc)
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, "  [green]•[-] "+strings.TrimSpace(line))
+		}
 	}
+	return lines
+}
 
-	return strings.Join(details, "\n")
+// detailError 把查询详情时的错误转换为便于用户理解的形式
+func detailError(word string, err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("未找到 %s 的详细信息", word)
+	}
+	if errors.Is(err, errQueryTimeout) {
+		return fmt.Errorf("查询 %s 超时", word)
+	}
+	return fmt.Errorf("查询 %s 出错: %v", word, err)
 }
 
-func showChineseDetail(chinese string) string {
+// getExamples 查询单词的例句，有翻译时附在例句后面
+func getExamples(word string) []string {
+	query := `SELECT example, translation FROM examples WHERE word = ? LIMIT 10`
+	start := time.Now()
+	ctx, cancel := queryContext()
+	defer cancel()
+	rows, err := englishDB.QueryContext(ctx, query, word)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	var examples []string
+	for rows.Next() {
+		var example string
+		var translation sql.NullString
+		if err := rows.Scan(&example, &translation); err != nil {
+			continue
+		}
+		example = tview.Escape(example)
+		if translation.String != "" {
+			example += " [gray]" + tview.Escape(translation.String) + "[-]"
+		}
+		examples = append(examples, example)
+	}
+	logQuery("例句", start, len(examples), query, word)
+	return examples
+}
+
+// showChineseDetail 渲染中文词的详情：对应的英文单词（已在生成数据库时按词频排序），
+// limit 大于 0 时只列出前 limit 个并提示按键显示全部，emphasis 为 true 时加强显示词头
+func showChineseDetail(chinese string, limit int, emphasis bool) (string, error) {
 	query := `SELECT english_words FROM chinese_words WHERE chinese = ?`
 
 	var englishWords string
-	err := chineseDB.QueryRow(query, chinese).Scan(&englishWords)
+	start := time.Now()
+	ctx, cancel := queryContext()
+	defer cancel()
+	err := timeoutError(ctx, chineseDB.QueryRowContext(ctx, query, chinese).Scan(&englishWords))
+	logQuery("中文详情", start, -1, query, chinese)
 	if err != nil {
-		return "查询出错: " + err.Error()
+		return "", detailError(chinese, err)
 	}
 
 	var details []string
-	details = append(details, "[yellow]中文:[-] [white::b]"+chinese+"[-]")
-	details = append(details, "")
+	details = append(details, headword("中文", chinese, emphasis)...)
 	details = append(details, "[yellow]对应的英文单词:[-]")
 	details = append(details, "")
 
 	// 解析英文单词列表（格式：英文单词（中文释义），用换行符分隔）
-	words := strings.Split(englishWords, "\n")
-	for i, word := range words {
+	// 注意 strings.Split("", "\n") 返回 [""]，因此按实际写入的行数判断是否为空
+	count, hidden := 0, 0
+	for _, word := range strings.Split(englishWords, "\n") {
 		word = strings.TrimSpace(word)
 		if word == "" {
 			continue
 		}
+		if limit > 0 && count >= limit {
+			hidden++
+			continue
+		}
 
 		// 清除单词释义中的换行符
 		word = cleanNewlines(word)
 
-		line := fmt.Sprintf("[green]%d.[-] [cyan]%s[-]", i+1, word)
+		// 英文单词可以在界面中点击或用 ←/→ 选中后打开，括号中的释义不可点击
+		count++
+		english, def, _ := strings.Cut(word, "（")
+		if def != "" {
+			def = "（" + def
+		}
+		line := fmt.Sprintf("[green]%d.[-] [cyan]%s%s[-]", count, linkRegion(count, tview.Escape(english)), def)
 		details = append(details, line)
 	}
 
-	if len(words) == 0 {
-		details = append(details, "[red]未找到对应的英文单词[-]")
+	if count == 0 {
+		details = append(details, "[red]无对应英文单词[-]")
+	}
+	if hidden > 0 {
+		details = append(details, "", fmt.Sprintf("[gray]…还有 %d 个英文单词，按 %s 显示全部[-]", hidden, keyLabel(actionExpand)))
 	}
 
-	return strings.Join(details, "\n")
+	return strings.Join(details, "\n"), nil
 }
@@ -0,0 +1,523 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fixtureCSV 测试用的小词典：从 ECDICT 中摘取的几十个词条，外加一个大小写变体 Apple
+const fixtureCSV = "testdata/ecdict.csv"
+
+// useFixtureDatabases 用 fixtureCSV 在内存中生成两个数据库供搜索和详情查询使用，并恢复默认配置
+func useFixtureDatabases(t testing.TB) {
+	t.Helper()
+	config = defaultConfig()
+	english, chinese, err := openMemoryDatabases(fixtureCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		english.Close()
+		chinese.Close()
+	})
+	useDatabases(english, chinese)
+}
+
+// wordsOf 返回搜索结果中的单词，便于比较
+func wordsOf(results []SearchResult) []string {
+	words := make([]string, len(results))
+	for i, r := range results {
+		words[i] = r.Word
+	}
+	return words
+}
+
+// matchOf 返回 word 在搜索结果中的匹配方式，不在结果中时 ok 为 false
+func matchOf(results []SearchResult, word string) (match MatchType, ok bool) {
+	for _, r := range results {
+		if r.Word == word {
+			return r.Match, true
+		}
+	}
+	return 0, false
+}
+
+func TestSearchEnglishExactBeforePrefix(t *testing.T) {
+	useFixtureDatabases(t)
+
+	results, err := searchEnglish("apple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) == 0 || results[0].Word != "apple" || results[0].Match != MatchExact {
+		t.Fatalf("searchEnglish(apple) = %v，第一个结果应为精确匹配的 apple", wordsOf(results))
+	}
+
+	// 精确匹配之后依次是前缀匹配和包含匹配，不会交错
+	last := MatchExact
+	for _, r := range results {
+		if r.Match < last {
+			t.Errorf("%s（%s）排在了 %s 的结果之后: %v", r.Word, r.Match.Label(), last.Label(), wordsOf(results))
+		}
+		last = r.Match
+	}
+	for word, want := range map[string]MatchType{"applet": MatchPrefix, "applejack": MatchPrefix, "pineapple": MatchContains} {
+		if got, ok := matchOf(results, word); !ok || got != want {
+			t.Errorf("%s 的匹配方式为 %v（找到: %v），应为 %s", word, got.Label(), ok, want.Label())
+		}
+	}
+}
+
+// withCharIndex 在 fn 执行期间假定中文数据库有（或没有）汉字倒排索引，用于比较两种包含匹配的查询方式
+func withCharIndex(index bool, fn func()) {
+	saved := hasChineseCharIndex
+	hasChineseCharIndex = index
+	defer func() { hasChineseCharIndex = saved }()
+	fn()
+}
+
+func TestSearchChineseCharIndexMatchesScan(t *testing.T) {
+	useFixtureDatabases(t)
+
+	var indexed, scanned []SearchResult
+	var err error
+	withCharIndex(true, func() { indexed, err = searchChinese("家") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	withCharIndex(false, func() { scanned, err = searchChinese("家") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := matchOf(indexed, "国家"); !ok {
+		t.Errorf("searchChinese(家) = %v，应包含 国家", wordsOf(indexed))
+	}
+	// 两种方式返回的词相同，顺序可能不同
+	a, b := wordsOf(indexed), wordsOf(scanned)
+	slices.Sort(a)
+	slices.Sort(b)
+	if !slices.Equal(a, b) {
+		t.Errorf("使用汉字索引的结果 %v 与全表扫描的结果 %v 不同", a, b)
+	}
+}
+
+// BenchmarkSearchChineseContains 比较中文包含匹配使用汉字倒排索引（char-index）和全表扫描（scan）的耗时，
+// 常见汉字在大词典上的差距最明显
+func BenchmarkSearchChineseContains(b *testing.B) {
+	useFixtureDatabases(b)
+	for _, bc := range []struct {
+		name  string
+		index bool
+	}{{"char-index", true}, {"scan", false}} {
+		b.Run(bc.name, func(b *testing.B) {
+			withCharIndex(bc.index, func() {
+				for b.Loop() {
+					if _, err := searchChinese("家"); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		})
+	}
+}
+
+// BenchmarkParallelSearch 在两万个随机单词的数据库文件上并发执行 searchEnglish，
+// 比较 openReadDB 的设置（private-cache）和加上 cache=shared（shared-cache）时的吞吐量
+func BenchmarkParallelSearch(b *testing.B) {
+	config = defaultConfig()
+	csvFile := syntheticCSV(b, 20000)
+	dir := b.TempDir()
+	englishFile, chineseFile := filepath.Join(dir, "english.db"), filepath.Join(dir, "chinese.db")
+	if err := withCSV(csvFile, func(r io.Reader) error { return CreateEnglishDB(context.Background(), r, englishFile) }); err != nil {
+		b.Fatal(err)
+	}
+	if err := withCSV(csvFile, func(r io.Reader) error { return CreateChineseDB(context.Background(), r, chineseFile) }); err != nil {
+		b.Fatal(err)
+	}
+
+	// sharedCache 与 openReadDB 相同，只是多了 cache=shared
+	sharedCache := func(file string) (*sql.DB, error) {
+		db, err := sql.Open("sqlite", readOnlyDSN(file)+"&cache=shared")
+		if err != nil {
+			return nil, err
+		}
+		db.SetMaxOpenConns(readPoolSize())
+		db.SetMaxIdleConns(readPoolSize())
+		return db, nil
+	}
+
+	keywords := []string{"ation", "tion", "abc", "zzz", "qu"}
+	for _, bc := range []struct {
+		name string
+		open func(string) (*sql.DB, error)
+	}{{"private-cache", openReadDB}, {"shared-cache", sharedCache}} {
+		b.Run(bc.name, func(b *testing.B) {
+			english, err := bc.open(englishFile)
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer english.Close()
+			chinese, err := bc.open(chineseFile)
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer chinese.Close()
+			useDatabases(english, chinese)
+
+			var next atomic.Int64
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					keyword := keywords[int(next.Add(1))%len(keywords)]
+					if _, err := searchEnglish(keyword); err != nil {
+						b.Error(err)
+						return
+					}
+				}
+			})
+		})
+	}
+}
+
+// resetHistory 清空搜索历史和查阅次数，测试结束时再清空一次
+func resetHistory(t *testing.T) {
+	reset := func() {
+		historyMutex.Lock()
+		searchHistory = nil
+		lastHistoryWord, lastHistoryTime = "", time.Time{}
+		historyMutex.Unlock()
+		lookupMutex.Lock()
+		lookupCounts = make(map[string]int)
+		firstLookups = make(map[string]string)
+		lookupMutex.Unlock()
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
+// TestConcurrentHistory 多个协程同时记录和读取搜索历史，用 go test -race 运行时检查数据竞争
+func TestConcurrentHistory(t *testing.T) {
+	resetHistory(t)
+
+	const writers, perWriter = 8, 50
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < perWriter; j++ {
+				addToHistory(fmt.Sprintf("word%d-%d", i, j))
+			}
+		}(i)
+	}
+	var readers sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				history := getSearchHistory()
+				// 返回的是副本，修改它不影响搜索历史
+				for k := range history {
+					history[k] = ""
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(stop)
+	readers.Wait()
+
+	history := getSearchHistory()
+	if len(history) != writers*perWriter {
+		t.Fatalf("搜索历史有 %d 条，应为 %d 条", len(history), writers*perWriter)
+	}
+	seen := make(map[string]bool)
+	for _, w := range history {
+		if w == "" || seen[w] {
+			t.Fatalf("搜索历史中有空的或重复的单词 %q", w)
+		}
+		seen[w] = true
+	}
+	// 每个协程按顺序记录，同一协程的单词在历史中后记录的在前
+	last := make(map[int]int)
+	for _, w := range history {
+		var i, j int
+		fmt.Sscanf(w, "word%d-%d", &i, &j)
+		if prev, ok := last[i]; ok && j > prev {
+			t.Fatalf("%s 排在同一协程先记录的 word%d-%d 之后", w, i, prev)
+		}
+		last[i] = j
+	}
+}
+
+func TestNormalizeQuery(t *testing.T) {
+	tests := []struct {
+		text, want string
+	}{
+		{"apple", "apple"},
+		{"  give up  ", "give up"},
+		{"give   up", "give up"},
+		{"in\tspite 　 of", "in spite of"},
+		{"Give Up", "Give Up"},
+		{"", ""},
+		{"   ", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeQuery(tt.text); got != tt.want {
+			t.Errorf("normalizeQuery(%q) = %q，应为 %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSearchEnglishPhrasalVerbs(t *testing.T) {
+	useFixtureDatabases(t)
+
+	tests := []struct {
+		query string
+		first string    // 排在第一位的精确匹配
+		also  []string  // 同时出现在结果中的词组
+		match MatchType // first 的匹配方式
+	}{
+		{"give up", "give up", nil, MatchExact},
+		{"  give    up ", "give up", nil, MatchExact},
+		{"Give Up", "give up", nil, MatchExact},
+		{"in spite of", "in spite of", nil, MatchExact},
+		{"give", "give", []string{"give up", "give in", "give way"}, MatchExact},
+		{"give u", "give up", nil, MatchPrefix},
+	}
+	for _, tt := range tests {
+		results, err := searchEnglish(tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) == 0 || results[0].Word != tt.first || results[0].Match != tt.match {
+			t.Errorf("searchEnglish(%q) = %v，第一个结果应为%s的 %s", tt.query, wordsOf(results), tt.match.Label(), tt.first)
+			continue
+		}
+		for _, phrase := range tt.also {
+			if got, ok := matchOf(results, phrase); !ok || got != MatchPrefix {
+				t.Errorf("searchEnglish(%q) 的结果 %v 中 %s 应为前缀匹配", tt.query, wordsOf(results), phrase)
+			}
+		}
+	}
+}
+
+func TestAddToHistoryDedupe(t *testing.T) {
+	resetHistory(t)
+
+	steps := []struct {
+		word    string
+		expire  bool // 记录前让上一次记录超出 historyDedupeWindow
+		history []string
+		count   int // word 的查阅次数
+	}{
+		{"apple", false, []string{"apple"}, 1},
+		{"dog", false, []string{"dog", "apple"}, 1},
+		// 点击和定时器先后记录同一个单词，只算一次
+		{"dog", false, []string{"dog", "apple"}, 1},
+		{"apple", false, []string{"apple", "dog"}, 2},
+		// 超出时间窗口后再次查阅同一个单词，照常记录
+		{"apple", true, []string{"apple", "dog"}, 3},
+	}
+	for i, step := range steps {
+		if step.expire {
+			historyMutex.Lock()
+			lastHistoryTime = lastHistoryTime.Add(-historyDedupeWindow)
+			historyMutex.Unlock()
+		}
+		addToHistory(step.word)
+		if got := getSearchHistory(); !slices.Equal(got, step.history) {
+			t.Errorf("第 %d 步记录 %s 后搜索历史为 %v，应为 %v", i+1, step.word, got, step.history)
+		}
+		if got := getLookupCount(step.word); got != step.count {
+			t.Errorf("第 %d 步记录 %s 后查阅次数为 %d，应为 %d", i+1, step.word, got, step.count)
+		}
+	}
+}
+
+func TestShowChineseDetailEmptyEnglishWords(t *testing.T) {
+	useFixtureDatabases(t)
+	// 旧版本生成的数据库中可能有 english_words 为空或只有空白的行
+	if _, err := chineseDB.Exec(`INSERT INTO chinese_words (chinese, english_words) VALUES
+		('空词', ''), ('空白词', ' '), ('空行词', char(10) || '  ' || char(10))`); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, chinese := range []string{"空词", "空白词", "空行词"} {
+		for _, limit := range []int{0, 1} {
+			detail, err := showChineseDetail(chinese, limit, false)
+			if err != nil {
+				t.Fatalf("showChineseDetail(%q) 出错: %v", chinese, err)
+			}
+			if !strings.Contains(detail, "无对应英文单词") {
+				t.Errorf("showChineseDetail(%q, %d) 没有提示无对应英文单词:\n%s", chinese, limit, detail)
+			}
+			if strings.Contains(detail, "1.") || strings.Contains(detail, "还有") {
+				t.Errorf("showChineseDetail(%q, %d) 列出了空的英文单词:\n%s", chinese, limit, detail)
+			}
+		}
+	}
+
+	// 有英文单词的中文词照常列出
+	detail, err := showChineseDetail("苹果", 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(detail, "无对应英文单词") || !strings.Contains(detail, "apple") {
+		t.Errorf("苹果 的详情应列出 apple:\n%s", detail)
+	}
+}
+
+func TestLemmaCandidates(t *testing.T) {
+	tests := []struct {
+		word string
+		want []string
+	}{
+		{"dogs", []string{"dog"}},
+		{"boxes", []string{"box", "boxe"}},
+		{"studies", []string{"study", "studi", "studie"}},
+		{"studied", []string{"study", "studi", "studie"}},
+		{"walked", []string{"walk", "walke"}},
+		{"stopped", []string{"stopp", "stop", "stoppe"}},
+		{"running", []string{"runn", "run", "runne"}},
+		{"making", []string{"mak", "make"}},
+		{"happily", []string{"happy", "happi"}},
+		{"quickly", []string{"quick"}},
+		// 太短或是词组、带撇号连字符的词不还原
+		{"dog", nil},
+		{"was", nil},
+		{"give ups", nil},
+		{"don'ts", nil},
+		{"co-ops", nil},
+		{"apple", nil},
+	}
+	for _, tt := range tests {
+		if got := lemmaCandidates(tt.word); !slices.Equal(got, tt.want) {
+			t.Errorf("lemmaCandidates(%q) = %q，应为 %q", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestSearchEnglishLemma(t *testing.T) {
+	useFixtureDatabases(t)
+
+	// 这些变形词都不是词典中的词条，结果的第一个应为原形
+	for query, want := range map[string]string{
+		"dogs":    "dog",
+		"boxes":   "box",
+		"studies": "study",
+		"walked":  "walk",
+		"liked":   "like",
+		"stopped": "stop",
+		"making":  "make",
+	} {
+		results, err := searchEnglish(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) == 0 || results[0].Word != want || results[0].Match != MatchLemma {
+			t.Errorf("searchEnglish(%q) = %v，第一个结果应为原形 %s", query, wordsOf(results), want)
+		}
+	}
+
+	// 本身是词条的词不还原
+	results, err := searchEnglish("running")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) == 0 || results[0].Word != "running" || results[0].Match != MatchExact {
+		t.Errorf("searchEnglish(running) = %v，第一个结果应为精确匹配的 running", wordsOf(results))
+	}
+}
+
+func TestRenderDetailNullColumns(t *testing.T) {
+	useFixtureDatabases(t)
+	withDetailSections(t, knownDetailSections...)
+	// 其他工具生成的数据库中可选的列可能是 NULL
+	if _, err := englishDB.Exec(`UPDATE words SET phonetic = NULL, bnc = NULL, tag = NULL WHERE word = 'dog'`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := englishDB.Exec(`INSERT INTO words (word) VALUES ('nullword')`); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		word    string
+		want    string // 详情中应有的内容
+		without string // 详情中不应出现的栏目
+	}{
+		{"dog", "狗", "BNC词频"},
+		{"nullword", "nullword", "BNC词频"},
+	}
+	for _, tt := range tests {
+		detail, _, err := renderDetail(detailKey{word: tt.word})
+		if err != nil {
+			t.Errorf("renderDetail(%s) 出错: %v", tt.word, err)
+			continue
+		}
+		if !strings.Contains(detail, tt.want) || strings.Contains(detail, tt.without) {
+			t.Errorf("%s 的详情应包含 %q、不包含 %q:\n%s", tt.word, tt.want, tt.without, detail)
+		}
+		w, err := lookupEnglishWord(tt.word)
+		if err != nil {
+			t.Errorf("lookupEnglishWord(%s) 出错: %v", tt.word, err)
+		} else if w.Phonetic != "" || w.Bnc != "" {
+			t.Errorf("lookupEnglishWord(%s) 的音标 %q、词频 %q 应为空", tt.word, w.Phonetic, w.Bnc)
+		}
+	}
+
+	// 搜索和随机抽取也能读出这些行
+	results, err := searchEnglish("nullword")
+	if err != nil || len(results) == 0 || results[0].Word != "nullword" {
+		t.Errorf("searchEnglish(nullword) = %v, %v", wordsOf(results), err)
+	}
+	if _, err := RandomWords(1000); err != nil {
+		t.Errorf("RandomWords 读取含 NULL 的行时出错: %v", err)
+	}
+}
+
+// TestSearchChineseCharIndexPlan 中文包含匹配应通过汉字倒排索引查找，不能退化为扫描整个 chinese_words 表
+func TestSearchChineseCharIndexPlan(t *testing.T) {
+	useFixtureDatabases(t)
+	if !hasChineseCharIndex {
+		t.Fatal("测试词典生成的中文数据库应有汉字倒排索引")
+	}
+
+	rows, err := chineseDB.Query(`EXPLAIN QUERY PLAN `+chineseCharContainsSQL, "家", "%家%", "家%", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var plan []string
+	for rows.Next() {
+		var id, parent, notused int
+		var detail string
+		if err := rows.Scan(&id, &parent, &notused, &detail); err != nil {
+			t.Fatal(err)
+		}
+		plan = append(plan, detail)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	text := strings.Join(plan, "\n")
+	// chinese_chars 按 ch 查找，chinese_words 按主键逐行取出
+	if !strings.Contains(text, "SEARCH cc") || !strings.Contains(text, "SEARCH c ") || strings.Contains(text, "SCAN") {
+		t.Errorf("包含匹配的查询计划没有使用汉字倒排索引:\n%s", text)
+	}
+}
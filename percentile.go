@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// BNC 词频的显示方式，由 config.BNCDisplay 设置
+const (
+	bncDisplayRank       = "rank"       // 原始排名，如 3421
+	bncDisplayPercentile = "percentile" // 百分位，如「比 78% 的词更常用」
+	bncDisplayBoth       = "both"       // 排名后附上百分位
+)
+
+// bncDisplay 当前的 BNC 词频显示方式
+var bncDisplay = bncDisplayRank
+
+// hasBNCPercentiles 英文数据库中是否有生成时预先计算的百分位表（旧版本数据库没有）
+var hasBNCPercentiles bool
+
+// validateBNCDisplay 检查 BNC 词频显示方式是否有效
+func validateBNCDisplay(mode string) error {
+	switch mode {
+	case bncDisplayRank, bncDisplayPercentile, bncDisplayBoth:
+		return nil
+	}
+	return fmt.Errorf("bncDisplay 只能是 %s、%s 或 %s", bncDisplayRank, bncDisplayPercentile, bncDisplayBoth)
+}
+
+// buildBNCPercentiles 根据 words 表中全部有词频的单词计算百分位的分界，写入 bnc_percentiles 表
+//
+// 第 p 行的 bnc 是「比 p% 的有词频单词更常用」能达到的最大排名，查询时只需在 99 行中找到满足条件的最大 p，
+// 不必在运行时统计几万个单词的分布
+func buildBNCPercentiles(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, `
+	-- BNC 排名的百分位分界，用于把排名显示为「比 p% 的词更常用」
+	CREATE TABLE IF NOT EXISTS bnc_percentiles (
+		percent INTEGER PRIMARY KEY,
+		bnc INTEGER NOT NULL
+	);
+	DELETE FROM bnc_percentiles;
+	`)
+	if err != nil {
+		return fmt.Errorf("无法创建百分位表: %v", err)
+	}
+
+	rows, err := db.QueryContext(ctx, `SELECT CAST(bnc AS INTEGER) AS rank FROM words WHERE rank > 0 ORDER BY rank`)
+	if err != nil {
+		return fmt.Errorf("无法读取词频: %v", err)
+	}
+	var ranks []int
+	for rows.Next() {
+		var rank int
+		if err := rows.Scan(&rank); err == nil {
+			ranks = append(ranks, rank)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("无法读取词频: %v", err)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("无法开始事务: %v", err)
+	}
+	n := len(ranks)
+	for p := 1; p < 100; p++ {
+		// 排名为 ranks[i] 的单词之后还有 n-1-i 个词，至少要占 p%
+		i := n - 1 - (p*n+99)/100
+		if i < 0 {
+			break
+		}
+		if _, err := tx.Exec(`INSERT INTO bnc_percentiles (percent, bnc) VALUES (?, ?)`, p, ranks[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("无法写入百分位: %v", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("无法写入百分位: %v", err)
+	}
+	return nil
+}
+
+// bncPercentile 返回排名为 bnc 的单词比百分之几的有词频单词更常用，没有词频或数据库中没有百分位表时返回 -1
+func bncPercentile(bnc string) int {
+	rank := parseBNC(bnc)
+	if !hasBNCPercentiles || rank == parseBNC("") {
+		return -1
+	}
+	ctx, cancel := queryContext()
+	defer cancel()
+	var p int
+	if err := englishDB.QueryRowContext(ctx, `SELECT COALESCE(MAX(percent), 0) FROM bnc_percentiles WHERE bnc >= ?`, rank).Scan(&p); err != nil {
+		return -1
+	}
+	return p
+}
+
+// formatBNC 按 bncDisplay 返回 BNC 词频的显示文本，没有词频时返回空字符串
+//
+// 数据库中没有百分位表时总是显示原始排名
+func formatBNC(bnc string) string {
+	if bnc == "" || bnc == "0" {
+		return ""
+	}
+	if bncDisplay == bncDisplayRank {
+		return bnc
+	}
+	p := bncPercentile(bnc)
+	if p < 0 {
+		return bnc
+	}
+	text := fmt.Sprintf("比 %d%% 的词更常用", p)
+	if p == 0 {
+		text = "属于最少见的 1% 的词"
+	}
+	if bncDisplay == bncDisplayBoth {
+		return bnc + "（" + text + "）"
+	}
+	return text
+}
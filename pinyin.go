@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// pinyinTableFile 可选的汉字拼音表，存在时生成中文数据库会为每个中文词写入拼音
+//
+// 每行一个汉字，支持两种格式（# 开头的行为注释）：
+//
+//	中 zhōng
+//	U+4E2D	kMandarin	zhōng
+//
+// 第二种即 Unihan_Readings.txt 中的 kMandarin 行，多个读音时取第一个。
+const pinyinTableFile = "pinyin.txt"
+
+// pinyinReplacer 把带声调的字母替换为无声调形式，ü 统一写作 v
+var pinyinReplacer = strings.NewReplacer(
+	"ā", "a", "á", "a", "ǎ", "a", "à", "a",
+	"ē", "e", "é", "e", "ě", "e", "è", "e",
+	"ī", "i", "í", "i", "ǐ", "i", "ì", "i",
+	"ō", "o", "ó", "o", "ǒ", "o", "ò", "o",
+	"ū", "u", "ú", "u", "ǔ", "u", "ù", "u",
+	"ǖ", "v", "ǘ", "v", "ǚ", "v", "ǜ", "v", "ü", "v", "u:", "v",
+	"ń", "n", "ň", "n", "ǹ", "n", "ḿ", "m",
+)
+
+// normalizePinyin 去除声调符号、声调数字、空格和隔音符号，
+// 使 "pinyin"、"pin1 yin1"、"pīnyīn" 都规范化为 "pinyin"
+func normalizePinyin(s string) string {
+	s = pinyinReplacer.Replace(strings.ToLower(s))
+
+	var b strings.Builder
+	for _, r := range s {
+		if r >= 'a' && r <= 'z' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// isPinyinQuery 判断输入是否可能是拼音（只含字母、声调符号、声调数字、空格和隔音符号）
+func isPinyinQuery(s string) bool {
+	s = pinyinReplacer.Replace(strings.ToLower(s))
+	hasLetter := false
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z':
+			hasLetter = true
+		case r >= '0' && r <= '5', r == ' ', r == '\'':
+		default:
+			return false
+		}
+	}
+	return hasLetter
+}
+
+// loadPinyinTable 读取汉字拼音表，返回汉字到规范化拼音的映射
+func loadPinyinTable(path string) (map[rune]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("无法打开拼音表: %v", err)
+	}
+	defer file.Close()
+
+	table := make(map[rune]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		reading := fields[1]
+		if fields[1] == "kMandarin" {
+			if len(fields) < 3 {
+				continue
+			}
+			reading = fields[2]
+		}
+
+		var ch rune
+		if strings.HasPrefix(fields[0], "U+") {
+			code, err := strconv.ParseInt(fields[0][2:], 16, 32)
+			if err != nil {
+				continue
+			}
+			ch = rune(code)
+		} else {
+			runes := []rune(fields[0])
+			if len(runes) != 1 {
+				continue
+			}
+			ch = runes[0]
+		}
+
+		if _, exists := table[ch]; !exists {
+			table[ch] = normalizePinyin(reading)
+		}
+	}
+	return table, scanner.Err()
+}
+
+// termPinyin 根据拼音表计算中文词的规范化拼音，有汉字查不到读音时返回空字符串
+func termPinyin(term string, table map[rune]string) string {
+	var b strings.Builder
+	for _, r := range term {
+		if !unicode.Is(unicode.Han, r) {
+			continue
+		}
+		p, ok := table[r]
+		if !ok || p == "" {
+			return ""
+		}
+		b.WriteString(p)
+	}
+	return b.String()
+}
+
+// searchPinyin 按规范化拼音精确匹配中文词
+func searchPinyin(query string) ([]SearchResult, error) {
+	normalized := normalizePinyin(query)
+	if normalized == "" {
+		return nil, nil
+	}
+
+	seen := make(map[string]bool)
+	return collectMatches(chineseDB, MatchPinyin, nil, seen,
+		`SELECT chinese FROM chinese_words WHERE pinyin = ? LIMIT ?`, normalized, searchLimit)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNormalizePinyin(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+		isPin bool // isPinyinQuery 的结果
+	}{
+		{"pinyin", "pinyin", true},
+		{"pin1yin1", "pinyin", true},
+		{"pin1 yin1", "pinyin", true},
+		{"pīnyīn", "pinyin", true},
+		{"PīnYīn", "pinyin", true},
+		{"xi'an", "xian", true},
+		{"lǜ", "lv", true},
+		{"nu:3", "nv", true},
+		{"ping2guo3", "pingguo", true},
+		{"pin9yin", "pinyin", false},
+		{"apple-pie", "applepie", false},
+		{"苹果", "", false},
+		{"123", "", false},
+	}
+	for _, tt := range tests {
+		if got := normalizePinyin(tt.query); got != tt.want {
+			t.Errorf("normalizePinyin(%q) = %q，应为 %q", tt.query, got, tt.want)
+		}
+		if got := isPinyinQuery(tt.query); got != tt.isPin {
+			t.Errorf("isPinyinQuery(%q) = %v，应为 %v", tt.query, got, tt.isPin)
+		}
+	}
+}
+
+// writePinyinTable 在 dir 中写入包含 苹、果、家 读音的拼音表，两种格式都有
+func writePinyinTable(t *testing.T, dir string) string {
+	t.Helper()
+	file := filepath.Join(dir, pinyinTableFile)
+	content := "# 测试用拼音表\n苹 píng\n果 guǒ\nU+5BB6\tkMandarin\tjiā\n家 gū\n"
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestLoadPinyinTable(t *testing.T) {
+	table, err := loadPinyinTable(writePinyinTable(t, t.TempDir()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 第一个读音优先
+	want := map[rune]string{'苹': "ping", '果': "guo", '家': "jia"}
+	if len(table) != len(want) {
+		t.Errorf("拼音表有 %d 个汉字，应为 %d 个: %v", len(table), len(want), table)
+	}
+	for ch, p := range want {
+		if table[ch] != p {
+			t.Errorf("%c 的拼音为 %q，应为 %q", ch, table[ch], p)
+		}
+	}
+
+	for term, want := range map[string]string{"苹果": "pingguo", "苹果（水果）": "", "国家": "", "苹·果": "pingguo"} {
+		if got := termPinyin(term, table); got != want {
+			t.Errorf("termPinyin(%q) = %q，应为 %q", term, got, want)
+		}
+	}
+}
+
+func TestSearchPinyin(t *testing.T) {
+	// 生成中文数据库时从当前目录读取拼音表
+	csvFile, err := filepath.Abs(fixtureCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	writePinyinTable(t, dir)
+	t.Chdir(dir)
+
+	config = defaultConfig()
+	english, chinese, err := openMemoryDatabases(csvFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer english.Close()
+	defer chinese.Close()
+	useDatabases(english, chinese)
+
+	for _, query := range []string{"pingguo", "ping2guo3", "píngguǒ", "ping guo"} {
+		results, err := searchPinyin(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, ok := matchOf(results, "苹果"); !ok || got != MatchPinyin {
+			t.Errorf("searchPinyin(%q) = %v，应包含拼音匹配的 苹果", query, wordsOf(results))
+		}
+	}
+	if results, err := searchPinyin("jia"); err != nil || len(results) != 0 {
+		t.Errorf("searchPinyin(jia) = %v, %v；国家 的 国 没有读音，不应有拼音", wordsOf(results), err)
+	}
+}
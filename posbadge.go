@@ -0,0 +1,62 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// 词性徽标的分类，对应主题中的颜色
+const (
+	posNoun      = "noun"
+	posVerb      = "verb"
+	posAdjective = "adjective"
+	posAdverb    = "adverb"
+	posOther     = "other"
+)
+
+// definitionPOSRegex 匹配英文释义开头的词性标记，ECDICT 沿用 WordNet 的写法：n、v、a、s（卧位形容词）、r（副词），有时带点
+var definitionPOSRegex = regexp.MustCompile(`^(n|v|a|s|r|adj|adv)\.?\s+`)
+
+// posCategory 根据词性标记（n.、vt.& vi.、adj. 或英文释义中的 n、r）判断属于哪一类
+func posCategory(marker string) string {
+	first, _, _ := strings.Cut(strings.TrimSpace(marker), ".")
+	switch first {
+	case "n", "pl":
+		return posNoun
+	case "v", "vt", "vi", "aux":
+		return posVerb
+	case "a", "adj", "s":
+		return posAdjective
+	case "adv", "r":
+		return posAdverb
+	}
+	return posOther
+}
+
+// posBadge 渲染中文释义中的词性标记：启用 posBadges 时按词性显示为彩色底色的徽标，
+// 否则与领域标记（[计]）一样只改变文字颜色
+func posBadge(marker string) string {
+	if !config.PosBadges || !posMarkerRegex.MatchString(marker) {
+		return "[darkcyan]" + marker + "[-]"
+	}
+	return "[black:" + config.Theme.posColor(posCategory(marker)) + "]" + marker + "[-:-]"
+}
+
+// definitionLines 渲染英文释义，启用 posBadges 时把每行开头的词性标记显示为彩色徽标
+func definitionLines(definition string) []string {
+	lines := bulletLines(definition)
+	if !config.PosBadges {
+		return lines
+	}
+	const bullet = "  [green]•[-] "
+	for i, line := range lines {
+		text := strings.TrimPrefix(line, bullet)
+		m := definitionPOSRegex.FindStringSubmatch(text)
+		if m == nil {
+			continue
+		}
+		badge := "[black:" + config.Theme.posColor(posCategory(m[1])) + "]" + m[1] + "[-:-]"
+		lines[i] = bullet + badge + " " + text[len(m[0]):]
+	}
+	return lines
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// progressVersion 学习进度文件的格式版本，格式不兼容地修改时递增
+const progressVersion = 1
+
+// progressBundle 学习进度文件的内容：把 userdata 中分散的各个文件合并为一个，便于迁移到其他电脑
+type progressBundle struct {
+	Version   int            `json:"version"`
+	Exported  time.Time      `json:"exported"`  // 导出时间
+	Profile   string         `json:"profile"`   // 导出时使用的档案，仅供参考，导入到当前档案
+	History   []string       `json:"history"`   // 搜索历史，最近的在前
+	Favorites []string       `json:"favorites"` // 收藏，按收藏时间先后排列
+	Lookups   map[string]int `json:"lookups"`   // 每个单词的查阅次数
+	Review    reviewState    `json:"review"`    // 测验的复习安排，较早版本导出的文件中没有
+
+	StudyLists   map[string][]string `json:"studyLists,omitempty"`   // 学习列表，较早版本导出的文件中没有
+	FirstLookups map[string]string   `json:"firstLookups,omitempty"` // 每个单词首次查询的日期，较早版本导出的文件中没有
+}
+
+// transferProgress 执行 --export-progress 和 --import-progress，两者同时指定时先导入再导出
+func transferProgress(exportFile, importFile string) error {
+	if err := loadState(); err != nil {
+		return fmt.Errorf("读取用户数据失败: %v", err)
+	}
+	if importFile != "" {
+		summary, err := importProgress(importFile)
+		if err != nil {
+			return err
+		}
+		consolePrintf("✅ 已从 %s 导入学习进度：%s\n", importFile, summary)
+	}
+	if exportFile != "" {
+		if err := exportProgress(exportFile); err != nil {
+			return err
+		}
+		consolePrintf("✅ 学习进度已导出到 %s\n", exportFile)
+	}
+	return nil
+}
+
+// exportProgress 把当前档案的全部用户数据写入 path（需先调用 loadState）
+func exportProgress(path string) error {
+	bundle := progressBundle{
+		Version:   progressVersion,
+		Exported:  time.Now(),
+		Profile:   profileName,
+		History:   getSearchHistory(),
+		Favorites: getFavorites(),
+		Lookups:   getLookupCounts(),
+		Review:    getReviewState(),
+
+		StudyLists:   getStudyLists().Lists,
+		FirstLookups: getFirstLookups(),
+	}
+	return writeJSONFileAtomic(path, bundle)
+}
+
+// importProgress 读取 path 中的学习进度，与当前档案的数据合并后保存（需先调用 loadState），返回合并结果的说明
+//
+// 合并规则：收藏取并集，新的追加在已有收藏之后；历史记录保留本机的顺序，
+// 导入的记录中本机没有的排在后面，超出 historySize 的部分丢弃；查阅次数取两边的较大值；
+// 复习安排只加入本机没有的单词，已有的保留本机的进度；学习列表只加入本机没有的列表，同名列表保留本机的。
+// 因此同一个文件导入多次与导入一次的结果相同
+func importProgress(path string) (string, error) {
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("无法读取 %s: %v", path, err)
+	}
+	var bundle progressBundle
+	if err := readJSONFile(path, &bundle); err != nil {
+		return "", err
+	}
+	if bundle.Version == 0 {
+		return "", fmt.Errorf("%s 不是学习进度文件", path)
+	}
+	if bundle.Version > progressVersion {
+		return "", fmt.Errorf("%s 由更新版本的程序导出（格式版本 %d），请先升级", path, bundle.Version)
+	}
+
+	favoritesMutex.Lock()
+	addedFavorites := 0
+	for _, w := range bundle.Favorites {
+		if !containsWord(favorites, w) {
+			favorites = append(favorites, w)
+			addedFavorites++
+		}
+	}
+	favoritesMutex.Unlock()
+
+	historyMutex.Lock()
+	addedHistory := 0
+	for _, w := range bundle.History {
+		if len(searchHistory) >= maxHistorySize {
+			break
+		}
+		if !containsWord(searchHistory, w) {
+			searchHistory = append(searchHistory, w)
+			addedHistory++
+		}
+	}
+	historyMutex.Unlock()
+
+	lookupMutex.Lock()
+	updatedLookups := 0
+	for w, c := range bundle.Lookups {
+		if c > lookupCounts[w] {
+			lookupCounts[w] = c
+			updatedLookups++
+		}
+	}
+	updatedDates := mergeFirstLookups(bundle.FirstLookups)
+	lookupMutex.Unlock()
+
+	reviewMutex.Lock()
+	addedCards := 0
+	for w, c := range bundle.Review.Cards {
+		if _, ok := reviewCards[w]; !ok {
+			reviewCards[w] = c
+			addedCards++
+		}
+	}
+	reviewMutex.Unlock()
+
+	studyListsMutex.Lock()
+	addedLists := 0
+	for name, words := range bundle.StudyLists {
+		if _, ok := studyLists[name]; !ok {
+			studyLists[name] = words
+			addedLists++
+		}
+	}
+	studyListsMutex.Unlock()
+
+	markStateDirty()
+	if err := saveState(); err != nil {
+		return "", fmt.Errorf("保存用户数据失败: %v", err)
+	}
+	return fmt.Sprintf("新增 %d 个收藏、%d 条历史记录、%d 个复习单词、%d 个学习列表，更新 %d 个单词的查阅次数、%d 个单词的首次查询日期",
+		addedFavorites, addedHistory, addedCards, addedLists, updatedLookups, updatedDates), nil
+}
+
+// containsWord 判断 list 中是否有 word
+func containsWord(list []string, word string) bool {
+	for _, w := range list {
+		if w == word {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"unicode/utf8"
+
+	"github.com/rivo/tview"
+	"golang.org/x/term"
+)
+
+// ProgressStyle 生成数据库和下载词典数据时进度条的样式（配置文件中的 progressBar）
+type ProgressStyle struct {
+	Width  int    `json:"width"`  // 进度条格数，0 表示按终端宽度自动调整
+	Fill   string `json:"fill"`   // 已完成部分的字符
+	Empty  string `json:"empty"`  // 未完成部分的字符
+	Color  bool   `json:"color"`  // 已完成部分显示为绿色（输出被重定向或纯文本模式下不着色）
+	Redraw string `json:"redraw"` // 刷新方式：auto、overwrite（在同一行重绘）或 lines（每 10% 输出一行）
+}
+
+// 进度条的刷新方式
+const (
+	redrawAuto      = "auto"      // 终端中在同一行重绘，输出被重定向或终端不支持回车覆盖（TERM=dumb）时逐行输出
+	redrawOverwrite = "overwrite" // 总是用 \r 在同一行重绘
+	redrawLines     = "lines"     // 总是逐行输出，适合把回车显示为换行或乱码的终端和日志
+)
+
+// 自动调整宽度时进度条的格数范围
+const (
+	progressMinWidth = 10
+	progressMaxWidth = 50
+)
+
+// progressLineStep 逐行输出时每隔多少个百分点输出一行
+const progressLineStep = 10
+
+// progressSuffixWidth 进度条右边百分比和剩余时间占用的宽度（见 progressBar.draw）
+const progressSuffixWidth = len(" [] 100% ") + 16
+
+// defaultProgressStyle 返回默认的进度条样式
+func defaultProgressStyle() ProgressStyle {
+	return ProgressStyle{Fill: "█", Empty: " ", Color: true, Redraw: redrawAuto}
+}
+
+// validate 检查格数和刷新方式，填充字符必须是单个字符
+func (s ProgressStyle) validate() error {
+	if s.Width < 0 {
+		return fmt.Errorf("progressBar.width 不能小于 0")
+	}
+	for name, value := range map[string]string{"fill": s.Fill, "empty": s.Empty} {
+		if utf8.RuneCountInString(value) != 1 || tview.TaggedStringWidth(value) != 1 {
+			return fmt.Errorf("progressBar.%s 必须是一个半角宽度的字符", name)
+		}
+	}
+	switch s.Redraw {
+	case redrawAuto, redrawOverwrite, redrawLines:
+		return nil
+	}
+	return fmt.Errorf("progressBar.redraw 只能是 %s、%s 或 %s", redrawAuto, redrawOverwrite, redrawLines)
+}
+
+// overwrite 返回进度条是否在同一行重绘
+func (s ProgressStyle) overwrite() bool {
+	switch s.Redraw {
+	case redrawOverwrite:
+		return true
+	case redrawLines:
+		return false
+	}
+	return term.IsTerminal(int(os.Stdout.Fd())) && os.Getenv("TERM") != "dumb"
+}
+
+// colored 返回是否给已完成部分着色
+func (s ProgressStyle) colored() bool {
+	return s.Color && !plainOutput && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// barWidth 返回进度条的格数：配置了 width 时直接使用，否则让说明文字、进度条和百分比正好放进终端的一行
+func (s ProgressStyle) barWidth(label string) int {
+	if s.Width > 0 {
+		return s.Width
+	}
+	columns, _, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		return progressMaxWidth
+	}
+	width := columns - tview.TaggedStringWidth(consoleText(label)) - progressSuffixWidth
+	return max(progressMinWidth, min(width, progressMaxWidth))
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strings"
+)
+
+const (
+	quizChoices = 4     // 每道题的选项数
+	quizMaxBNC  = 10000 // 只用 BNC 词频排名在此之内的常用词出题
+	quizClueMax = 3     // 题面最多显示的释义行数
+)
+
+// lowerWordRegex 只由小写字母组成的单词，出题、干扰项和反义词推测都只处理这类单词，排除缩写、词组和带连字符的词
+var lowerWordRegex = regexp.MustCompile(`^[a-z]+$`)
+
+// quizNameRegex ECDICT 中小写的人名和地名（如 wilkinson）不算专有名词，但不适合出题
+var quizNameRegex = regexp.MustCompile(`姓氏|人名|男子名|女子名|男名|女名|地名`)
+
+// quizUsable 判断单词能否用于出题或作干扰项
+func quizUsable(w Word) bool {
+	return lowerWordRegex.MatchString(w.Word) && isChinese(w.Translation) && !quizNameRegex.MatchString(w.Translation)
+}
+
+// quizQuestion 一道选择题：根据中文释义从四个英文单词中选出正确的一个
+type quizQuestion struct {
+	Word    Word     // 正确答案
+	Clue    []string // 题面：遮住答案单词后的中文释义，每行一个词性
+	Choices []string // 选项，其中一个是 Word.Word
+	Answer  int      // 正确选项在 Choices 中的下标
+	Review  bool     // 是到期复习的单词，而不是新词
+}
+
+// errQuizDone 今天没有到期的复习、新词也已达到 newWordsPerDay 时 newQuizQuestion 返回的错误
+var errQuizDone = errors.New("今天的测验已完成")
+
+// newQuizQuestion 出下一道题：先复习到期的单词，没有到期的单词时再抽取新的常用词，
+// 每天的新词不超过 newWordsPerDay 个。干扰项取词频、长度和词性相近的单词。
+// 指定了学习列表（--study）时只用列表中的单词出题
+func newQuizQuestion() (quizQuestion, error) {
+	if studyListName != "" {
+		return newStudyListQuestion(studyListName)
+	}
+	for _, word := range dueReviews() {
+		// 重新生成数据库后单词可能已经不存在，跳过即可
+		w, err := lookupEnglishWord(word)
+		if err != nil || !quizUsable(w) {
+			continue
+		}
+		q, ok, err := buildQuizQuestion(w)
+		if err != nil {
+			return quizQuestion{}, err
+		}
+		if ok {
+			q.Review = true
+			return q, nil
+		}
+	}
+
+	if newWordsRemaining() == 0 {
+		return quizQuestion{}, errQuizDone
+	}
+	candidates, err := RandomWords(10, WithBNCRange(1, quizMaxBNC), WithLength(3, 0), WithoutProperNouns())
+	if err != nil {
+		return quizQuestion{}, err
+	}
+	for _, word := range candidates {
+		if !quizUsable(word) || hasReviewCard(word.Word) {
+			continue
+		}
+		q, ok, err := buildQuizQuestion(word)
+		if err != nil {
+			return quizQuestion{}, err
+		}
+		if ok {
+			return q, nil
+		}
+	}
+	return quizQuestion{}, fmt.Errorf("没有找到合适的测验单词")
+}
+
+// newStudyListQuestion 用学习列表中的单词出题：先复习列表中到期的单词，再按列表顺序出还没有学过的单词
+//
+// 列表是用户自己选的，新词不受 newWordsPerDay 限制，也不要求是常用词；没有中文释义或找不到干扰项的单词会被跳过。
+// 列表中的单词都学过且没有到期的复习时返回 errQuizDone
+func newStudyListQuestion(name string) (quizQuestion, error) {
+	words, ok := studyListWords(name)
+	if !ok {
+		return quizQuestion{}, checkStudyList(name)
+	}
+	inList := make(map[string]bool, len(words))
+	for _, word := range words {
+		inList[word] = true
+	}
+
+	var candidates []string
+	review := make(map[string]bool)
+	for _, word := range dueReviews() {
+		if inList[word] {
+			candidates = append(candidates, word)
+			review[word] = true
+		}
+	}
+	for _, word := range words {
+		if !hasReviewCard(word) {
+			candidates = append(candidates, word)
+		}
+	}
+
+	for _, word := range candidates {
+		w, err := lookupEnglishWord(word)
+		if err != nil || !isChinese(w.Translation) {
+			continue
+		}
+		q, ok, err := buildQuizQuestion(w)
+		if err != nil {
+			return quizQuestion{}, err
+		}
+		if ok {
+			q.Review = review[word]
+			return q, nil
+		}
+	}
+	return quizQuestion{}, errQuizDone
+}
+
+// studyListProgress 返回学习列表中已经在测验中出现过的单词数和总词数
+func studyListProgress(name string) (learned, total int) {
+	words, _ := studyListWords(name)
+	for _, word := range words {
+		if hasReviewCard(word) {
+			learned++
+		}
+	}
+	return learned, len(words)
+}
+
+// buildQuizQuestion 以 word 为答案出题，找不到足够的干扰项时 ok 为 false
+func buildQuizQuestion(word Word) (q quizQuestion, ok bool, err error) {
+	distractors, err := quizDistractors(word, quizChoices-1)
+	if err != nil || len(distractors) < quizChoices-1 {
+		return quizQuestion{}, false, err
+	}
+
+	q = quizQuestion{Word: word, Clue: quizClue(word), Choices: append(distractors, word.Word)}
+	rand.Shuffle(len(q.Choices), func(i, j int) {
+		q.Choices[i], q.Choices[j] = q.Choices[j], q.Choices[i]
+	})
+	for i, c := range q.Choices {
+		if c == word.Word {
+			q.Answer = i
+		}
+	}
+	return q, true, nil
+}
+
+// quizDistractors 为 target 挑选 n 个干扰项
+//
+// 先在词频相差一倍以内、长度相差不超过两个字母且词性相同的单词中挑选，不够时逐步放宽条件。
+// 与答案同形（如 apples 和 apple）或中文释义相同的单词会被跳过，避免出现两个正确选项
+func quizDistractors(target Word, n int) ([]string, error) {
+	bnc := parseBNC(target.Bnc)
+	length := len(target.Word)
+	pos := firstPOS(target.Translation)
+
+	attempts := [][]RandomOption{
+		{WithBNCRange(bnc/2, bnc*2), WithLength(length-2, length+2)},
+		{WithBNCRange(bnc/2, bnc*2), WithLength(length-2, length+2)},
+		{WithBNCRange(bnc/4, bnc*4), WithLength(length-3, length+3)},
+		// 学习列表中可能有没有词频的单词，最后不再限制词频
+		{WithBNCRange(1, quizMaxBNC), WithLength(length-3, length+3)},
+	}
+	if pos != "" {
+		attempts[0] = append(attempts[0], WithPOS(pos))
+	}
+
+	seen := map[string]bool{target.Word: true}
+	var result []string
+	for _, opts := range attempts {
+		words, err := RandomWords(n*4, append(opts, WithoutProperNouns())...)
+		if err != nil {
+			return nil, err
+		}
+		for _, w := range words {
+			if len(result) == n {
+				return result, nil
+			}
+			if seen[w.Word] || !quizUsable(w) || w.Translation == target.Translation ||
+				sameWordFamily(w.Word, target.Word) {
+				continue
+			}
+			seen[w.Word] = true
+			result = append(result, w.Word)
+		}
+		if len(result) == n {
+			return result, nil
+		}
+	}
+	return result, nil
+}
+
+// sameWordFamily 判断两个单词是否只是词尾变化不同（如 apple 和 apples）
+func sameWordFamily(a, b string) bool {
+	for _, lemma := range lemmaCandidates(a) {
+		if lemma == b {
+			return true
+		}
+	}
+	for _, lemma := range lemmaCandidates(b) {
+		if lemma == a {
+			return true
+		}
+	}
+	return false
+}
+
+// firstPOS 返回中文释义第一行的词性（如 "n. 苹果" 返回 n），没有词性时返回空字符串
+func firstPOS(translation string) string {
+	line, _, _ := strings.Cut(translation, `\n`)
+	pos, _, ok := strings.Cut(line, ". ")
+	if !ok || pos == "" || strings.ContainsAny(pos, " [") {
+		return ""
+	}
+	return pos
+}
+
+// quizClue 生成题面：取中文释义的前几行，并把其中出现的答案单词替换为 ___
+func quizClue(word Word) []string {
+	mask := regexp.MustCompile(`(?i)` + regexp.QuoteMeta(word.Word))
+	var clue []string
+	for _, line := range strings.Split(word.Translation, `\n`) {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		clue = append(clue, mask.ReplaceAllString(line, "___"))
+		if len(clue) == quizClueMax {
+			break
+		}
+	}
+	return clue
+}
+
+// quizSession 一次测验的得分记录
+type quizSession struct {
+	asked   int    // 已回答的题数
+	correct int    // 答对的题数
+	missed  []Word // 答错的单词，测验结束时列出
+}
+
+// answer 记录一次作答，返回是否答对
+func (s *quizSession) answer(q quizQuestion, choice int) bool {
+	s.asked++
+	if choice == q.Answer {
+		s.correct++
+		return true
+	}
+	s.missed = append(s.missed, q.Word)
+	return false
+}
+
+// score 返回「答对 / 已答」形式的得分
+func (s *quizSession) score() string {
+	return fmt.Sprintf("%d / %d", s.correct, s.asked)
+}
+
+// summary 返回测验结束时的总结，如「共 10 题，答对 7 题（70%）」
+func (s *quizSession) summary() string {
+	if s.asked == 0 {
+		return "没有回答任何题目"
+	}
+	return fmt.Sprintf("共 %d 题，答对 %d 题（%d%%）", s.asked, s.correct, s.correct*100/s.asked)
+}
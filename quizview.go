@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+var (
+	quizView     *tview.Flex     // 测验界面的外层容器
+	quizText     *tview.TextView // 题面
+	quizList     *tview.List     // 选项列表
+	quizStatus   *tview.TextView // 测验界面的状态栏：得分、今天的进度和上一题的结果
+	quizCurrent  quizQuestion    // 当前题目
+	quizReady    bool            // 当前题目已加载，可以作答
+	quizScore    quizSession     // 本次测验的得分
+	quizVersion  int64           // 出题版本号，关闭测验或换题后丢弃旧的出题结果
+	quizFeedback string          // 上一题的结果，显示在得分之后
+)
+
+// newQuizView 创建测验界面：题面、四个选项和底部状态栏
+func newQuizView() *tview.Flex {
+	quizText = tview.NewTextView().
+		SetDynamicColors(true).
+		SetWordWrap(true)
+
+	quizList = tview.NewList().
+		ShowSecondaryText(false).
+		SetWrapAround(true).
+		SetMainTextColor(tcell.ColorWhite).
+		SetSelectedTextColor(tcell.ColorBlack).
+		SetSelectedBackgroundColor(tcell.ColorYellow)
+	quizList.SetSelectedFunc(func(index int, mainText string, secondaryText string, shortcut rune) {
+		answerQuiz(index)
+	})
+
+	quizStatus = tview.NewTextView().
+		SetDynamicColors(true)
+
+	hint := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText("[gray]1-4 或 Enter 作答 · Esc/" + keyLabel(actionQuiz) + " 结束测验[-]")
+
+	quizView = tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(quizText, 0, 1, false).
+		AddItem(quizList, quizChoices*2, 0, true).
+		AddItem(quizStatus, 1, 0, false).
+		AddItem(hint, 1, 0, false)
+	title := "单词测验：选出与释义对应的英文单词"
+	if studyListName != "" {
+		title = "单词测验（学习列表：" + tview.Escape(studyListName) + "）：选出与释义对应的英文单词"
+	}
+	quizView.SetBorder(true).SetTitle(title)
+	quizView.SetInputCapture(handleQuizKey)
+	return quizView
+}
+
+// showQuizView 开始一次新的测验
+func showQuizView() {
+	quizScore = quizSession{}
+	quizFeedback = ""
+	pages.ShowPage("quiz")
+	app.SetFocus(quizList)
+	nextQuizQuestion()
+}
+
+// closeQuizView 结束测验，回到主界面并在详情面板中显示本次测验的总结
+func closeQuizView() {
+	atomic.AddInt64(&quizVersion, 1)
+	quizReady = false
+	pages.HidePage("quiz")
+	app.SetFocus(searchInput)
+
+	clearDetail()
+	updateDetailTitle()
+	var b strings.Builder
+	b.WriteString("[yellow::b]测验结束[-::-]\n\n" + quizScore.summary() + "\n")
+	if len(quizScore.missed) > 0 {
+		b.WriteString("\n[yellow]答错的单词:[-]\n")
+		for _, w := range quizScore.missed {
+			b.WriteString(fmt.Sprintf("  [green]%s[-]  %s\n", tview.Escape(w.Word), tview.Escape(strings.Join(quizClue(w), "；"))))
+		}
+	}
+	detailView.SetText(b.String()).ScrollToBeginning()
+	setStatus("测验结束：" + quizScore.summary())
+}
+
+// nextQuizQuestion 在后台生成下一道题，生成完成后显示
+func nextQuizQuestion() {
+	version := atomic.AddInt64(&quizVersion, 1)
+	quizReady = false
+	quizList.Clear()
+	quizText.SetText("[gray]出题中...[-]")
+	updateQuizStatus()
+
+	go func() {
+		q, err := newQuizQuestion()
+		app.QueueUpdateDraw(func() {
+			if atomic.LoadInt64(&quizVersion) != version {
+				return
+			}
+			if err == errQuizDone && studyListName != "" {
+				quizText.SetText("[green]学习列表「" + tview.Escape(studyListName) + "」中的单词都已学过，今天没有到期的复习[-]\n\n" +
+					"[gray]答错的单词明天会再次出现，答对的按间隔加倍安排复习[-]")
+				return
+			}
+			if err == errQuizDone {
+				quizText.SetText(fmt.Sprintf("[green]今天的复习已完成，新词也已达到每日上限（%d 个）[-]\n\n"+
+					"[gray]明天再来，或在 config.json 中调高 newWordsPerDay[-]", config.NewWordsPerDay))
+				return
+			}
+			if err != nil {
+				quizText.SetText("[red]出题失败: " + tview.Escape(err.Error()) + "[-]")
+				return
+			}
+			showQuizQuestion(q)
+		})
+	}()
+}
+
+// showQuizQuestion 显示题面和选项
+func showQuizQuestion(q quizQuestion) {
+	quizCurrent = q
+	quizReady = true
+
+	var b strings.Builder
+	kind := "新词"
+	if q.Review {
+		kind = "复习"
+	}
+	b.WriteString(fmt.Sprintf("[yellow]第 %d 题[-] [gray]· %s[-]\n\n", quizScore.asked+1, kind))
+	for _, line := range q.Clue {
+		b.WriteString(tview.Escape(line) + "\n")
+	}
+	quizText.SetText(b.String()).ScrollToBeginning()
+
+	quizList.Clear()
+	for i, choice := range q.Choices {
+		quizList.AddItem(tview.Escape(choice), "", rune('1'+i), nil)
+	}
+	quizList.SetCurrentItem(0)
+}
+
+// answerQuiz 记录对当前题目第 index 个选项的作答，然后出下一题
+func answerQuiz(index int) {
+	if !quizReady || index < 0 || index >= len(quizCurrent.Choices) {
+		return
+	}
+	q := quizCurrent
+	correct := quizScore.answer(q, index)
+	recordReview(q.Word.Word, correct)
+	if correct {
+		quizFeedback = "[green]✓ " + tview.Escape(q.Word.Word) + " 正确[-]"
+	} else {
+		quizFeedback = fmt.Sprintf("[red]✗ 答案是 %s，你选了 %s[-]",
+			tview.Escape(q.Word.Word), tview.Escape(q.Choices[index]))
+	}
+	nextQuizQuestion()
+}
+
+// updateQuizStatus 在测验界面的状态栏显示当前得分、今天的进度和上一题的结果
+func updateQuizStatus() {
+	text := fmt.Sprintf("得分: %s · 今日新词 %d/%d · 待复习 %d",
+		quizScore.score(), newWordsToday(), config.NewWordsPerDay, len(dueReviews()))
+	if studyListName != "" {
+		learned, total := studyListProgress(studyListName)
+		text = fmt.Sprintf("得分: %s · 列表已学 %d/%d", quizScore.score(), learned, total)
+	}
+	if quizFeedback != "" {
+		text += " · " + quizFeedback
+	}
+	quizStatus.SetText(text)
+}
+
+// handleQuizKey 处理测验界面中的按键
+func handleQuizKey(event *tcell.EventKey) *tcell.EventKey {
+	if event.Key() == tcell.KeyEsc || keyBindings[event.Key()] == actionQuiz {
+		closeQuizView()
+		return nil
+	}
+	return event
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RandomOption 限定 RandomWords 可以抽到哪些单词，多个条件需同时满足
+type RandomOption func(*randomQuery)
+
+// randomQuery RandomWords 的查询条件
+type randomQuery struct {
+	conds    []string      // SQL 条件，彼此以 AND 连接
+	args     []interface{} // 条件中的参数
+	needTags bool          // 条件用到了 tag 列，旧版本的数据库没有该列
+}
+
+// where 追加一个查询条件
+func (q *randomQuery) where(cond string, args ...interface{}) {
+	q.conds = append(q.conds, cond)
+	q.args = append(q.args, args...)
+}
+
+// WithBNCRange 只抽取 BNC 词频排名在 min 到 max 之间的单词（排名越小越常用），max 为 0 表示不限上限，
+// 没有词频的单词不会被抽到
+func WithBNCRange(min, max int) RandomOption {
+	return func(q *randomQuery) {
+		if min < 1 {
+			min = 1
+		}
+		q.where("CAST(bnc AS INTEGER) >= ?", min)
+		if max > 0 {
+			q.where("CAST(bnc AS INTEGER) <= ?", max)
+		}
+	}
+}
+
+// WithTag 只抽取带有指定考试标签的单词，标签取自 ECDICT 的 tag 列：zk（中考）、gk（高考）、cet4、cet6、ky（考研）、toefl、ielts、gre
+func WithTag(tag string) RandomOption {
+	return func(q *randomQuery) {
+		q.where(`(' ' || COALESCE(tag, '') || ' ') LIKE ? ESCAPE '\'`, "% "+escapeLike(strings.ToLower(tag))+" %")
+		q.needTags = true
+	}
+}
+
+// WithLength 只抽取长度在 min 到 max 个字母之间的单词，max 为 0 表示不限上限
+func WithLength(min, max int) RandomOption {
+	return func(q *randomQuery) {
+		if min > 0 {
+			q.where("length(word) >= ?", min)
+		}
+		if max > 0 {
+			q.where("length(word) <= ?", max)
+		}
+	}
+}
+
+// posAliases 词性在中文释义中的写法：ECDICT 的形容词多写作 a.，动词分为 vt. 和 vi.
+var posAliases = map[string][]string{
+	"adj": {"adj", "a"},
+	"v":   {"v", "vt", "vi"},
+}
+
+// WithPOS 只抽取中文释义中有指定词性（如 n、v、adj、adv）的单词
+func WithPOS(pos string) RandomOption {
+	return func(q *randomQuery) {
+		pos = strings.TrimSuffix(strings.ToLower(pos), ".")
+		prefixes, ok := posAliases[pos]
+		if !ok {
+			prefixes = []string{pos}
+		}
+		// 每个词性占释义的一行，行首为「词性. 」，行之间以字面的 \n 分隔
+		var ors []string
+		var args []interface{}
+		for _, p := range prefixes {
+			ors = append(ors, "translation LIKE ?", "translation LIKE ?")
+			args = append(args, p+". %", `%\n`+p+". %")
+		}
+		q.where("("+strings.Join(ors, " OR ")+")", args...)
+	}
+}
+
+// WithoutProperNouns 不抽取专有名词和缩写
+func WithoutProperNouns() RandomOption {
+	return func(q *randomQuery) {
+		q.where("NOT " + properNounSQL)
+	}
+}
+
+// RandomWords 从英文词库中随机抽取最多 n 个满足条件的单词，返回完整的单词信息
+//
+// 例如 RandomWords(5, WithTag("cet4"), WithPOS("adj"), WithBNCRange(1, 5000)) 抽取 5 个常用的四级形容词，
+// 可用于生成测验题或每日单词
+func RandomWords(n int, opts ...RandomOption) ([]Word, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("单词数必须大于 0")
+	}
+	if err := databaseReady(); err != nil {
+		return nil, err
+	}
+
+	q := &randomQuery{}
+	for _, opt := range opts {
+		opt(q)
+	}
+	if q.needTags && !hasTags {
+		return nil, fmt.Errorf("数据库中没有考试标签，重新生成数据库后才能按标签筛选")
+	}
+
+	query := `SELECT word, phonetic, definition, translation, bnc, '' FROM words`
+	if hasSources {
+		query = `SELECT word, phonetic, definition, translation, bnc, COALESCE(s.name, '')
+		         FROM words LEFT JOIN sources s ON s.id = words.source_id`
+	}
+	if len(q.conds) > 0 {
+		query += " WHERE " + strings.Join(q.conds, " AND ")
+	}
+	query += " ORDER BY RANDOM() LIMIT ?"
+	args := append(q.args, n)
+
+	start := time.Now()
+	ctx, cancel := queryContext()
+	defer cancel()
+	rows, err := englishDB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, timeoutError(ctx, err)
+	}
+	defer rows.Close()
+
+	var words []Word
+	for rows.Next() {
+		w, err := scanWord(rows, false, true)
+		if err != nil {
+			continue
+		}
+		words = append(words, w)
+	}
+	logQuery("随机单词", start, len(words), query, args...)
+	return words, timeoutError(ctx, rows.Err())
+}
+
+// randomOptions 由命令行或 HTTP 请求中的文本参数构造 RandomWords 的条件，参数为空表示不限制
+//
+// bnc 和 length 写作范围，如 1-5000、4-8，省略一侧表示不限（如 5000- 或 -8）
+func randomOptions(bnc, tag, pos, length string) ([]RandomOption, error) {
+	var opts []RandomOption
+	if bnc != "" {
+		min, max, err := parseRange(bnc)
+		if err != nil {
+			return nil, fmt.Errorf("bnc %v", err)
+		}
+		opts = append(opts, WithBNCRange(min, max))
+	}
+	if tag != "" {
+		opts = append(opts, WithTag(tag))
+	}
+	if pos != "" {
+		opts = append(opts, WithPOS(pos))
+	}
+	if length != "" {
+		min, max, err := parseRange(length)
+		if err != nil {
+			return nil, fmt.Errorf("length %v", err)
+		}
+		opts = append(opts, WithLength(min, max))
+	}
+	return opts, nil
+}
+
+// parseRange 解析「最小值-最大值」形式的范围，省略的一侧返回 0
+func parseRange(text string) (min, max int, err error) {
+	lo, hi, ok := strings.Cut(text, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("范围 %q 格式错误，应写作 最小值-最大值（如 1-5000）", text)
+	}
+	parse := func(s string) (int, error) {
+		if s = strings.TrimSpace(s); s == "" {
+			return 0, nil
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("范围 %q 中的 %q 不是有效的数字", text, s)
+		}
+		return n, nil
+	}
+	if min, err = parse(lo); err != nil {
+		return 0, 0, err
+	}
+	if max, err = parse(hi); err != nil {
+		return 0, 0, err
+	}
+	if max > 0 && min > max {
+		return 0, 0, fmt.Errorf("范围 %q 的最小值大于最大值", text)
+	}
+	return min, max, nil
+}
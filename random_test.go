@@ -0,0 +1,93 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestProperNounSQL 用 ECDICT 的真实词条检查哪些词会被当作专有名词和缩写
+func TestProperNounSQL(t *testing.T) {
+	useFixtureDatabases(t)
+
+	tests := []struct {
+		word   string
+		proper bool
+	}{
+		{"NASA", true},     // 全部大写的缩写，虽然有中文翻译
+		{"Adamatic", true}, // 含大写字母且没有中文翻译
+		{"Paris", false},   // 首字母大写，但有中文翻译
+		{"Runciman", false},
+		{"Apple", false},
+		{"absotively", false}, // 没有中文翻译，但全是小写
+		{"apple", false},
+		{"don't", false},
+	}
+	for _, tt := range tests {
+		var n int
+		if err := englishDB.QueryRow(`SELECT COUNT(*) FROM words WHERE word = ? AND `+properNounSQL, tt.word).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		if got := n > 0; got != tt.proper {
+			t.Errorf("%s 被当作专有名词: %v，应为 %v", tt.word, got, tt.proper)
+		}
+	}
+}
+
+func TestRandomWordsWithoutProperNouns(t *testing.T) {
+	useFixtureDatabases(t)
+
+	// 抽取的数量多于测试词典的词条数，返回的就是全部符合条件的词
+	pick := func(opts ...RandomOption) []string {
+		t.Helper()
+		words, err := RandomWords(1000, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var result []string
+		for _, w := range words {
+			result = append(result, w.Word)
+		}
+		return result
+	}
+	all, filtered := pick(), pick(WithoutProperNouns())
+	for _, word := range []string{"NASA", "Adamatic"} {
+		if !slices.Contains(all, word) {
+			t.Errorf("不排除专有名词时应能抽到 %s", word)
+		}
+		if slices.Contains(filtered, word) {
+			t.Errorf("WithoutProperNouns 抽到了 %s", word)
+		}
+	}
+	if len(filtered) != len(all)-2 {
+		t.Errorf("WithoutProperNouns 抽到 %d 个词，应比全部的 %d 个少 2 个", len(filtered), len(all))
+	}
+}
+
+func TestSearchHideProperNouns(t *testing.T) {
+	tests := []struct {
+		query  string
+		word   string
+		hide   bool
+		listed bool
+	}{
+		{"na", "NASA", false, true},
+		{"na", "NASA", true, false},
+		{"ada", "Adamatic", true, false},
+		{"par", "Paris", true, true},
+		// 精确匹配不受影响
+		{"NASA", "NASA", true, true},
+		{"Adamatic", "Adamatic", true, true},
+	}
+	for _, tt := range tests {
+		useFixtureDatabases(t)
+		config.HideProperNounsInSearch = tt.hide
+		results, err := search(tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := matchOf(results, tt.word); ok != tt.listed {
+			t.Errorf("HideProperNounsInSearch 为 %v 时 search(%q) = %v，是否包含 %s 应为 %v",
+				tt.hide, tt.query, wordsOf(results), tt.word, tt.listed)
+		}
+	}
+}
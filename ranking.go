@@ -0,0 +1,62 @@
+package main
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	lookupCounts    = make(map[string]int) // 每个单词被查阅的次数，持久化在 userdata 中
+	lookupMutex     sync.Mutex             // 保护 lookupCounts 的并发访问
+	personalRanking atomic.Bool            // 是否按查阅次数调整同一匹配类型内的排序，界面中切换时搜索协程可能正在读取
+)
+
+// recordLookup 把单词的查阅次数加一
+func recordLookup(word string) {
+	lookupMutex.Lock()
+	lookupCounts[word]++
+	recordFirstLookup(word)
+	lookupMutex.Unlock()
+	markStateDirty()
+}
+
+// getLookupCount 返回单个单词的查阅次数
+func getLookupCount(word string) int {
+	lookupMutex.Lock()
+	defer lookupMutex.Unlock()
+	return lookupCounts[word]
+}
+
+// getLookupCounts 返回查阅次数的副本，用于保存
+func getLookupCounts() map[string]int {
+	lookupMutex.Lock()
+	defer lookupMutex.Unlock()
+
+	counts := make(map[string]int, len(lookupCounts))
+	for w, c := range lookupCounts {
+		counts[w] = c
+	}
+	return counts
+}
+
+// rankByLookups 在每个匹配类型分组内把查阅次数多的单词排到前面，
+// 次数相同的保持数据库返回的原有顺序
+func rankByLookups(results []SearchResult) {
+	lookupMutex.Lock()
+	defer lookupMutex.Unlock()
+
+	if len(lookupCounts) == 0 {
+		return
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Match != results[j].Match {
+			return results[i].Match < results[j].Match
+		}
+		if results[i].Match == MatchRank {
+			// 按排名范围列出的单词保持排名顺序
+			return false
+		}
+		return lookupCounts[results[i].Word] > lookupCounts[results[j].Word]
+	})
+}
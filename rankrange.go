@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// rankQueryPrefix 搜索框中按 BNC 排名范围列出单词的前缀，如 rank:2000-3000
+const rankQueryPrefix = "rank:"
+
+// rankedWord 按排名范围查到的单词
+type rankedWord struct {
+	word string
+	rank int
+}
+
+// parseRankQuery 解析 rank:最小值-最大值 形式的查询，不是这种查询时 ok 为 false；范围写错时返回错误
+func parseRankQuery(keyword string) (min, max int, ok bool, err error) {
+	if len(keyword) < len(rankQueryPrefix) || !strings.EqualFold(keyword[:len(rankQueryPrefix)], rankQueryPrefix) {
+		return 0, 0, false, nil
+	}
+	min, max, err = parseRankRange(strings.TrimSpace(keyword[len(rankQueryPrefix):]))
+	return min, max, true, err
+}
+
+// parseRankRange 解析 BNC 排名范围，写法同 parseRange（如 2000-3000、5000-、-100），最小值不足 1 时按 1 处理
+func parseRankRange(text string) (min, max int, err error) {
+	min, max, err = parseRange(text)
+	if err != nil {
+		return 0, 0, err
+	}
+	if min < 1 {
+		min = 1
+	}
+	return min, max, nil
+}
+
+// rankWords 按 BNC 排名从小到大列出排名在 min 到 max 之间的单词（max 为 0 表示不限上限），limit 不大于 0 时不限数量
+//
+// 条件和排序都写作 CAST(bnc AS INTEGER)，与 idx_bnc 索引的表达式一致，可以直接按索引顺序读取
+func rankWords(min, max, limit int) ([]rankedWord, error) {
+	if err := databaseReady(); err != nil {
+		return nil, err
+	}
+	query := `SELECT word, CAST(bnc AS INTEGER) FROM words WHERE CAST(bnc AS INTEGER) >= ?`
+	args := []interface{}{min}
+	if max > 0 {
+		query += ` AND CAST(bnc AS INTEGER) <= ?`
+		args = append(args, max)
+	}
+	query += properNounFilter(config.HideProperNounsInSearch) + ` ORDER BY CAST(bnc AS INTEGER), word`
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	start := time.Now()
+	ctx, cancel := queryContext()
+	defer cancel()
+	rows, err := englishDB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, timeoutError(ctx, err)
+	}
+	defer rows.Close()
+	var words []rankedWord
+	for rows.Next() {
+		var w rankedWord
+		if err := rows.Scan(&w.word, &w.rank); err == nil {
+			words = append(words, w)
+		}
+	}
+	logQuery("词频排名", start, len(words), query, args...)
+	return words, timeoutError(ctx, rows.Err())
+}
+
+// searchRankRange 执行 rank: 查询，结果按排名顺序归入「词频排名」分组
+func searchRankRange(min, max int) ([]SearchResult, error) {
+	words, err := rankWords(min, max, searchLimit)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]SearchResult, 0, len(words))
+	for _, w := range words {
+		results = append(results, SearchResult{Word: w.word, Match: MatchRank})
+	}
+	return results, nil
+}
+
+// runRankRange 执行 --rank：按排名顺序输出范围内的全部单词，附上简短释义
+func runRankRange(text string) error {
+	min, max, err := parseRankRange(text)
+	if err != nil {
+		return fmt.Errorf("-rank %v", err)
+	}
+	if err := openExistingDatabases(); err != nil {
+		return err
+	}
+	defer closeDatabases()
+
+	words, err := rankWords(min, max, 0)
+	if err != nil {
+		return err
+	}
+	if len(words) == 0 {
+		return fmt.Errorf("没有 BNC 排名在 %s 之间的单词", text)
+	}
+	for _, w := range words {
+		if isIgnored(w.word) {
+			continue
+		}
+		line := fmt.Sprintf("%6d  %-20s", w.rank, w.word)
+		if preview := previewWord(w.word); preview != "" {
+			line += "  " + preview
+		}
+		fmt.Println(strings.TrimRight(line, " "))
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// replQuitCommands 在 --repl 模式中退出的命令
+var replQuitCommands = map[string]bool{":q": true, "quit": true, "exit": true}
+
+// runREPL 逐行读取 in 中的查询并输出纯文本的查询结果，读到退出命令或输入结束（Ctrl+D）时返回
+//
+// 不使用 tview，适合 SSH 等交互界面显示不正常的环境；in 是终端时在每次输入前显示提示符
+func runREPL(in io.Reader) error {
+	prompt := ""
+	if f, ok := in.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		prompt = "> "
+		consolePrintln("输入单词查询，:q 或 Ctrl+D 退出")
+	}
+
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Print(prompt)
+		if !scanner.Scan() {
+			break
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if replQuitCommands[strings.ToLower(line)] {
+			return nil
+		}
+		query := normalizeQuery(line)
+		if query == "" {
+			continue
+		}
+		if err := printLookup(query); err != nil {
+			consolePrintf("❌ %v\n", err)
+		}
+		fmt.Println()
+	}
+	if prompt != "" {
+		fmt.Println()
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	reviewDateLayout  = "2006-01-02" // 复习日期的格式，按本地时间的自然日计算
+	maxReviewInterval = 180          // 复习间隔最长的天数
+)
+
+// reviewCard 测验中出过的一个单词的复习安排：答对后间隔加倍，答错后从第二天重新开始
+type reviewCard struct {
+	Interval int    `json:"interval"` // 当前的复习间隔（天），答错后为 0
+	Due      string `json:"due"`      // 下次复习的日期
+}
+
+// reviewState 复习文件的内容
+type reviewState struct {
+	Cards           map[string]reviewCard `json:"cards"`
+	IntroducedDate  string                `json:"introducedDate"`  // introducedCount 统计的日期
+	IntroducedCount int                   `json:"introducedCount"` // 这一天第一次出现在测验中的新词数量
+}
+
+var (
+	reviewCards     = make(map[string]reviewCard)
+	introducedDate  string
+	introducedCount int
+	reviewMutex     sync.Mutex
+)
+
+// reviewFile 返回复习文件路径
+func reviewFile() string {
+	return filepath.Join(stateDir(), "review.json")
+}
+
+// reviewDate 返回 t 之后 days 天的日期
+func reviewDate(t time.Time, days int) string {
+	return t.AddDate(0, 0, days).Format(reviewDateLayout)
+}
+
+// getReviewState 返回复习数据的副本，用于保存
+func getReviewState() reviewState {
+	reviewMutex.Lock()
+	defer reviewMutex.Unlock()
+	cards := make(map[string]reviewCard, len(reviewCards))
+	for w, c := range reviewCards {
+		cards[w] = c
+	}
+	return reviewState{Cards: cards, IntroducedDate: introducedDate, IntroducedCount: introducedCount}
+}
+
+// setReviewState 用读取到的复习文件替换内存中的复习数据
+func setReviewState(s reviewState) {
+	reviewMutex.Lock()
+	defer reviewMutex.Unlock()
+	reviewCards = make(map[string]reviewCard, len(s.Cards))
+	for w, c := range s.Cards {
+		reviewCards[w] = c
+	}
+	introducedDate = s.IntroducedDate
+	introducedCount = s.IntroducedCount
+}
+
+// dueReviews 返回今天到期需要复习的单词，逾期最久的在前
+func dueReviews() []string {
+	today := reviewDate(time.Now(), 0)
+	reviewMutex.Lock()
+	var due []string
+	dates := make(map[string]string)
+	for w, c := range reviewCards {
+		if c.Due <= today {
+			due = append(due, w)
+			dates[w] = c.Due
+		}
+	}
+	reviewMutex.Unlock()
+
+	sort.Slice(due, func(i, j int) bool {
+		if dates[due[i]] != dates[due[j]] {
+			return dates[due[i]] < dates[due[j]]
+		}
+		return due[i] < due[j]
+	})
+	return due
+}
+
+// hasReviewCard 判断单词是否已经在测验中出现过
+func hasReviewCard(word string) bool {
+	reviewMutex.Lock()
+	defer reviewMutex.Unlock()
+	_, ok := reviewCards[word]
+	return ok
+}
+
+// newWordsToday 返回今天已经出现的新词数量
+func newWordsToday() int {
+	reviewMutex.Lock()
+	defer reviewMutex.Unlock()
+	if introducedDate != reviewDate(time.Now(), 0) {
+		return 0
+	}
+	return introducedCount
+}
+
+// newWordsRemaining 返回今天还能出现的新词数量（newWordsPerDay 减去已经出现的）
+func newWordsRemaining() int {
+	if n := config.NewWordsPerDay - newWordsToday(); n > 0 {
+		return n
+	}
+	return 0
+}
+
+// recordReview 记录一次作答并安排下次复习，第一次出现的单词计入今天的新词数量
+func recordReview(word string, correct bool) {
+	now := time.Now()
+	reviewMutex.Lock()
+	card, seen := reviewCards[word]
+	if !seen {
+		today := reviewDate(now, 0)
+		if introducedDate != today {
+			introducedDate = today
+			introducedCount = 0
+		}
+		introducedCount++
+	}
+	if correct {
+		card.Interval *= 2
+		if card.Interval == 0 {
+			card.Interval = 1
+		} else if card.Interval > maxReviewInterval {
+			card.Interval = maxReviewInterval
+		}
+		card.Due = reviewDate(now, card.Interval)
+	} else {
+		card.Interval = 0
+		card.Due = reviewDate(now, 1)
+	}
+	reviewCards[word] = card
+	reviewMutex.Unlock()
+	markStateDirty()
+}
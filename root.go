@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// rootQueryPrefix 搜索框中按词根列出同根词的前缀，如 root:struct
+const rootQueryPrefix = "root:"
+
+// minRootQuery root: 查询的词根至少要有的字母数，更短的词根几乎出现在任何单词里
+const minRootQuery = 3
+
+// rootCandidateLimit root: 查询最多检查多少个含有词根的单词，词缀过滤之后通常只剩一小部分
+const rootCandidateLimit = 5000
+
+// rootPrefixes 同根词认可的前缀（construct、instruct、obstruct），最多可以叠加两个（reconstruct）
+var rootPrefixes = []string{
+	"a", "ab", "ad", "anti", "auto", "be", "bi", "co", "com", "con", "contra", "counter",
+	"de", "di", "dis", "em", "en", "ex", "extra", "fore", "hyper", "il", "im", "in", "infra",
+	"inter", "intra", "ir", "mal", "micro", "mis", "mono", "multi", "non", "ob", "out", "over",
+	"per", "post", "pre", "pro", "re", "se", "semi", "sub", "super", "sur", "trans", "ultra",
+	"un", "under",
+}
+
+// rootSuffixes 同根词认可的词尾：派生词的词尾（见 familySuffixes）再加上常跟在词根后的几个
+var rootSuffixes = append([]string{"or", "ee", "ative", "ure", "ural", "ivity", "ively"}, familySuffixes...)
+
+// parseRootQuery 解析 root:词根 形式的查询，不是这种查询时 ok 为 false；词根太短或含有字母以外的字符时返回错误
+func parseRootQuery(keyword string) (root string, ok bool, err error) {
+	if len(keyword) < len(rootQueryPrefix) || !strings.EqualFold(keyword[:len(rootQueryPrefix)], rootQueryPrefix) {
+		return "", false, nil
+	}
+	root = strings.ToLower(strings.TrimSpace(keyword[len(rootQueryPrefix):]))
+	if len(root) < minRootQuery || !lowerWordRegex.MatchString(root) {
+		return "", true, fmt.Errorf("词根必须是至少 %d 个英文字母，如 root:struct", minRootQuery)
+	}
+	return root, true, nil
+}
+
+// rootWord root: 查询中通过词缀过滤的单词
+type rootWord struct {
+	word     string
+	rank     int  // BNC 排名，没有排名时为 0
+	prefixed bool // 词根前面有前缀（construct），否则是词根加词尾（structure）
+}
+
+// searchRoot 执行 root: 查询：找出含有词根的单词，只保留能拆成「前缀 + 词根 + 词尾」的，
+// 词根本身作为精确匹配排在最前，其次是词根加词尾的派生词，最后是带前缀的同根词，同一组内按词频排列。
+// 词形变化（constructs、constructed）在原形也在结果中时不再单独列出
+func searchRoot(root string) ([]SearchResult, error) {
+	candidates, err := rootCandidates(root)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]bool, len(candidates))
+	for _, w := range candidates {
+		found[w.word] = true
+	}
+	var words []rootWord
+	exact := false
+	for _, w := range candidates {
+		if w.word == root {
+			exact = true
+			continue
+		}
+		if isInflectionOf(w.word, found) {
+			continue
+		}
+		if prefixed, ok := splitAffixes(w.word, root); ok {
+			w.prefixed = prefixed
+			words = append(words, w)
+		}
+	}
+
+	sort.SliceStable(words, func(i, j int) bool {
+		a, b := words[i], words[j]
+		if a.prefixed != b.prefixed {
+			return !a.prefixed
+		}
+		if (a.rank == 0) != (b.rank == 0) {
+			return a.rank != 0
+		}
+		if a.rank != b.rank {
+			return a.rank < b.rank
+		}
+		return a.word < b.word
+	})
+
+	var results []SearchResult
+	if exact {
+		results = append(results, SearchResult{Word: root, Match: MatchExact})
+	}
+	for _, w := range words {
+		if len(results) >= searchLimit {
+			break
+		}
+		match := MatchRootDerived
+		if w.prefixed {
+			match = MatchRootPrefixed
+		}
+		results = append(results, SearchResult{Word: w.word, Match: match})
+	}
+	return results, nil
+}
+
+// rootCandidates 查出含有 root 的全小写单词及其 BNC 排名，有三字符片段索引时用它缩小范围
+func rootCandidates(root string) ([]rootWord, error) {
+	filter := properNounFilter(config.HideProperNounsInSearch)
+	query := `SELECT word, COALESCE(CAST(bnc AS INTEGER), 0) FROM words WHERE word LIKE ? ESCAPE '\'` + filter + ` LIMIT ?`
+	args := []interface{}{"%" + escapeLike(root) + "%", rootCandidateLimit}
+	if hasTrigramIndex {
+		rarest, trigramArgs := rarestTrigram(wordTrigrams(root))
+		query = `SELECT word, COALESCE(CAST(bnc AS INTEGER), 0) FROM word_trigrams t JOIN words ON words.id = t.word_id
+		WHERE t.trigram = ` + rarest + ` AND word LIKE ? ESCAPE '\'` + filter + ` LIMIT ?`
+		args = append(trigramArgs, args...)
+	}
+
+	start := time.Now()
+	ctx, cancel := queryContext()
+	defer cancel()
+	rows, err := englishDB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, timeoutError(ctx, err)
+	}
+	defer rows.Close()
+	var words []rootWord
+	for rows.Next() {
+		var w rootWord
+		// LIKE 不区分大小写，专有名词和词组不参与词根分析
+		if err := rows.Scan(&w.word, &w.rank); err == nil && lowerWordRegex.MatchString(w.word) {
+			words = append(words, w)
+		}
+	}
+	logQuery("词根", start, len(words), query, args...)
+	return words, timeoutError(ctx, rows.Err())
+}
+
+// isInflectionOf 判断 word 是否是 found 中另一个单词的词形变化（见 lemmaCandidates）
+func isInflectionOf(word string, found map[string]bool) bool {
+	for _, lemma := range lemmaCandidates(word) {
+		if lemma != word && found[lemma] {
+			return true
+		}
+	}
+	return false
+}
+
+// splitAffixes 判断 word 能否拆成「前缀 + 词根 + 词尾」，前缀和词尾都可以为空但不能同时为空，
+// 词根末尾的 e、y 按常见拼写规则变化（produce → production、apply → appliance）；prefixed 表示有前缀
+func splitAffixes(word, root string) (prefixed bool, ok bool) {
+	stems := []string{root}
+	switch root[len(root)-1] {
+	case 'e':
+		stems = append(stems, root[:len(root)-1])
+	case 'y':
+		stems = append(stems, root[:len(root)-1]+"i")
+	}
+	for _, stem := range stems {
+		for i := strings.Index(word, stem); i >= 0; {
+			prefix, rest := word[:i], word[i+len(stem):]
+			if (prefix != "" || rest != "") && (stem == root || rest != "") &&
+				(prefix == "" || splitsIntoPrefixes(prefix, 2)) && (rest == "" || splitsIntoRootSuffixes(rest)) {
+				return prefix != "", true
+			}
+			next := strings.Index(word[i+1:], stem)
+			if next < 0 {
+				break
+			}
+			i += next + 1
+		}
+	}
+	return false, false
+}
+
+// splitsIntoPrefixes 判断 prefix 能否完整拆分成最多 depth 个 rootPrefixes 中的前缀
+func splitsIntoPrefixes(prefix string, depth int) bool {
+	if depth == 0 {
+		return false
+	}
+	for _, p := range rootPrefixes {
+		if after, ok := strings.CutPrefix(prefix, p); ok && (after == "" || splitsIntoPrefixes(after, depth-1)) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitsIntoRootSuffixes 判断 rest 能否完整拆分成 rootSuffixes 中的词尾；以 e 结尾的词尾
+// 后面接元音开头的词尾时可以去掉 e（structure → structural）
+func splitsIntoRootSuffixes(rest string) bool {
+	for _, s := range rootSuffixes {
+		if after, ok := strings.CutPrefix(rest, s); ok && (after == "" || splitsIntoRootSuffixes(after)) {
+			return true
+		}
+		if len(s) > 1 && s[len(s)-1] == 'e' {
+			if after, ok := strings.CutPrefix(rest, s[:len(s)-1]); ok && after != "" &&
+				strings.ContainsRune("aeiouy", rune(after[0])) && splitsIntoRootSuffixes(after) {
+				return true
+			}
+		}
+	}
+	return false
+}
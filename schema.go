@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// schemaVersion 当前版本生成的数据库结构版本，生成时写入两个数据库的 PRAGMA user_version
+//
+// 表或列有变化（如新增 tag、phonetic_us 这样的列）时加一；版本 0 是写入版本号之前生成的数据库
+const schemaVersion = 1
+
+// setSchemaVersion 把 schemaVersion 写入已生成的数据库
+func setSchemaVersion(ctx context.Context, db *sql.DB) error {
+	if _, err := db.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", schemaVersion)); err != nil {
+		return fmt.Errorf("无法写入数据库结构版本: %v", err)
+	}
+	return nil
+}
+
+// writeSchemaVersion 打开生成好的数据库文件写入 schemaVersion
+func writeSchemaVersion(ctx context.Context, path string) error {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return fmt.Errorf("无法打开数据库 %s: %v", path, err)
+	}
+	defer db.Close()
+	return setSchemaVersion(ctx, db)
+}
+
+// dbSchemaVersion 读取数据库文件的结构版本
+func dbSchemaVersion(path string) (int, error) {
+	db, err := sql.Open("sqlite", readOnlyDSN(path))
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+	var version int
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("无法读取 %s 的结构版本: %v", path, err)
+	}
+	return version, nil
+}
+
+// schemaVersions 返回两个数据库中最旧和最新的结构版本
+func schemaVersions() (oldest, newest int, err error) {
+	for i, file := range []string{englishDBFile, chineseDBFile} {
+		v, err := dbSchemaVersion(file)
+		if err != nil {
+			return 0, 0, err
+		}
+		if i == 0 || v < oldest {
+			oldest = v
+		}
+		if i == 0 || v > newest {
+			newest = v
+		}
+	}
+	return oldest, newest, nil
+}
+
+// schemaStatus 两个数据库的结构版本不是当前版本时的说明：有旧版本生成的数据库时提示重新生成，
+// 否则有更新的版本生成的数据库时提示部分内容可能无法显示；都是当前版本时返回 nil
+func schemaStatus(oldest, newest int) error {
+	if oldest < schemaVersion {
+		return schemaError(oldest)
+	}
+	return schemaError(newest)
+}
+
+// schemaError 数据库结构版本不是当前版本时的说明，版本一致时返回 nil
+func schemaError(version int) error {
+	switch {
+	case version < schemaVersion:
+		return fmt.Errorf("数据库由旧版本生成（结构版本 %d，当前为 %d），请运行 ./dict build -force 重新生成", version, schemaVersion)
+	case version > schemaVersion:
+		return fmt.Errorf("数据库由更新的版本生成（结构版本 %d，当前为 %d），部分内容可能无法显示", version, schemaVersion)
+	}
+	return nil
+}
+
+// upgradeDatabases 启动时检查已有数据库的结构版本，有一个比当前版本旧时用词典数据重新生成；
+// 找不到词典数据时只给出提示，继续使用旧数据库（缺少的列对应的功能不可用）。
+// 由更新的版本生成的数据库不会重新生成，只给出提示
+func upgradeDatabases() error {
+	version, newest, err := schemaVersions()
+	if err != nil {
+		return err
+	}
+	if version >= schemaVersion {
+		if err := schemaStatus(version, newest); err != nil {
+			consolePrintf("⚠️  %v\n", err)
+		}
+		return nil
+	}
+
+	if _, err := resolveDictCSV(dictCSVFile); err != nil && config.DictURL == "" {
+		consolePrintf("⚠️  %v\n", schemaError(version))
+		consolePrintf("   找不到 %s 或 %s，继续使用旧数据库，部分功能可能不可用\n", dictCSVFile, dictGzFile)
+		return nil
+	}
+	consolePrintf("🔄 数据库由旧版本生成（结构版本 %d，当前为 %d），正在重新生成...\n", version, schemaVersion)
+	consolePrintln()
+	return buildDatabases(dictCSVFile)
+}
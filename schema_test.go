@@ -0,0 +1,74 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// writeVersionedDB 在 path 创建一个结构版本为 version 的数据库
+func writeVersionedDB(t *testing.T, path string, version int) {
+	t.Helper()
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS t (x); PRAGMA user_version = %d`, version)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSchemaVersions(t *testing.T) {
+	tests := []struct {
+		english, chinese int
+		oldest, newest   int
+		status           string // schemaStatus 的说明中应有的内容，为空表示没有问题
+		rebuild          bool   // upgradeDatabases 是否要重新生成
+	}{
+		{schemaVersion, schemaVersion, schemaVersion, schemaVersion, "", false},
+		{schemaVersion + 1, schemaVersion, schemaVersion, schemaVersion + 1, "更新的版本", false},
+		{schemaVersion, schemaVersion + 2, schemaVersion, schemaVersion + 2, "更新的版本", false},
+		{schemaVersion - 1, schemaVersion, schemaVersion - 1, schemaVersion, "旧版本", true},
+		{schemaVersion, schemaVersion - 1, schemaVersion - 1, schemaVersion, "旧版本", true},
+		// 一个更旧、一个更新时仍需重新生成
+		{schemaVersion - 1, schemaVersion + 1, schemaVersion - 1, schemaVersion + 1, "旧版本", true},
+	}
+	for _, tt := range tests {
+		name := fmt.Sprintf("英文 %d、中文 %d", tt.english, tt.chinese)
+		t.Chdir(t.TempDir())
+		writeVersionedDB(t, englishDBFile, tt.english)
+		writeVersionedDB(t, chineseDBFile, tt.chinese)
+
+		oldest, newest, err := schemaVersions()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if oldest != tt.oldest || newest != tt.newest {
+			t.Errorf("%s: schemaVersions() = %d, %d，应为 %d, %d", name, oldest, newest, tt.oldest, tt.newest)
+		}
+		status := schemaStatus(oldest, newest)
+		if tt.status == "" && status != nil || tt.status != "" && (status == nil || !strings.Contains(status.Error(), tt.status)) {
+			t.Errorf("%s: schemaStatus = %v，应提示 %q", name, status, tt.status)
+		}
+
+		// 目录中没有词典数据，需要重新生成时只给出提示并继续使用旧数据库
+		config = defaultConfig()
+		config.DictURL = ""
+		var upgradeErr error
+		output := captureStdout(t, func() { upgradeErr = upgradeDatabases() })
+		if upgradeErr != nil {
+			t.Errorf("%s: upgradeDatabases() 出错: %v", name, upgradeErr)
+		}
+		if tt.status != "" && !strings.Contains(output, tt.status) {
+			t.Errorf("%s: upgradeDatabases 没有提示 %q，输出为:\n%s", name, tt.status, output)
+		}
+		if got := strings.Contains(output, "继续使用旧数据库"); got != tt.rebuild {
+			t.Errorf("%s: upgradeDatabases 是否尝试重新生成为 %v，应为 %v，输出为:\n%s", name, got, tt.rebuild, output)
+		}
+		if tt.status == "" && output != "" {
+			t.Errorf("%s: 版本一致时不应有输出:\n%s", name, output)
+		}
+	}
+}
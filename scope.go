@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+)
+
+// 结果范围：按匹配方式逐级放宽显示的结果，对应搜索函数中精确、前缀、包含三个阶段
+const (
+	scopeAll      = iota // 全部结果（每次搜索后的默认值）
+	scopeExact           // 只显示精确匹配（含原形、拼写变体）
+	scopePrefix          // 再加上前缀匹配
+	scopeContains        // 再加上包含匹配
+)
+
+var (
+	resultScope  = scopeAll     // 当前显示的结果范围
+	scopeResults []SearchResult // 最近一次搜索的完整结果，切换范围时从中重新挑选，不重新查询
+)
+
+// scopeNames 状态栏中显示的范围名称
+var scopeNames = map[int]string{
+	scopeAll:      "全部结果",
+	scopeExact:    "仅精确匹配",
+	scopePrefix:   "精确 + 前缀匹配",
+	scopeContains: "精确 + 前缀 + 包含匹配",
+}
+
+// matchScope 返回匹配方式最早出现在哪个范围中；拼音、跨语言、后缀等其他匹配只在全部结果中显示
+func matchScope(match MatchType) int {
+	switch match {
+	case MatchExact, MatchLemma, MatchSpelling:
+		return scopeExact
+	case MatchPrefix:
+		return scopePrefix
+	case MatchContains:
+		return scopeContains
+	}
+	return scopeAll
+}
+
+// inScope 判断匹配方式是否在范围 scope 中显示
+func inScope(match MatchType, scope int) bool {
+	return scope == scopeAll || (matchScope(match) != scopeAll && matchScope(match) <= scope)
+}
+
+// resetResultScope 新的搜索结果显示后记下完整结果，范围恢复为全部
+func resetResultScope(results []SearchResult) {
+	scopeResults = results
+	resultScope = scopeAll
+}
+
+// cycleResultScope 按 精确 → 前缀 → 包含 → 全部 的顺序切换显示的结果范围，
+// 只在已经查到的结果中重新挑选；筛选框打开时在新的范围内重新筛选
+func cycleResultScope() {
+	if browseMode || len(scopeResults) == 0 {
+		setStatus("[yellow]请先搜索，切换范围只作用于当前的搜索结果[-]")
+		return
+	}
+	resultScope = (resultScope + 1) % len(scopeNames)
+
+	var visible []SearchResult
+	for _, r := range scopeResults {
+		if inScope(r.Match, resultScope) {
+			visible = append(visible, r)
+		}
+	}
+	resultTexts, resultWords = buildResultRows(visible)
+	if filterOpen {
+		applyResultFilter(resultFilter.GetText())
+	} else {
+		showResultRows(resultTexts, resultWords)
+	}
+	setStatus(fmt.Sprintf("范围: %s（%d / %d 个结果），%s 切换", scopeNames[resultScope],
+		len(visible), len(scopeResults), keyLabel(actionScope)))
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// selfTestCheck 自检中的一项检查，check 返回 nil 表示通过
+type selfTestCheck struct {
+	name  string
+	check func() error
+}
+
+// selfTestChecks --selftest 依次执行的检查，使用的都是任何完整词典中都有的常见词
+var selfTestChecks = []selfTestCheck{
+	{"精确匹配 apple 排在第一位", func() error { return checkExactFirst("apple") }},
+	{"精确匹配词组 give up 排在第一位", func() error { return checkExactFirst("give up") }},
+	{"apple 有中文释义", func() error { return checkTranslation("apple") }},
+	{"反查「苹果」能找到 apple", func() error { return checkReverseLookup("苹果", "apple") }},
+	{fmt.Sprintf("随机推荐的单词词频在 %d-%d 之间", randomMinBNC, randomMaxBNC), checkRandomBand},
+}
+
+// runSelfTest 打开已生成的数据库执行全部检查，逐项输出结果，有检查未通过时返回错误
+func runSelfTest() error {
+	if err := openExistingDatabases(); err != nil {
+		return err
+	}
+	defer closeDatabases()
+
+	failed := 0
+	for _, c := range selfTestChecks {
+		if err := c.check(); err != nil {
+			failed++
+			consolePrintf("❌ %s: %v\n", c.name, err)
+			continue
+		}
+		consolePrintf("✅ %s\n", c.name)
+	}
+	if failed > 0 {
+		return fmt.Errorf("自检未通过：%d/%d 项检查失败，可以运行 ./dict build -force 重新生成数据库", failed, len(selfTestChecks))
+	}
+	consolePrintf("🎉 全部 %d 项检查通过\n", len(selfTestChecks))
+	return nil
+}
+
+// checkExactFirst 检查搜索 word 时第一个结果是它本身的精确匹配
+func checkExactFirst(word string) error {
+	results, err := search(word)
+	if err != nil {
+		return fmt.Errorf("搜索失败: %v", err)
+	}
+	if len(results) == 0 {
+		return fmt.Errorf("没有搜索结果")
+	}
+	if first := results[0]; first.Word != word || first.Match != MatchExact {
+		return fmt.Errorf("第一个结果是 %q（%s）", first.Word, first.Match.Label())
+	}
+	return nil
+}
+
+// checkTranslation 检查 word 有中文释义
+func checkTranslation(word string) error {
+	w, err := lookupEnglishWord(word)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(w.Translation) == "" {
+		return fmt.Errorf("中文释义为空")
+	}
+	return nil
+}
+
+// checkReverseLookup 检查搜索中文 chinese 能精确匹配到它，且对应的英文单词中有 english
+func checkReverseLookup(chinese, english string) error {
+	results, err := search(chinese)
+	if err != nil {
+		return fmt.Errorf("搜索失败: %v", err)
+	}
+	if len(results) == 0 || results[0].Word != chinese {
+		return fmt.Errorf("没有精确匹配到「%s」", chinese)
+	}
+
+	var englishWords string
+	if err := chineseDB.QueryRow(`SELECT english_words FROM chinese_words WHERE chinese = ?`, chinese).Scan(&englishWords); err != nil {
+		return fmt.Errorf("无法读取对应的英文单词: %v", err)
+	}
+	// 每行的格式为「英文单词（中文释义）」
+	for _, line := range strings.Split(englishWords, "\n") {
+		if word, _, _ := strings.Cut(strings.TrimSpace(line), "（"); word == english {
+			return nil
+		}
+	}
+	return fmt.Errorf("对应的英文单词中没有 %s", english)
+}
+
+// checkRandomBand 检查初始界面随机推荐的单词都在设定的词频范围内
+func checkRandomBand() error {
+	words, err := RandomWords(initialHistoryCount, randomRecommendOptions()...)
+	if err != nil {
+		return err
+	}
+	if len(words) == 0 {
+		return fmt.Errorf("没有抽到单词")
+	}
+	for _, w := range words {
+		if bnc := parseBNC(w.Bnc); bnc < randomMinBNC || bnc > randomMaxBNC {
+			return fmt.Errorf("%s 的词频为 %q", w.Word, w.Bnc)
+		}
+	}
+	return nil
+}
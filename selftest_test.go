@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSelfTestChecks(t *testing.T) {
+	useFixtureDatabases(t)
+
+	// 测试词典中有 apple、give up 和 苹果，全部检查都应通过
+	for _, c := range selfTestChecks {
+		if err := c.check(); err != nil {
+			t.Errorf("%s: %v", c.name, err)
+		}
+	}
+
+	failing := []struct {
+		name  string
+		check func() error
+	}{
+		{"变形词只有原形匹配", func() error { return checkExactFirst("dogs") }},
+		{"没有搜索结果", func() error { return checkExactFirst("zzzz") }},
+		{"中文释义为空", func() error { return checkTranslation("absotively") }},
+		{"单词不存在", func() error { return checkTranslation("zzzz") }},
+		{"中文词不存在", func() error { return checkReverseLookup("不存在的词", "apple") }},
+		{"对应的英文单词中没有", func() error { return checkReverseLookup("苹果", "dog") }},
+	}
+	for _, c := range failing {
+		if err := c.check(); err == nil {
+			t.Errorf("%s 时检查应失败", c.name)
+		}
+	}
+}
+
+func TestRunSelfTest(t *testing.T) {
+	// runSelfTest 打开当前目录中已生成的数据库
+	csvFile, err := filepath.Abs(fixtureCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(t.TempDir())
+	config = defaultConfig()
+	captureStdout(t, func() {
+		if err := withCSV(csvFile, func(r io.Reader) error { return CreateEnglishDB(context.Background(), r, englishDBFile) }); err != nil {
+			t.Fatal(err)
+		}
+		if err := withCSV(csvFile, func(r io.Reader) error { return CreateChineseDB(context.Background(), r, chineseDBFile) }); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	var selfTestErr error
+	output := captureStdout(t, func() { selfTestErr = runSelfTest() })
+	if selfTestErr != nil {
+		t.Fatalf("runSelfTest() 出错: %v\n%s", selfTestErr, output)
+	}
+	if strings.Count(output, "✅") != len(selfTestChecks) {
+		t.Errorf("应有 %d 项检查通过，输出为:\n%s", len(selfTestChecks), output)
+	}
+
+	// 有检查未通过时逐项报告并返回错误
+	saved := selfTestChecks
+	selfTestChecks = append(append([]selfTestCheck{}, saved...),
+		selfTestCheck{"反查「苹果」能找到 dog", func() error { return checkReverseLookup("苹果", "dog") }})
+	defer func() { selfTestChecks = saved }()
+	output = captureStdout(t, func() { selfTestErr = runSelfTest() })
+	want := fmt.Sprintf("1/%d 项检查失败", len(selfTestChecks))
+	if selfTestErr == nil || !strings.Contains(selfTestErr.Error(), want) {
+		t.Errorf("runSelfTest() 的错误为 %v，应报告%s", selfTestErr, want)
+	}
+	if !strings.Contains(output, "❌ 反查「苹果」能找到 dog") {
+		t.Errorf("没有报告未通过的检查，输出为:\n%s", output)
+	}
+}
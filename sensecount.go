@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// senseCount 返回中文释义拆分后的义项总数（见 parseSenses），用来粗略表示一个词有多少种意思
+func senseCount(translation string) int {
+	count := 0
+	for _, g := range parseSenses(translation) {
+		count += len(g.Senses)
+	}
+	return count
+}
+
+// addSenseCounts 开启 senseCounts 时一次查出结果中英文单词的释义，填入各自的义项数；
+// 中文词和用户词表的词条不计数。查询失败时只记入日志，列表照常显示
+func addSenseCounts(results []SearchResult) {
+	if !config.SenseCounts {
+		return
+	}
+	index := make(map[string][]int)
+	var args []interface{}
+	for i, r := range results {
+		if r.User || isChinese(r.Word) {
+			continue
+		}
+		if _, ok := index[r.Word]; !ok {
+			args = append(args, r.Word)
+		}
+		index[r.Word] = append(index[r.Word], i)
+	}
+	if len(args) == 0 {
+		return
+	}
+
+	ctx, cancel := queryContext()
+	defer cancel()
+	rows, err := englishDB.QueryContext(ctx, `SELECT word, translation FROM words WHERE word IN (?`+strings.Repeat(", ?", len(args)-1)+`)`, args...)
+	if err != nil {
+		logWarnf("无法查询义项数: %v", timeoutError(ctx, err))
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var word, translation string
+		if err := rows.Scan(&word, &translation); err != nil {
+			continue
+		}
+		for _, i := range index[word] {
+			results[i].Senses = senseCount(translation)
+		}
+	}
+}
+
+// senseCountLabel 单词列表中跟在单词后面的义项数，如 run (7)；没有计数时为空
+func senseCountLabel(count int) string {
+	if count == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" [gray](%d)[-]", count)
+}
+
+// withSenseCount 开启 senseCounts 时在详情的词头（见 headword）下面加一行义项数，emphasis 与生成词头时相同
+func withSenseCount(head []string, translation string, emphasis bool) []string {
+	count := senseCount(translation)
+	if !config.SenseCounts || count == 0 {
+		return head
+	}
+	indent := ""
+	if emphasis {
+		indent = "  "
+	}
+	line := fmt.Sprintf("%s[gray]共 %d 个义项[-]", indent, count)
+	// 词头最后一行是空行，义项数紧贴在词头下面
+	return append(head[:len(head)-1:len(head)-1], line, "")
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// senseGroup 中文释义中同一词性（或领域标记，如 [计]）下的义项
+type senseGroup struct {
+	POS    string   // 词性，如 n.、vt.；领域标记如 [计]；没有标记的行为空
+	Senses []string // 各个义项，保持原来的顺序
+}
+
+// posMarkerRegex 匹配义项开头的词性标记，如 n.、vt.、vt.& vi.
+var posMarkerRegex = regexp.MustCompile(`^((?:n|v|vt|vi|a|adj|adv|prep|conj|pron|interj|int|art|num|aux|abbr|pl|det|pref|suf|phr)\.(?:\s*&\s*(?:vt|vi|n|v|a|adj|adv)\.)*)\s*`)
+
+// fieldLabelRegex 匹配一行开头的领域标记，如 [计]、[医]、[网络]
+var fieldLabelRegex = regexp.MustCompile(`^(\[[^\]]+\])\s*`)
+
+// listNumberRegex 匹配义项的编号，如 1.、2、（后面不能紧跟数字，避免拆开 3.5 之类的数字）
+var listNumberRegex = regexp.MustCompile(`^\d{1,2}[.、]\s*[^\d\s]`)
+
+// parseSenses 把 ECDICT 的中文释义拆成按词性分组的义项
+//
+// 释义中各行以字面的 \n 分隔，每行通常以词性开头；较新的词条把几个词性写在同一行（vt. 剽窃；vi. 窃取），
+// 义项之间用「；」分隔，也有用 ①②、1. 2. 编号的。同一个词性出现多次时合并到第一次出现的分组中。
+// 括号内的分号和编号（如人名释义中的生平）不拆分，逗号分隔的近义词仍算一个义项
+func parseSenses(translation string) []senseGroup {
+	var groups []senseGroup
+	index := make(map[string]int) // 词性 → 在 groups 中的下标
+
+	add := func(pos, sense string) {
+		i, ok := index[pos]
+		if !ok {
+			i = len(groups)
+			index[pos] = i
+			groups = append(groups, senseGroup{POS: pos})
+		}
+		groups[i].Senses = append(groups[i].Senses, sense)
+	}
+
+	text := strings.ReplaceAll(translation, "\\n", "\n")
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		pos := ""
+		if m := fieldLabelRegex.FindStringSubmatch(line); m != nil {
+			pos = m[1]
+			line = line[len(m[0]):]
+		}
+		for _, piece := range splitSenses(line) {
+			if m := posMarkerRegex.FindStringSubmatch(piece); m != nil {
+				pos = m[1]
+				piece = strings.TrimSpace(piece[len(m[0]):])
+			}
+			if piece != "" {
+				add(pos, piece)
+			}
+		}
+	}
+	return groups
+}
+
+// splitSenses 在括号之外的分号、圆圈编号（①）和数字编号（1.）处拆分一行释义，去掉编号和空白
+func splitSenses(line string) []string {
+	var pieces []string
+	var current strings.Builder
+	flush := func() {
+		if s := strings.Trim(current.String(), " ,，"); s != "" {
+			pieces = append(pieces, s)
+		}
+		current.Reset()
+	}
+
+	depth := 0 // 括号嵌套层数
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '(' || r == '（' || r == '[':
+			depth++
+		case (r == ')' || r == '）' || r == ']') && depth > 0:
+			depth--
+		case depth > 0:
+		case r == '；' || r == ';':
+			flush()
+			continue
+		case r >= '①' && r <= '⑳':
+			flush()
+			continue
+		case unicode.IsDigit(r) && (i == 0 || unicode.IsSpace(runes[i-1])):
+			if loc := listNumberRegex.FindStringIndex(string(runes[i:])); loc != nil {
+				flush()
+				// 跳过编号和标点，保留后面的第一个字符
+				skip := len([]rune(string(runes[i:])[:loc[1]])) - 1
+				i += skip - 1
+				continue
+			}
+		}
+		current.WriteRune(r)
+	}
+	flush()
+	return pieces
+}
+
+// senseLines 把中文释义渲染成按词性分组的编号列表：只有一个义项的词性写在同一行，多个义项时逐行编号
+func senseLines(translation string) []string {
+	var lines []string
+	for _, g := range parseSenses(translation) {
+		prefix := "  [green]•[-] "
+		if g.POS != "" {
+			prefix += posBadge(g.POS) + " "
+		}
+		if len(g.Senses) == 1 {
+			lines = append(lines, prefix+g.Senses[0])
+			continue
+		}
+		lines = append(lines, strings.TrimRight(prefix, " "))
+		for i, sense := range g.Senses {
+			lines = append(lines, fmt.Sprintf("      [gray]%d.[-] %s", i+1, sense))
+		}
+	}
+	return lines
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSensesFixture(t *testing.T) {
+	useFixtureDatabases(t)
+
+	// 测试词典中 ECDICT 原有的释义
+	tests := []struct {
+		word string
+		want []senseGroup
+	}{
+		{"apple", []senseGroup{{"n.", []string{"苹果, 家伙"}}, {"[医]", []string{"苹果"}}}},
+		{"dog", []senseGroup{{"n.", []string{"狗, 坏蛋"}}, {"vt.", []string{"跟踪, 尾随"}}}},
+		{"give", []senseGroup{
+			{"n.", []string{"弹性, 适应性"}},
+			{"vt.", []string{"给, 授予, 供给, 产生, 发表, 付出, 献出, 让出"}},
+			{"vi.", []string{"捐赠, 支持不住, 让步"}},
+		}},
+		{"run", []senseGroup{
+			{"n.", []string{"跑, 赛跑, 奔跑, 奔跑的路程, 趋向, 流出, 运转时间, 连续"}},
+			{"vi.", []string{"跑, 奔跑, 跑步, 赛跑, 竞赛, 行驶, 运转, 进行, 蔓延"}},
+			{"vt.", []string{"使跑, 参赛, 追究, 驾驶, 开动, 管理, 经营, 使流出, 运行"}},
+			{"a.", []string{"熔化的, 融化的, 浇铸的"}},
+			{"", []string{"run的过去式和过去分词"}},
+			{"[计]", []string{"运行"}},
+		}},
+		// 没有词性的词组释义
+		{"give up", []senseGroup{{"", []string{"放弃, 停止, 献出, 抛弃, 认输, 把...送交"}}, {"[法]", []string{"放弃, 停止, 把...送交"}}}},
+		// 括号内的内容不拆分，括号外的分号拆开
+		{"Runciman", []senseGroup{{"n.", []string{"(Runciman)人名", "(英)朗西曼"}}}},
+		{"Apple", []senseGroup{{"n.", []string{"苹果公司", "苹果"}}}},
+		{"absotively", nil},
+	}
+	for _, tt := range tests {
+		w, err := lookupEnglishWord(tt.word)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := parseSenses(w.Translation); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseSenses(%q) = %+v，应为 %+v", w.Translation, got, tt.want)
+		}
+	}
+}
+
+func TestParseSensesNumbered(t *testing.T) {
+	tests := []struct {
+		translation string
+		want        []senseGroup
+	}{
+		// 同一行中的多个词性
+		{"vt. 剽窃；抄袭；vi. 剽窃", []senseGroup{{"vt.", []string{"剽窃", "抄袭"}}, {"vi.", []string{"剽窃"}}}},
+		{"vt.& vi. 冲浪", []senseGroup{{"vt.& vi.", []string{"冲浪"}}}},
+		// 圆圈编号和数字编号
+		{"n. ①苹果 ②苹果树", []senseGroup{{"n.", []string{"苹果", "苹果树"}}}},
+		{"n. 1. 苹果 2. 苹果树", []senseGroup{{"n.", []string{"苹果", "苹果树"}}}},
+		// 数字不是编号时不拆分
+		{"n. 3.5 英寸软盘", []senseGroup{{"n.", []string{"3.5 英寸软盘"}}}},
+		// 重复出现的词性合并到第一个分组
+		{`n. 狗\nvt. 跟踪\nn. 坏蛋`, []senseGroup{{"n.", []string{"狗", "坏蛋"}}, {"vt.", []string{"跟踪"}}}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := parseSenses(tt.translation); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseSenses(%q) = %+v，应为 %+v", tt.translation, got, tt.want)
+		}
+	}
+}
+
+func TestSenseLines(t *testing.T) {
+	lines := senseLines("n. 苹果公司；苹果\\n[医] 苹果")
+	if len(lines) != 4 {
+		t.Fatalf("senseLines 返回 %d 行，应为 4 行:\n%s", len(lines), strings.Join(lines, "\n"))
+	}
+	// 多个义项时逐行编号，只有一个义项时写在同一行
+	if !strings.Contains(lines[1], "1.") || !strings.HasSuffix(lines[1], "苹果公司") ||
+		!strings.Contains(lines[2], "2.") || !strings.HasSuffix(lines[2], "苹果") {
+		t.Errorf("n. 的两个义项没有分行编号:\n%s", strings.Join(lines, "\n"))
+	}
+	if !strings.HasSuffix(lines[3], " 苹果") || strings.Contains(lines[3], "1.") {
+		t.Errorf("[医] 的唯一义项应写在同一行: %q", lines[3])
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// validateShareURL 检查 shareURL 为空或是 http、https 地址
+func validateShareURL(rawURL string) error {
+	if rawURL == "" {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("shareURL %q 不是有效的 http 或 https 地址", rawURL)
+	}
+	return nil
+}
+
+// shareText 返回分享单词用的文本：设置了 shareURL 时是查询服务的 /word 地址，否则是用 --open 打开它的命令
+func shareText(word string) string {
+	if config.ShareURL != "" {
+		return strings.TrimSuffix(config.ShareURL, "/") + "/word?q=" + url.QueryEscape(word)
+	}
+	return "dict --open " + shellQuote(word)
+}
+
+// shellQuote 在单词含有空格或 shell 特殊字符时加上单引号，如 give up → 'give up'，单词中的单引号另行转义
+func shellQuote(word string) string {
+	if word != "" && !strings.ContainsAny(word, " \t'\"\\$`!*?&;|<>()[]{}#~") {
+		return word
+	}
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
+
+// copyShareText 把当前单词的分享文本复制到剪贴板；没有剪贴板工具或复制失败时在状态栏显示这段文本，可以手动复制
+func copyShareText() {
+	if currentWord == "" {
+		setStatus("[yellow]请先选中一个单词[-]")
+		return
+	}
+	text := shareText(currentWord)
+	go func() {
+		err := copyToClipboard(text)
+		app.QueueUpdateDraw(func() {
+			if err != nil {
+				// 原因较长，放在诊断日志中，状态栏优先显示要分享的文本
+				logWarnf("%v", err)
+				setStatus("分享: " + tview.Escape(text) + "  [yellow]（无法复制到剪贴板，请手动复制）[-]")
+				return
+			}
+			setStatus("已复制分享" + shareKind() + " " + tview.Escape(text))
+		})
+	}()
+}
+
+// shareKind 返回分享文本的类型，用于状态栏提示
+func shareKind() string {
+	if config.ShareURL != "" {
+		return "链接"
+	}
+	return "命令"
+}
@@ -0,0 +1,47 @@
+package main
+
+import "strings"
+
+// punctuationReplacer 把输入法、手机键盘和从文档中复制时常见的弯引号、各种连字符换成词库使用的 ASCII 字符
+// （ECDICT 中的 don't、mother-in-law 都只使用 ' 和 -）
+var punctuationReplacer = strings.NewReplacer(
+	"’", "'", "‘", "'", "ʼ", "'", "′", "'", "`", "'",
+	"‐", "-", "‑", "-", "–", "-", "—", "-", "−", "-",
+)
+
+const maxSpellingVariantLength = 15 // 超过这个长度的单词不再尝试插入标点，避免候选过多
+
+// spellingVariants 返回与 word 只差撇号、连字符或空格的其他写法，用于精确匹配和原形都没有结果时
+//
+// 已经带有标点或空格时，在三者之间互换或去掉它们（mother in law → mother-in-law，co op → co-op）；
+// 只有字母时在每个位置分别插入撇号、连字符和空格（dont → don't，oclock → o'clock，xray → x-ray）
+func spellingVariants(word string) []string {
+	word = strings.ToLower(word)
+	var variants []string
+	seen := map[string]bool{word: true}
+	add := func(v string) {
+		if v != "" && !seen[v] {
+			seen[v] = true
+			variants = append(variants, v)
+		}
+	}
+
+	if strings.ContainsAny(word, " -'") {
+		add(strings.ReplaceAll(word, "-", " "))
+		add(strings.ReplaceAll(word, " ", "-"))
+		add(strings.ReplaceAll(word, "'", ""))
+		add(strings.ReplaceAll(word, "-", ""))
+		add(strings.ReplaceAll(word, " ", ""))
+		return variants
+	}
+
+	if len(word) < 3 || len(word) > maxSpellingVariantLength || !lowerWordRegex.MatchString(word) {
+		return nil
+	}
+	for i := 1; i < len(word); i++ {
+		for _, sep := range []string{"'", "-", " "} {
+			add(word[:i] + sep + word[i:])
+		}
+	}
+	return variants
+}
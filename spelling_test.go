@@ -0,0 +1,108 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNormalizeQueryPunctuation(t *testing.T) {
+	tests := []struct {
+		text, want string
+	}{
+		{"don’t", "don't"},
+		{"don‘t", "don't"},
+		{"donʼt", "don't"},
+		{"don`t", "don't"},
+		{"mother–in–law", "mother-in-law"},
+		{"mother—in—law", "mother-in-law"},
+		{"co‑op", "co-op"},
+		{"co‐op", "co-op"},
+		{" don’t  stop ", "don't stop"},
+	}
+	for _, tt := range tests {
+		if got := normalizeQuery(tt.text); got != tt.want {
+			t.Errorf("normalizeQuery(%q) = %q，应为 %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSpellingVariants(t *testing.T) {
+	tests := []struct {
+		word string
+		want []string
+	}{
+		{"mother in law", []string{"mother-in-law", "motherinlaw"}},
+		{"mother-in-law", []string{"mother in law", "motherinlaw"}},
+		{"co-op", []string{"co op", "coop"}},
+		{"co op", []string{"co-op", "coop"}},
+		{"don't", []string{"dont"}},
+		{"Don't", []string{"dont"}},
+		{"dont", []string{"d'ont", "d-ont", "d ont", "do'nt", "do-nt", "do nt", "don't", "don-t", "don t"}},
+		// 太短、太长或含有字母以外字符的单词不插入标点
+		{"ok", nil},
+		{"internationalization", nil},
+		{"café", nil},
+	}
+	for _, tt := range tests {
+		if got := spellingVariants(tt.word); !slices.Equal(got, tt.want) {
+			t.Errorf("spellingVariants(%q) = %q，应为 %q", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestSearchEnglishPunctuation(t *testing.T) {
+	useFixtureDatabases(t)
+
+	tests := []struct {
+		query string
+		want  []string // 排在最前面的结果（顺序不限）
+		match MatchType
+	}{
+		{"don't", []string{"don't"}, MatchExact},
+		{"don’t", []string{"don't"}, MatchExact},
+		{"dont", []string{"don't"}, MatchSpelling},
+		{"mother-in-law", []string{"mother-in-law"}, MatchExact},
+		{"mother–in–law", []string{"mother-in-law"}, MatchExact},
+		{"mother in law", []string{"mother-in-law"}, MatchSpelling},
+		{"co-op", []string{"co-op"}, MatchExact},
+		{"coop", []string{"coop"}, MatchExact},
+		// 两种写法都是词条，都作为拼写变体列出
+		{"co op", []string{"co-op", "coop"}, MatchSpelling},
+	}
+	for _, tt := range tests {
+		results, err := searchEnglish(tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) < len(tt.want) {
+			t.Errorf("searchEnglish(%q) = %v，应以 %v 开头", tt.query, wordsOf(results), tt.want)
+			continue
+		}
+		got := wordsOf(results[:len(tt.want)])
+		slices.Sort(got)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("searchEnglish(%q) = %v，应以 %v 开头", tt.query, wordsOf(results), tt.want)
+		}
+		for _, r := range results[:len(tt.want)] {
+			if r.Match != tt.match {
+				t.Errorf("searchEnglish(%q) 中 %s 为%s，应为%s", tt.query, r.Word, r.Match.Label(), tt.match.Label())
+			}
+		}
+	}
+}
+
+func TestLookupWordsWithPunctuation(t *testing.T) {
+	useFixtureDatabases(t)
+
+	// 生成数据库时原样保存带撇号、连字符的词条，详情按原词查询
+	for _, word := range []string{"don't", "mother-in-law", "co-op", "'hood"} {
+		w, err := lookupEnglishWord(word)
+		if err != nil {
+			t.Errorf("lookupEnglishWord(%q) 出错: %v", word, err)
+			continue
+		}
+		if w.Word != word || w.Translation == "" {
+			t.Errorf("lookupEnglishWord(%q) = %q（释义 %q）", word, w.Word, w.Translation)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// stateRoot 用户数据（搜索历史等）的存放目录，与只读的词典数据库分开
+const stateRoot = "userdata"
+
+// profileName 当前使用的用户档案，为空时使用默认档案（直接存放在 stateRoot 下）
+var profileName string
+
+var (
+	stateDirty   atomic.Bool // 用户数据自上次保存后是否有修改
+	saveMutex    sync.Mutex  // 保证定时保存和退出时的保存不会同时写文件
+	detailNoWrap atomic.Bool // 详情面板是否关闭了自动换行（长音标、例句保持在一行，左右键横向滚动）
+)
+
+// historyState 搜索历史文件的内容
+type historyState struct {
+	History []string `json:"history"`
+}
+
+// preferencesState 界面偏好文件的内容，未设置的项使用默认值
+type preferencesState struct {
+	DetailWrap *bool `json:"detailWrap,omitempty"` // 详情面板是否自动换行，默认换行
+}
+
+// lookupState 查阅次数文件的内容
+type lookupState struct {
+	Counts       map[string]int    `json:"counts"`
+	FirstLookups map[string]string `json:"firstLookups,omitempty"` // 每个单词首次查询的日期，如 2024-03-01
+}
+
+// stateDir 返回当前档案的用户数据目录，不同档案的历史记录等互不影响，词典数据库则共用
+func stateDir() string {
+	if profileName == "" {
+		return stateRoot
+	}
+	return filepath.Join(stateRoot, "profiles", profileName)
+}
+
+// validateProfileName 检查档案名能否安全地用作目录名
+func validateProfileName(name string) error {
+	if name == "" {
+		return nil
+	}
+	if name == "." || name == ".." || strings.ContainsAny(name, `/\:*?"<>|`) {
+		return fmt.Errorf("档案名 %q 不能包含路径分隔符或特殊字符", name)
+	}
+	return nil
+}
+
+// favoritesState 收藏文件的内容
+type favoritesState struct {
+	Favorites []string `json:"favorites"`
+}
+
+// historyFile 返回搜索历史文件路径
+func historyFile() string {
+	return filepath.Join(stateDir(), "history.json")
+}
+
+// lookupFile 返回查阅次数文件路径
+func lookupFile() string {
+	return filepath.Join(stateDir(), "lookups.json")
+}
+
+// favoritesFile 返回收藏文件路径
+func favoritesFile() string {
+	return filepath.Join(stateDir(), "favorites.json")
+}
+
+// preferencesFile 返回界面偏好文件路径
+func preferencesFile() string {
+	return filepath.Join(stateDir(), "preferences.json")
+}
+
+// markStateDirty 标记用户数据已修改，等待下次保存
+func markStateDirty() {
+	stateDirty.Store(true)
+}
+
+// loadState 从磁盘读取用户数据，文件不存在时保持为空
+func loadState() error {
+	var h historyState
+	if err := readJSONFile(historyFile(), &h); err != nil {
+		return err
+	}
+
+	historyMutex.Lock()
+	searchHistory = h.History
+	if len(searchHistory) > maxHistorySize {
+		searchHistory = searchHistory[:maxHistorySize]
+	}
+	historyMutex.Unlock()
+
+	var l lookupState
+	if err := readJSONFile(lookupFile(), &l); err != nil {
+		return err
+	}
+	lookupMutex.Lock()
+	for w, c := range l.Counts {
+		lookupCounts[w] = c
+	}
+	for w, d := range l.FirstLookups {
+		firstLookups[w] = d
+	}
+	lookupMutex.Unlock()
+
+	var f favoritesState
+	if err := readJSONFile(favoritesFile(), &f); err != nil {
+		return err
+	}
+	favoritesMutex.Lock()
+	favorites = f.Favorites
+	favoritesMutex.Unlock()
+
+	var r reviewState
+	if err := readJSONFile(reviewFile(), &r); err != nil {
+		return err
+	}
+	setReviewState(r)
+
+	var s studyListsState
+	if err := readJSONFile(studyListsFile(), &s); err != nil {
+		return err
+	}
+	setStudyLists(s)
+
+	var p preferencesState
+	if err := readJSONFile(preferencesFile(), &p); err != nil {
+		return err
+	}
+	if p.DetailWrap != nil {
+		detailNoWrap.Store(!*p.DetailWrap)
+	}
+	return nil
+}
+
+// saveState 把有修改的用户数据写入磁盘
+func saveState() error {
+	saveMutex.Lock()
+	defer saveMutex.Unlock()
+
+	if !stateDirty.Swap(false) {
+		return nil
+	}
+
+	err := rotateHistory(int64(config.HistoryMaxFileSize)*1024, config.HistoryRotateKeep)
+	if err == nil {
+		err = writeJSONFileAtomic(historyFile(), historyState{History: getSearchHistory()})
+	}
+	if err == nil {
+		err = writeJSONFileAtomic(lookupFile(), lookupState{Counts: getLookupCounts(), FirstLookups: getFirstLookups()})
+	}
+	if err == nil {
+		err = writeJSONFileAtomic(favoritesFile(), favoritesState{Favorites: getFavorites()})
+	}
+	if err == nil {
+		err = writeJSONFileAtomic(reviewFile(), getReviewState())
+	}
+	if err == nil {
+		err = writeJSONFileAtomic(studyListsFile(), getStudyLists())
+	}
+	if err == nil {
+		wrap := !detailNoWrap.Load()
+		err = writeJSONFileAtomic(preferencesFile(), preferencesState{DetailWrap: &wrap})
+	}
+	if err != nil {
+		// 保存失败时保留修改标记，下次继续尝试
+		markStateDirty()
+		return err
+	}
+	return nil
+}
+
+// rotateHistory 在历史文件超过 maxBytes 时把它归档为 history-时间.json，
+// 内存中只保留最近 keep 条，随后由 saveState 写入新的历史文件；maxBytes 为 0 时不做处理
+//
+// 归档使用重命名，和 writeJSONFileAtomic 一样不会留下写了一半的文件；
+// 即使在归档后、写入新文件前崩溃，全部历史也仍在归档文件中
+func rotateHistory(maxBytes int64, keep int) error {
+	if maxBytes <= 0 {
+		return nil
+	}
+	info, err := os.Stat(historyFile())
+	if err != nil || info.Size() <= maxBytes {
+		return nil
+	}
+
+	// 同一秒内多次归档时加上序号，避免覆盖之前的归档
+	stamp := time.Now().Format("20060102-150405")
+	archive := filepath.Join(stateDir(), "history-"+stamp+".json")
+	for i := 2; ; i++ {
+		if _, err := os.Stat(archive); os.IsNotExist(err) {
+			break
+		}
+		archive = filepath.Join(stateDir(), fmt.Sprintf("history-%s-%d.json", stamp, i))
+	}
+	if err := os.Rename(historyFile(), archive); err != nil {
+		return fmt.Errorf("无法归档 %s: %v", historyFile(), err)
+	}
+
+	historyMutex.Lock()
+	if len(searchHistory) > keep {
+		searchHistory = searchHistory[:keep]
+	}
+	historyMutex.Unlock()
+	return nil
+}
+
+// startAutoSave 在后台按固定间隔保存用户数据，返回的函数用于停止定时保存
+func startAutoSave(interval time.Duration, onError func(error)) (stop func()) {
+	if interval <= 0 {
+		return func() {}
+	}
+
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				if err := saveState(); err != nil && onError != nil {
+					onError(err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
+
+// readJSONFile 读取 JSON 文件到 v，文件不存在时不做任何修改
+func readJSONFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("无法读取 %s: %v", path, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%s 格式错误: %v", path, err)
+	}
+	return nil
+}
+
+// writeJSONFileAtomic 先写入临时文件再重命名，避免中途崩溃留下损坏的文件
+func writeJSONFileAtomic(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("无法序列化 %s: %v", path, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("无法创建目录 %s: %v", filepath.Dir(path), err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("无法创建临时文件: %v", err)
+	}
+	tmpName := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return fmt.Errorf("无法写入 %s: %v", tmpName, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return fmt.Errorf("无法写入 %s: %v", tmpName, err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return fmt.Errorf("无法写入 %s: %v", tmpName, err)
+	}
+
+	if err := os.Rename(tmpName, path); err != nil {
+		os.Remove(tmpName)
+		return fmt.Errorf("无法保存 %s: %v", path, err)
+	}
+	return nil
+}
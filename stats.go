@@ -0,0 +1,53 @@
+package main
+
+import "sync"
+
+// sessionStats 本次运行期间的使用统计，退出时输出摘要
+type sessionStats struct {
+	searched       map[string]bool // 本次查过的单词
+	reviewed       int             // 其中以前就查过的单词数量（复习）
+	favoritesAdded map[string]bool // 本次新收藏且仍在收藏中的单词
+	mu             sync.Mutex
+}
+
+var stats = sessionStats{
+	searched:       make(map[string]bool),
+	favoritesAdded: make(map[string]bool),
+}
+
+// recordSearch 记录本次查过的单词，seenBefore 表示本次运行前是否已查过，同一单词只统计一次
+func (s *sessionStats) recordSearch(word string, seenBefore bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.searched[word] {
+		return
+	}
+	s.searched[word] = true
+	if seenBefore {
+		s.reviewed++
+	}
+}
+
+// recordFavorite 记录收藏状态的变化，本次收藏后又取消的不计入
+func (s *sessionStats) recordFavorite(word string, added bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if added {
+		s.favoritesAdded[word] = true
+	} else {
+		delete(s.favoritesAdded, word)
+	}
+}
+
+// printSummary 在控制台输出本次使用的统计摘要，什么都没做时不输出
+func (s *sessionStats) printSummary() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.searched) == 0 && len(s.favoritesAdded) == 0 {
+		return
+	}
+	consolePrintln("📊 本次共查询 ", len(s.searched), " 个单词（其中复习 ", s.reviewed, " 个），新收藏 ", len(s.favoritesAdded), " 个")
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// studyListsState 学习列表文件的内容
+type studyListsState struct {
+	Lists map[string][]string `json:"lists"` // 列表名 → 单词，保持导入时的顺序
+}
+
+var (
+	studyLists      = make(map[string][]string)
+	studyListsMutex sync.Mutex
+)
+
+// studyListName 当前测验只使用的学习列表，为空表示按常用词出题（--study）
+var studyListName string
+
+// studyListsFile 返回学习列表文件路径
+func studyListsFile() string {
+	return filepath.Join(stateDir(), "studylists.json")
+}
+
+// getStudyLists 返回全部学习列表的副本，用于保存
+func getStudyLists() studyListsState {
+	studyListsMutex.Lock()
+	defer studyListsMutex.Unlock()
+	lists := make(map[string][]string, len(studyLists))
+	for name, words := range studyLists {
+		lists[name] = append([]string(nil), words...)
+	}
+	return studyListsState{Lists: lists}
+}
+
+// setStudyLists 用读取到的学习列表文件替换内存中的列表
+func setStudyLists(s studyListsState) {
+	studyListsMutex.Lock()
+	defer studyListsMutex.Unlock()
+	studyLists = make(map[string][]string, len(s.Lists))
+	for name, words := range s.Lists {
+		studyLists[name] = words
+	}
+}
+
+// studyListWords 返回学习列表中的单词，列表不存在时 ok 为 false
+func studyListWords(name string) (words []string, ok bool) {
+	studyListsMutex.Lock()
+	defer studyListsMutex.Unlock()
+	words, ok = studyLists[name]
+	return append([]string(nil), words...), ok
+}
+
+// studyListNames 返回全部学习列表的名称，按字母顺序排列
+func studyListNames() []string {
+	studyListsMutex.Lock()
+	defer studyListsMutex.Unlock()
+	var names []string
+	for name := range studyLists {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// checkStudyList 检查 --study 指定的列表是否存在，不存在时在错误中列出已有的列表
+func checkStudyList(name string) error {
+	if _, ok := studyListWords(name); ok {
+		return nil
+	}
+	names := studyListNames()
+	if len(names) == 0 {
+		return fmt.Errorf("没有名为 %q 的学习列表，请先用 --import-list 导入", name)
+	}
+	return fmt.Errorf("没有名为 %q 的学习列表（已有 %s）", name, strings.Join(names, "、"))
+}
+
+// importStudyList 读取每行一个单词的文本文件，把词典中能查到的单词保存为名为 name 的学习列表（同名列表会被替换），
+// 返回查不到的单词。name 为空时使用不带扩展名的文件名
+//
+// 空行和 # 开头的注释行被忽略；大小写与词典不一致时（如 Monday 写成 monday）使用词典中的写法，重复的单词只保留一次
+func importStudyList(path, name string) (listName string, imported int, missing []string, err error) {
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return name, 0, nil, fmt.Errorf("无法打开单词列表: %v", err)
+	}
+	defer file.Close()
+
+	var words []string
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := normalizeQuery(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		word, ok := studyListEntry(line)
+		if !ok {
+			missing = append(missing, line)
+			continue
+		}
+		if !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return name, 0, nil, fmt.Errorf("无法读取单词列表: %v", err)
+	}
+	if len(words) == 0 {
+		return name, 0, missing, fmt.Errorf("%s 中没有能在词典中查到的单词", path)
+	}
+
+	studyListsMutex.Lock()
+	studyLists[name] = words
+	studyListsMutex.Unlock()
+	markStateDirty()
+	return name, len(words), missing, nil
+}
+
+// studyListEntry 在词典中查找列表中的一行，先按原样、再按小写形式查找，返回词典中的写法
+func studyListEntry(line string) (string, bool) {
+	for _, candidate := range []string{line, strings.ToLower(line)} {
+		if w, err := lookupEnglishWord(candidate); err == nil {
+			return w.Word, true
+		}
+	}
+	return "", false
+}
+
+// runImportStudyList 执行 --import-list：导入单词列表并保存，逐个列出词典中查不到的单词
+func runImportStudyList(path, name string) error {
+	if err := openExistingDatabases(); err != nil {
+		return err
+	}
+	defer closeDatabases()
+	if err := loadState(); err != nil {
+		return fmt.Errorf("读取用户数据失败: %v", err)
+	}
+
+	listName, imported, missing, err := importStudyList(path, name)
+	if len(missing) > 0 {
+		consolePrintf("⚠️  以下 %d 个单词在词典中没有找到，未加入列表:\n", len(missing))
+		for _, word := range missing {
+			consolePrintf("   %s\n", word)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if err := saveState(); err != nil {
+		return fmt.Errorf("保存学习列表失败: %v", err)
+	}
+	consolePrintf("✅ 已导入学习列表「%s」，共 %d 个单词，运行 ./dict --study %s 开始测验\n", listName, imported, listName)
+	return nil
+}
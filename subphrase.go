@@ -0,0 +1,69 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// subphraseMinLength 查找查询中包含的词时最短的词长（汉字个数），单个汉字几乎总能匹配到，意义不大
+const subphraseMinLength = 2
+
+// subphraseMaxQuery 查找查询中包含的词时最多检查查询的前多少个字，候选子串的数量随长度平方增长
+const subphraseMaxQuery = 24
+
+// chineseSubphrases 为 true 时中文搜索还查找词库中被查询包含的词，如 人工智能 → 智能（chineseSubphrases）
+var chineseSubphrases bool
+
+// subphrases 返回 keyword 中所有长度不少于 subphraseMinLength 的子串（不含 keyword 本身），
+// 较长的排在前面，同样长度按在查询中出现的位置排列，重复的子串只保留一次
+func subphrases(keyword string) []string {
+	runes := []rune(keyword)
+	if len(runes) > subphraseMaxQuery {
+		runes = runes[:subphraseMaxQuery]
+	}
+	var result []string
+	seen := make(map[string]bool)
+	for length := len(runes); length >= subphraseMinLength; length-- {
+		for start := 0; start+length <= len(runes); start++ {
+			s := string(runes[start : start+length])
+			if s != keyword && !seen[s] {
+				seen[s] = true
+				result = append(result, s)
+			}
+		}
+	}
+	return result
+}
+
+// searchSubphrases 在中文数据库中查找被 keyword 包含的词，按 subphrases 的顺序（长词优先）追加到 results
+func searchSubphrases(keyword string, results []SearchResult, seen map[string]bool, limit int) ([]SearchResult, error) {
+	candidates := subphrases(keyword)
+	if len(candidates) == 0 || limit <= 0 {
+		return results, nil
+	}
+	args := make([]interface{}, len(candidates))
+	for i, c := range candidates {
+		args[i] = c
+	}
+	query := `SELECT chinese FROM chinese_words WHERE chinese IN (?` + strings.Repeat(", ?", len(candidates)-1) + `)`
+	found, err := collectMatches(chineseDB, MatchSubphrase, nil, make(map[string]bool), query, args...)
+	if err != nil {
+		return results, err
+	}
+
+	order := make(map[string]int, len(candidates))
+	for i, c := range candidates {
+		order[c] = i
+	}
+	sort.Slice(found, func(i, j int) bool { return order[found[i].Word] < order[found[j].Word] })
+	for _, r := range found {
+		if len(results) >= limit {
+			break
+		}
+		if !seen[r.Word] {
+			seen[r.Word] = true
+			results = append(results, r)
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/rivo/tview"
+)
+
+// surpriseOptions 返回「随便看看」使用的抽取条件：surpriseBNC 限定词频范围，surpriseTag 限定考试标签
+func surpriseOptions() ([]RandomOption, error) {
+	opts, err := randomOptions(config.SurpriseBNC, config.SurpriseTag, "", "")
+	if err != nil {
+		return nil, err
+	}
+	if config.HideProperNouns {
+		opts = append(opts, WithoutProperNouns())
+	}
+	return opts, nil
+}
+
+// randomWord 按 surpriseBNC 和 surpriseTag 随机抽取一个不在 ignoreWords 中的单词
+func randomWord() (string, error) {
+	opts, err := surpriseOptions()
+	if err != nil {
+		return "", err
+	}
+	// 多抽几个，去掉 ignoreWords 排除的单词后仍能剩下一个
+	words, err := RandomWords(5, opts...)
+	if err != nil {
+		return "", err
+	}
+	for _, w := range words {
+		if !isIgnored(w.Word) {
+			return w.Word, nil
+		}
+	}
+	return "", fmt.Errorf("没有满足条件的单词（surpriseBNC、surpriseTag）")
+}
+
+// surpriseMe 随机抽一个单词并直接显示它的详情，之前的单词压入导航栈，可以按返回键回去
+func surpriseMe() {
+	go func() {
+		word, err := randomWord()
+		app.QueueUpdateDraw(func() {
+			if err != nil {
+				showError(err)
+				return
+			}
+			if word != currentWord {
+				pushDetailBack()
+			}
+			loadDetail(word)
+			message := "随便看看: " + tview.Escape(word)
+			if len(detailBack) > 0 {
+				message += "，按 " + keyLabel(actionBack) + " 返回 " + tview.Escape(detailBack[len(detailBack)-1])
+			}
+			setStatus(message + "，再按 " + keyLabel(actionSurprise) + " 换一个")
+		})
+	}()
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"strings"
+)
+
+// syllableSuffixes 常见后缀，整体作为最后一个音节，不再按元音拆分（如 na-tion、pic-ture）
+var syllableSuffixes = []string{"tion", "sion", "cian", "tial", "cial", "ture", "sure", "geous", "cious", "tious"}
+
+// syllableOnsets 可以一起出现在音节开头的辅音组合，拆分辅音串时尽量让它们留在后一个音节
+var syllableOnsets = map[string]bool{
+	"ch": true, "sh": true, "th": true, "ph": true, "wh": true, "qu": true,
+	"bl": true, "br": true, "cl": true, "cr": true, "dr": true, "fl": true, "fr": true,
+	"gl": true, "gr": true, "pl": true, "pr": true, "sc": true, "sk": true, "sl": true,
+	"sm": true, "sn": true, "sp": true, "st": true, "sw": true, "tr": true, "tw": true,
+	"str": true, "spr": true, "scr": true, "spl": true, "thr": true, "shr": true, "chr": true,
+}
+
+// syllableCodas 只能留在前一个音节末尾的辅音组合（如 sing-er、tax-i）
+var syllableCodas = map[string]bool{"ck": true, "ng": true, "x": true}
+
+// keepOnset 判断两个元音之间只有这个辅音组合时是否整体归后一个音节：
+// 二合字母和辅音 + r/l 不拆开（mo-ther、se-cret），其他组合从中间分开（hap-py）
+func keepOnset(onset string) bool {
+	if len(onset) != 2 {
+		return true
+	}
+	switch onset {
+	case "ch", "sh", "th", "ph", "wh", "qu":
+		return true
+	}
+	return onset[1] == 'r' || onset[1] == 'l'
+}
+
+// isSyllableVowel 判断 word 中第 i 个字母是否作为元音；y 在词首或元音前作辅音
+func isSyllableVowel(word string, i int) bool {
+	switch word[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return true
+	case 'y':
+		return i > 0 && !isSyllableVowel(word, i-1)
+	}
+	return false
+}
+
+// splitSyllables 用启发式规则把单词拆分为音节，如 dictionary → dic-tio-na-ry
+//
+// 规则只是近似：连续的元音算作一个音节核，词尾不发音的 e 不单独成音节，
+// 两个元音之间的辅音串尽量把能出现在词首的组合留给后一个音节，单个辅音归后一个音节。
+// 词组按空格分别拆分，含有字母以外字符的单词保持原样
+func splitSyllables(word string) string {
+	parts := strings.Fields(word)
+	for i, p := range parts {
+		parts[i] = splitWordSyllables(p)
+	}
+	return strings.Join(parts, " ")
+}
+
+// splitWordSyllables 拆分单个单词，返回以连字符连接的音节
+func splitWordSyllables(word string) string {
+	lower := strings.ToLower(word)
+	for _, r := range lower {
+		if r < 'a' || r > 'z' {
+			return word
+		}
+	}
+
+	// 先切下常见后缀，剩下的部分再按元音拆分
+	stem, suffix := lower, ""
+	for _, s := range syllableSuffixes {
+		if len(lower) > len(s)+1 && strings.HasSuffix(lower, s) {
+			stem, suffix = lower[:len(lower)-len(s)], s
+			break
+		}
+	}
+
+	// 找出每个音节核（连续元音）的起止位置
+	type nucleus struct{ start, end int }
+	var nuclei []nucleus
+	for i := 0; i < len(stem); i++ {
+		if !isSyllableVowel(stem, i) {
+			continue
+		}
+		if len(nuclei) > 0 && nuclei[len(nuclei)-1].end == i {
+			nuclei[len(nuclei)-1].end = i + 1
+		} else {
+			nuclei = append(nuclei, nucleus{i, i + 1})
+		}
+	}
+
+	// 词尾的 e 通常不发音（make、state），但辅音 + le 自成一个音节（ta-ble）
+	finalLe := false
+	if n := len(nuclei); n > 1 && suffix == "" {
+		last := nuclei[n-1]
+		if last.start == len(stem)-1 && stem[last.start] == 'e' {
+			finalLe = len(stem) >= 3 && stem[len(stem)-2] == 'l' && !isSyllableVowel(stem, len(stem)-3)
+			if !finalLe {
+				nuclei = nuclei[:n-1]
+			}
+		}
+	}
+
+	// 在相邻两个音节核之间确定分界点
+	var cuts []int
+	for i := 1; i < len(nuclei); i++ {
+		from, to := nuclei[i-1].end, nuclei[i].start
+		cluster := stem[from:to]
+		var cut int
+		switch {
+		case finalLe && i == len(nuclei)-1:
+			// 辅音 + le 结尾时，le 前的一个辅音归入最后一个音节（lit-tle、ap-ple）
+			cut = to - 2
+			if cut < from {
+				cut = from
+			}
+		case syllableCodas[cluster]:
+			cut = to
+		case len(cluster) == 1:
+			cut = from
+		default:
+			// 从前往后找最长的可作词首的组合，其余辅音留在前一个音节；找不到时只把最后一个辅音分给后一个音节
+			cut = to - 1
+			for k := from; k < to-1; k++ {
+				if onset := stem[k:to]; syllableOnsets[onset] && (k > from || keepOnset(onset)) {
+					cut = k
+					break
+				}
+			}
+			// ck 不能被拆开（pock-et）
+			if stem[cut-1:cut+1] == "ck" {
+				cut++
+			}
+		}
+		cuts = append(cuts, cut)
+	}
+
+	var syllables []string
+	prev := 0
+	for _, c := range cuts {
+		if c > prev && c < len(stem) {
+			syllables = append(syllables, word[prev:c])
+			prev = c
+		}
+	}
+	syllables = append(syllables, word[prev:len(stem)])
+	if suffix != "" {
+		if len(nuclei) == 0 && len(syllables) == 1 {
+			// 后缀前没有元音（如 -tion 前只有辅音），合并为一个音节
+			return word
+		}
+		syllables = append(syllables, word[len(stem):])
+	}
+	return strings.Join(syllables, "-")
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitSyllables(t *testing.T) {
+	tests := []struct {
+		word, want string
+	}{
+		{"dictionary", "dic-tio-na-ry"},
+		{"nation", "na-tion"},
+		{"national", "na-tio-nal"},
+		{"picture", "pic-ture"},
+		{"construction", "con-struc-tion"},
+		{"structure", "struc-ture"},
+		{"happy", "hap-py"},
+		{"running", "run-ning"},
+		{"mother", "mo-ther"},
+		{"secret", "se-cret"},
+		{"singer", "sing-er"},
+		{"taxi", "tax-i"},
+		{"quickly", "quick-ly"},
+		{"beyond", "be-yond"},
+		{"hotdog", "hot-dog"},
+		// 辅音 + le 结尾
+		{"apple", "ap-ple"},
+		{"table", "ta-ble"},
+		{"little", "lit-tle"},
+		{"impossible", "im-pos-si-ble"},
+		// 单音节词和不发音的词尾 e
+		{"make", "make"},
+		{"strong", "strong"},
+		{"yes", "yes"},
+		{"a", "a"},
+		// 保留大小写，词组按空格分别拆分，含字母以外字符的保持原样
+		{"Apple", "Ap-ple"},
+		{"give up", "give up"},
+		{"happy nation", "hap-py na-tion"},
+		{"don't", "don't"},
+		{"co-op", "co-op"},
+	}
+	for _, tt := range tests {
+		if got := splitSyllables(tt.word); got != tt.want {
+			t.Errorf("splitSyllables(%q) = %q，应为 %q", tt.word, got, tt.want)
+		}
+	}
+}
+
+// withDetailSections 在测试期间让英文详情显示 sections 中的栏目
+func withDetailSections(t *testing.T, sections ...string) {
+	saved := detailSections
+	detailSections = sections
+	t.Cleanup(func() { detailSections = saved })
+}
+
+func TestRenderDetailSyllables(t *testing.T) {
+	useFixtureDatabases(t)
+	withDetailSections(t, sectionTranslation, sectionSyllables)
+
+	detail, _, err := renderDetail(detailKey{word: "dictionary"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(detail, "音节:") || !strings.Contains(detail, "dic-tio-na-ry") {
+		t.Errorf("dictionary 的详情中没有音节拆分:\n%s", detail)
+	}
+
+	// 单音节词不显示这一栏
+	detail, _, err = renderDetail(detailKey{word: "dog"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(detail, "音节:") {
+		t.Errorf("dog 的详情中不应显示音节:\n%s", detail)
+	}
+}
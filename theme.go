@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Theme 界面中可配置的颜色，取值为颜色名称（如 "skyblue"）或 "#rrggbb"
+type Theme struct {
+	HistoryMarker  string `json:"historyMarker"`  // 初始列表中历史记录的 ★ 标记
+	FavoriteMarker string `json:"favoriteMarker"` // 已收藏单词的 ♥ 标记
+	FocusBorder    string `json:"focusBorder"`    // 当前获得焦点的面板的边框
+
+	PhraseMarker       string `json:"phraseMarker"`       // 列表中词组前的 ◇ 标记（typeMarkers 启用时）
+	AbbreviationMarker string `json:"abbreviationMarker"` // 列表中缩写前的 Ⓐ 标记
+
+	// 详情中词性徽标的底色（posBadges 启用时）
+	PosNoun      string `json:"posNoun"`      // n.
+	PosVerb      string `json:"posVerb"`      // v.、vt.、vi.
+	PosAdjective string `json:"posAdjective"` // adj.、a.
+	PosAdverb    string `json:"posAdverb"`    // adv.
+	PosOther     string `json:"posOther"`     // prep.、conj. 等其他词性
+}
+
+// defaultTheme 返回默认配色
+func defaultTheme() Theme {
+	return Theme{
+		HistoryMarker:  "skyblue",
+		FavoriteMarker: "red",
+		FocusBorder:    "gold",
+
+		PhraseMarker:       "mediumpurple",
+		AbbreviationMarker: "orange",
+		PosNoun:            "skyblue",
+		PosVerb:            "lightgreen",
+		PosAdjective:       "plum",
+		PosAdverb:          "khaki",
+		PosOther:           "silver",
+	}
+}
+
+// validate 检查主题中的颜色能否被识别
+func (t Theme) validate() error {
+	for name, value := range map[string]string{
+		"historyMarker":      t.HistoryMarker,
+		"favoriteMarker":     t.FavoriteMarker,
+		"focusBorder":        t.FocusBorder,
+		"phraseMarker":       t.PhraseMarker,
+		"abbreviationMarker": t.AbbreviationMarker,
+		"posNoun":            t.PosNoun,
+		"posVerb":            t.PosVerb,
+		"posAdjective":       t.PosAdjective,
+		"posAdverb":          t.PosAdverb,
+		"posOther":           t.PosOther,
+	} {
+		if _, ok := tcell.ColorNames[value]; !ok && (len(value) != 7 || value[0] != '#' || tcell.GetColor(value) == tcell.ColorDefault) {
+			return fmt.Errorf("theme.%s 的颜色 %q 无法识别", name, value)
+		}
+	}
+	return nil
+}
+
+// posColor 返回某类词性（见 posCategory）徽标的底色
+func (t Theme) posColor(category string) string {
+	switch category {
+	case posNoun:
+		return t.PosNoun
+	case posVerb:
+		return t.PosVerb
+	case posAdjective:
+		return t.PosAdjective
+	case posAdverb:
+		return t.PosAdverb
+	}
+	return t.PosOther
+}
+
+// colored 用 tview 颜色标签给文本着色
+func colored(color, text string) string {
+	return "[" + color + "]" + text + "[-]"
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// queryTimeout 运行时单次查询的最长时间，0 表示不限制；由 config.QueryTimeout 设置
+//
+// 超时后 SQLite 会中断正在执行的语句，查询返回 errQueryTimeout，界面不会一直停在「搜索中」。
+// 输入很快时被新输入取代的查询结果本来就会被丢弃，超时只是让它们最多占用这么久
+var queryTimeout = 2 * time.Second
+
+// errQueryTimeout 查询超过 queryTimeout 时返回的错误
+var errQueryTimeout = errors.New("查询超时")
+
+// queryContext 返回运行时查询使用的 context，查询（包括读取全部结果行）结束后需调用 cancel
+func queryContext() (context.Context, context.CancelFunc) {
+	if queryTimeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), queryTimeout)
+}
+
+// timeoutError 在 ctx 已超时的情况下把查询返回的错误换成 errQueryTimeout，其他错误原样返回
+func timeoutError(ctx context.Context, err error) error {
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return errQueryTimeout
+	}
+	return err
+}
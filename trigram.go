@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// trigramSize 倒排索引中每个片段的字符数
+const trigramSize = 3
+
+// hasTrigramIndex 英文数据库是否包含三字母片段索引（旧版本数据库没有）
+var hasTrigramIndex bool
+
+// wordTrigrams 返回单词小写形式中所有不重复的三字符片段，不足三个字符时返回 nil
+func wordTrigrams(word string) []string {
+	runes := []rune(strings.ToLower(word))
+	if len(runes) < trigramSize {
+		return nil
+	}
+
+	seen := make(map[string]bool, len(runes))
+	var trigrams []string
+	for i := 0; i+trigramSize <= len(runes); i++ {
+		t := string(runes[i : i+trigramSize])
+		if !seen[t] {
+			seen[t] = true
+			trigrams = append(trigrams, t)
+		}
+	}
+	return trigrams
+}
+
+// buildTrigramIndex 为 words 表中的全部单词建立三字符片段到单词 id 的倒排索引
+func buildTrigramIndex(ctx context.Context, db *sql.DB) error {
+	_, err := db.Exec(`
+	-- 三字符片段到单词的倒排索引，用于加速包含匹配（LIKE '%x%' 无法使用普通索引）
+	CREATE TABLE IF NOT EXISTS word_trigrams (
+		trigram TEXT NOT NULL,
+		word_id INTEGER NOT NULL,
+		PRIMARY KEY (trigram, word_id)
+	) WITHOUT ROWID;
+	`)
+	if err != nil {
+		return fmt.Errorf("无法创建表: %v", err)
+	}
+
+	// 先把单词全部读出来，避免在同一个连接上边读边写
+	type entry struct {
+		id   int64
+		word string
+	}
+	var entries []entry
+	rows, err := db.QueryContext(ctx, `SELECT id, word FROM words`)
+	if err != nil {
+		return fmt.Errorf("无法读取单词: %v", err)
+	}
+	for rows.Next() {
+		var e entry
+		if err := rows.Scan(&e.id, &e.word); err != nil {
+			rows.Close()
+			return fmt.Errorf("无法读取单词: %v", err)
+		}
+		entries = append(entries, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("无法读取单词: %v", err)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("无法开始事务: %v", err)
+	}
+	stmt, err := tx.Prepare(`INSERT OR IGNORE INTO word_trigrams (trigram, word_id) VALUES (?, ?)`)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("无法准备语句: %v", err)
+	}
+	defer stmt.Close()
+
+	bar := newProgressBar("      📊 索引进度")
+	for i, e := range entries {
+		if i%1000 == 0 {
+			if err := ctx.Err(); err != nil {
+				consolePrintln()
+				tx.Rollback()
+				return err
+			}
+			bar.Update(i, len(entries))
+		}
+		for _, t := range wordTrigrams(e.word) {
+			if _, err := stmt.Exec(t, e.id); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("无法写入索引: %v", err)
+			}
+		}
+	}
+
+	// 每个片段对应的单词数，查询时据此选出最少见的片段
+	if _, err := tx.Exec(`
+	CREATE TABLE IF NOT EXISTS trigram_counts (
+		trigram TEXT PRIMARY KEY,
+		n INTEGER NOT NULL
+	) WITHOUT ROWID;
+	INSERT OR REPLACE INTO trigram_counts SELECT trigram, COUNT(*) FROM word_trigrams GROUP BY trigram;
+	`); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("无法统计索引: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("无法提交事务: %v", err)
+	}
+	bar.Finish()
+	return nil
+}
+
+// containsQuery 返回英文包含匹配的查询语句和参数
+//
+// 有片段索引且关键词不少于三个字符时，只遍历关键词中最少见的片段对应的单词，
+// 再用 LIKE 确认确实包含关键词（关键词中的 % 和 _ 按字面匹配）；否则退回到全表扫描。
+// 候选单词按 id 顺序逐条检查，凑够 limit 个就停止，不需要先求出所有片段的交集，
+// 因此 tion、ing 这类常见词干也不会比全表扫描慢
+func containsQuery(keyword, filter string, limit int) (string, []interface{}) {
+	trigrams := wordTrigrams(keyword)
+	escaped := escapeLike(keyword)
+	if !hasTrigramIndex || len(trigrams) == 0 {
+		return `SELECT word FROM words WHERE word LIKE ? ESCAPE '\' AND word NOT LIKE ? ESCAPE '\'` + filter + ` LIMIT ?`,
+			[]interface{}{"%" + escaped + "%", escaped + "%", limit}
+	}
+
+	rarest, args := rarestTrigram(trigrams)
+	query := `SELECT word FROM word_trigrams t JOIN words ON words.id = t.word_id
+		WHERE t.trigram = ` + rarest + `
+		AND word LIKE ? ESCAPE '\' AND word NOT LIKE ? ESCAPE '\'` + filter + ` LIMIT ?`
+	return query, append(args, "%"+escaped+"%", escaped+"%", limit)
+}
+
+// rarestTrigram 返回从 trigrams 中选出索引里单词数最少的片段的子查询和参数
+//
+// 某个片段在索引中不存在时 COALESCE 返回 0，说明没有任何单词包含全部片段
+func rarestTrigram(trigrams []string) (string, []interface{}) {
+	var args []interface{}
+	counts := make([]string, len(trigrams))
+	for i, t := range trigrams {
+		counts[i] = `SELECT ? AS trigram, COALESCE((SELECT n FROM trigram_counts WHERE trigram = ?), 0) AS n`
+		args = append(args, t, t)
+	}
+	return `(SELECT trigram FROM (` + strings.Join(counts, " UNION ALL ") + `) ORDER BY n LIMIT 1)`, args
+}
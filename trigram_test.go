@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/csv"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// withTrigramIndex 在 fn 执行期间假定英文数据库有（或没有）三字母片段索引，用于比较两种包含匹配的查询方式
+func withTrigramIndex(index bool, fn func()) {
+	saved := hasTrigramIndex
+	hasTrigramIndex = index
+	defer func() { hasTrigramIndex = saved }()
+	fn()
+}
+
+func TestWordTrigrams(t *testing.T) {
+	tests := []struct {
+		word string
+		want []string
+	}{
+		{"ab", nil},
+		{"tion", []string{"tio", "ion"}},
+		{"Nation", []string{"nat", "ati", "tio", "ion"}},
+		{"aaaa", []string{"aaa"}},
+	}
+	for _, tt := range tests {
+		if got := wordTrigrams(tt.word); !slices.Equal(got, tt.want) {
+			t.Errorf("wordTrigrams(%q) = %v，应为 %v", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestContainsTrigramMatchesScan(t *testing.T) {
+	useFixtureDatabases(t)
+	if !hasTrigramIndex {
+		t.Fatal("生成的英文数据库没有三字母片段索引")
+	}
+
+	for _, keyword := range []string{"tion", "appl", "ruct", "xyz"} {
+		var indexed, scanned []SearchResult
+		var err error
+		withTrigramIndex(true, func() { indexed, err = searchEnglish(keyword) })
+		if err != nil {
+			t.Fatal(err)
+		}
+		withTrigramIndex(false, func() { scanned, err = searchEnglish(keyword) })
+		if err != nil {
+			t.Fatal(err)
+		}
+		a, b := wordsOf(indexed), wordsOf(scanned)
+		slices.Sort(a)
+		slices.Sort(b)
+		if !slices.Equal(a, b) {
+			t.Errorf("searchEnglish(%q) 使用片段索引的结果 %v 与全表扫描的结果 %v 不同", keyword, a, b)
+		}
+	}
+}
+
+// syntheticCSV 在临时目录中写入由 n 个随机单词组成的词典 CSV，其中约十分之一以 tion 结尾
+//
+// 测试词典只有几十个词条，全表扫描比查索引还快；基准测试用它才能看出索引的作用
+func syntheticCSV(tb testing.TB, n int) string {
+	tb.Helper()
+	path := filepath.Join(tb.TempDir(), "synthetic.csv")
+	file, err := os.Create(path)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	w.Write([]string{"word", "phonetic", "definition", "translation", "pos", "collins", "oxford", "tag", "bnc", "frq", "exchange", "detail", "audio"})
+	r := rand.New(rand.NewPCG(1, 2))
+	for i := 0; i < n; i++ {
+		word := make([]byte, 4+r.IntN(6))
+		for j := range word {
+			word[j] = byte('a' + r.IntN(26))
+		}
+		if i%10 == 0 {
+			word = append(word, "tion"...)
+		}
+		w.Write([]string{string(word), "", "", "n. 测试", "", "", "", "", "0", "0", "", "", ""})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		tb.Fatal(err)
+	}
+	return path
+}
+
+// BenchmarkContainsMatch 在两万个随机单词上比较英文包含匹配使用三字母片段索引（trigram）和全表扫描（scan）的耗时
+func BenchmarkContainsMatch(b *testing.B) {
+	config = defaultConfig()
+	english, chinese, err := openMemoryDatabases(syntheticCSV(b, 20000))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer english.Close()
+	defer chinese.Close()
+	useDatabases(english, chinese)
+	for _, bc := range []struct {
+		name  string
+		index bool
+	}{{"trigram", true}, {"scan", false}} {
+		b.Run(bc.name, func(b *testing.B) {
+			withTrigramIndex(bc.index, func() {
+				for b.Loop() {
+					if _, err := searchEnglish("ation"); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		})
+	}
+}
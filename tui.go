@@ -0,0 +1,1272 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"golang.org/x/term"
+)
+
+var (
+	searchInput   *tview.InputField
+	wordList      *tview.List
+	detailView    *tview.TextView
+	sideView      *tview.TextView // 分栏布局时右侧显示中文释义的面板
+	detailPanel   *tview.Flex     // 详情区域，单栏时只包含 detailView
+	statusBar     *tview.TextView // 底部状态栏，用于显示错误等提示信息
+	leftPanel     *tview.Flex
+	searchResults []string    // 列表每一行对应的单词，分组标题行为空字符串
+	searchMutex   sync.Mutex  // 保护搜索结果的并发访问
+	inputTimer    *time.Timer // 输入后的自动切换定时器
+	idleTimer     *time.Timer // 无操作超时定时器，任何按键或鼠标操作都会重新计时
+	lastListIndex int         // 上一次选中的列表行，跳过分组标题时用于判断移动方向
+	listVersion   int64       // 列表中当前显示的是哪个版本的搜索结果（对应 searchVersion）
+	listChanging  bool        // 正在处理列表选中项的变化（方向键移动），期间不记入搜索历史
+	rowMarkup     []string    // 初始列表每行带颜色标记的文本，为空表示列表没有着色
+	rowPlain      []string    // 与 rowMarkup 对应的纯文本，选中行显示纯文本以保证选中颜色的对比度
+	plainRow      = -1        // 当前以纯文本显示的行
+	vimMode       bool        // 启用 vim 风格按键（j/k 移动、gg/G 跳到首尾、/ 搜索）
+	pendingG      bool        // vim 模式下已按下一个 g，等待第二个 g
+	browseMode    bool        // 浏览模式：按首字母翻阅单词而不是搜索
+	browsePrefix  string      // 浏览模式下当前的前缀
+	browsePage    int         // 浏览模式下当前的页码（从 0 开始）
+	splitLayout   bool        // 用户是否开启了分栏布局
+	splitActive   bool        // 当前是否正在以分栏显示（终端过窄时自动回退为单栏）
+	currentWord   string      // 详情面板当前显示的单词，切换布局时用于重新渲染
+	screenWidth   int         // 最近一次绘制时的终端宽度
+	pinnedWord    string      // 固定用于对比的英文单词，为空表示未固定
+	expandedWord  string      // 按 F9 展开、不截断显示的单词
+
+	activeQuery string       // 当前生效的搜索词（已规范化），焦点离开搜索框后仍然保留
+	queryMutex  sync.RWMutex // 保护 activeQuery，详情等渲染在后台协程中读取
+)
+
+// openWord 启动后立即搜索的单词（--open）
+var openWord string
+
+// autoFocusDelay 停止输入多久后自动把焦点切换到单词列表，并把选中的单词记入历史
+var autoFocusDelay = 5 * time.Second
+
+// splitMinWidth 分栏布局所需的最小终端宽度，窄于此宽度时使用单栏布局
+const splitMinWidth = 140
+
+// interactiveTerminal 判断能否启动全屏界面，不能时返回原因
+//
+// tcell 直接打开控制终端，只重定向了标准输出（如 dict > out.txt）时界面仍能使用；
+// 标准输入和输出都不是终端（在脚本、定时任务或不分配终端的 ssh 中运行）或 TERM=dumb 时则无法交互
+func interactiveTerminal() (bool, string) {
+	if os.Getenv("TERM") == "dumb" {
+		return false, "TERM=dumb 的终端不支持全屏界面"
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) && !term.IsTerminal(int(os.Stdout.Fd())) {
+		return false, "标准输入和标准输出都不是终端"
+	}
+	return true, ""
+}
+
+// runTUI 创建界面并运行应用，直到用户退出
+func runTUI() error {
+	// 创建应用
+	app = tview.NewApplication()
+	mainLayout := newMainLayout()
+
+	// 指定了 --open 时直接搜索该单词，如同在搜索框中输入；否则显示随机单词或历史记录
+	if openWord != "" {
+		searchInput.SetText(openWord)
+	} else {
+		showInitialWords()
+	}
+
+	// 指定了 --study 时直接进入该学习列表的测验
+	if studyListName != "" {
+		showQuizView()
+	}
+
+	// 无障碍模式使用黑白屏幕，没有指定时由 Run 创建普通屏幕
+	if accessibleMode {
+		screen, err := newAccessibleScreen()
+		if err != nil {
+			return err
+		}
+		app.SetScreen(screen)
+	}
+
+	return app.SetRoot(mainLayout, true).EnableMouse(true).Run()
+}
+
+// newMainLayout 创建所有界面组件并返回根页面（主界面和历史记录界面）
+func newMainLayout() *tview.Pages {
+	// 创建UI组件
+	searchInput = tview.NewInputField().
+		SetLabel("搜索: ").
+		SetFieldWidth(0).
+		SetPlaceholder("输入中文或英文...")
+
+	wordList = tview.NewList().
+		ShowSecondaryText(false).
+		SetWrapAround(false). // 禁止循环滚动
+		SetMainTextColor(tcell.ColorWhite).
+		SetSelectedTextColor(tcell.ColorBlack).
+		SetSelectedBackgroundColor(tcell.ColorYellow)
+
+	detailView = tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWordWrap(true).
+		SetWrap(!detailNoWrap.Load())
+	detailView.SetBorder(true).SetTitle("详细信息")
+	setupDetailLinks()
+
+	sideView = tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWordWrap(true).
+		SetWrap(!detailNoWrap.Load())
+	sideView.SetBorder(true).SetTitle("中文释义")
+
+	detailPanel = tview.NewFlex().
+		AddItem(detailView, 0, 1, false)
+
+	statusBar = tview.NewTextView().
+		SetDynamicColors(true)
+
+	// 监听列表选择变化，按上下键时立即显示详情
+	wordList.SetChangedFunc(onListChanged)
+	wordList.SetInputCapture(handleVirtualListKey)
+
+	// 搜索功能（异步查询）
+	searchInput.SetChangedFunc(onSearchChanged)
+
+	// 设置输入框快捷键
+	searchInput.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter || key == tcell.KeyDown {
+			app.SetFocus(wordList)
+		}
+	})
+	setupHistoryDropdown()
+
+	// 左侧面板（搜索框和列表）
+	leftPanel = tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(searchInput, 1, 0, true).
+		AddItem(newResultFilter(), 0, 0, false).
+		AddItem(wordList, 0, 1, false)
+	leftPanel.SetBorder(true).SetTitle("单词列表")
+
+	// 主布局（左右分栏）
+	mainLayout := tview.NewFlex().
+		AddItem(leftPanel, 0, 3, true).
+		AddItem(detailPanel, 0, 7, false)
+
+	// 根布局（主布局和底部状态栏）
+	root := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(mainLayout, 0, 1, true).
+		AddItem(statusBar, 1, 0, false)
+
+	// 设置全局快捷键（只在主界面中生效，历史记录界面有自己的按键处理）
+	root.SetInputCapture(handleGlobalKey)
+
+	pages = tview.NewPages().
+		AddPage("main", root, true, true).
+		AddPage("history", newHistoryView(), true, false).
+		AddPage("quiz", newQuizView(), true, false).
+		AddPage("leaderboard", newLeaderboardView(), true, false)
+
+	// 任何按键或鼠标操作都重新开始空闲计时
+	app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		resetIdleTimer()
+		return event
+	})
+	app.SetMouseCapture(func(event *tcell.EventMouse, action tview.MouseAction) (*tcell.EventMouse, tview.MouseAction) {
+		resetIdleTimer()
+		return event, action
+	})
+	resetIdleTimer()
+
+	// 每次绘制前根据终端宽度决定是否分栏，从而在窗口缩放时自动切换
+	app.SetBeforeDrawFunc(func(screen tcell.Screen) bool {
+		screenWidth, _ = screen.Size()
+		applyDetailLayout(screenWidth)
+		updateFocusBorders()
+		updateSelectedMarker()
+		return false
+	})
+
+	return pages
+}
+
+// updateFocusBorders 把获得焦点的面板的边框显示为 theme.focusBorder，其余面板恢复默认颜色
+//
+// 在每次绘制前调用，因此按键、定时器和方向键转移焦点后都会立即更新。绘制时 app 已加锁，
+// 不能调用 app.GetFocus，这里通过各面板的 HasFocus 判断
+func updateFocusBorders() {
+	active := tcell.GetColor(config.Theme.FocusBorder)
+	for _, pane := range []struct {
+		box     *tview.Box
+		focused bool
+	}{
+		{leftPanel.Box, leftPanel.HasFocus()},
+		{detailView.Box, detailView.HasFocus()},
+		{sideView.Box, sideView.HasFocus()},
+	} {
+		color := tview.Styles.BorderColor
+		if pane.focused {
+			color = active
+		}
+		pane.box.SetBorderColor(color)
+	}
+}
+
+// resetIdleTimer 重新开始空闲计时（需在主线程中调用），未配置 idleTimeout 时不做任何事
+func resetIdleTimer() {
+	if config.IdleTimeout <= 0 {
+		return
+	}
+	timeout := time.Duration(config.IdleTimeout) * time.Minute
+	if idleTimer == nil {
+		idleTimer = time.AfterFunc(timeout, onIdle)
+	} else {
+		idleTimer.Reset(timeout)
+	}
+}
+
+// onIdle 在空闲超时后保存用户数据，然后按配置回到初始界面或退出程序
+func onIdle() {
+	err := saveState()
+
+	app.QueueUpdateDraw(func() {
+		if config.IdleAction == "exit" {
+			app.Stop()
+			return
+		}
+
+		// 停止输入后的自动聚焦定时器，避免它在重置后又把未完成的搜索记入历史
+		if inputTimer != nil {
+			inputTimer.Stop()
+			inputTimer = nil
+		}
+		pages.HidePage("history")
+		pages.HidePage("leaderboard")
+		if front, _ := pages.GetFrontPage(); front == "quiz" {
+			closeQuizView()
+		}
+		pinnedWord = ""
+		lockedWord = ""
+		if browseMode {
+			toggleBrowseMode()
+		}
+		searchInput.SetText("")
+		app.SetFocus(searchInput)
+		updateDetailTitle()
+
+		if err != nil {
+			showError(fmt.Errorf("保存用户数据失败: %v", err))
+		}
+	})
+}
+
+// applyDetailLayout 根据分栏开关和终端宽度调整详情区域，布局变化时重新渲染当前单词
+func applyDetailLayout(width int) {
+	want := splitLayout && width >= splitMinWidth
+	if want == splitActive {
+		return
+	}
+	splitActive = want
+
+	detailPanel.Clear()
+	detailPanel.AddItem(detailView, 0, 1, false)
+	if splitActive {
+		detailPanel.AddItem(sideView, 0, 1, false)
+		setStatus("")
+	}
+	if currentWord != "" {
+		loadDetail(currentWord)
+	}
+}
+
+// toggleSplitLayout 开启或关闭分栏布局
+func toggleSplitLayout() {
+	splitLayout = !splitLayout
+	if !splitLayout {
+		setStatus("")
+		return
+	}
+
+	if screenWidth < splitMinWidth {
+		setStatus(fmt.Sprintf("[yellow]终端宽度不足 %d 列，暂时使用单栏布局，加宽窗口后自动分栏[-]", splitMinWidth))
+	} else {
+		setStatus("已开启分栏布局")
+	}
+}
+
+// setStatus 在状态栏显示提示信息（需在主线程中调用）
+func setStatus(text string) {
+	statusBar.SetText(text)
+}
+
+// showError 在状态栏以红色显示错误信息（需在主线程中调用）
+func showError(err error) {
+	setStatus("[red]✗ " + tview.Escape(err.Error()) + "[-]")
+}
+
+// showUpdating 数据库正在更新时代替错误信息显示的提示（需在主线程中调用），更新完成后会自动刷新
+func showUpdating() {
+	setStatus("[yellow]" + errDatabaseUpdating.Error() + "（完成后自动刷新）[-]")
+}
+
+// onRebuildStarted 发现其他进程正在重新生成数据库时提示用户（在后台协程中调用）
+func onRebuildStarted() {
+	app.QueueUpdateDraw(func() {
+		setStatus("[yellow]数据库正在后台重新生成，完成前继续使用旧数据[-]")
+	})
+}
+
+// onRebuildFinished 后台生成结束后刷新当前的搜索结果（在后台协程中调用）
+func onRebuildFinished(updated bool, err error) {
+	app.QueueUpdateDraw(func() {
+		switch {
+		case err != nil:
+			showError(fmt.Errorf("切换到新数据库失败: %v", err))
+		case !updated:
+			setStatus("[yellow]后台生成数据库未完成，继续使用旧数据[-]")
+		default:
+			// 数据可能已经变化，重新执行当前的搜索；搜索框为空时重新显示初始列表
+			onSearchChanged(searchInput.GetText())
+			setStatus("[green]数据库已更新[-]")
+		}
+	})
+}
+
+// reloadDatabases 在后台重新打开数据库文件、重新读取用户词表并刷新当前的搜索结果，
+// 用于在另一个终端中修改或重新生成数据库、编辑用户词表之后
+//
+// 打开失败时继续使用原来的数据库
+func reloadDatabases() {
+	setStatus("[yellow]正在重新加载数据库...[-]")
+	go func() {
+		err := reopenDatabases()
+		if err != nil {
+			logErrorf("重新加载数据库失败: %v", err)
+		} else {
+			logInfof("已重新加载数据库")
+		}
+		userErr := loadUserWords(config.UserWords)
+		detailCache.clear()
+		app.QueueUpdateDraw(func() {
+			if err != nil {
+				showError(fmt.Errorf("重新加载数据库失败，继续使用原来的数据: %v", err))
+				return
+			}
+			onSearchChanged(searchInput.GetText())
+			if userErr != nil {
+				showError(userErr)
+				return
+			}
+			setStatus("[green]数据库已重新加载[-]")
+		})
+	}()
+}
+
+// loadDetail 异步加载单词的详细信息并显示在详情面板中
+func loadDetail(word string) {
+	// 详情锁定时不切换到其他单词，只在状态栏预览
+	if lockedWord != "" && word != lockedWord {
+		showLockedPreview(word)
+		return
+	}
+	maxLength := config.MaxDetailLength
+	full := word == expandedWord
+	if full {
+		maxLength = 0
+	}
+
+	key := newDetailKey(word, splitActive, full)
+
+	go func(sw, pinned string, split bool) {
+		var detail, side string
+		var err error
+		if pinned != "" && pinned != sw && !isChinese(sw) {
+			detail, side, err = renderComparison(pinned, sw, split)
+		} else {
+			detail, side, err = cachedRenderDetail(key)
+		}
+
+		// 超长的详情会让 TextView 渲染和滚动变慢，默认只显示前一部分
+		detail, _ = truncateDetail(detail, maxLength)
+		side, _ = truncateDetail(side, maxLength)
+
+		// 在主线程中更新详细信息
+		app.QueueUpdateDraw(func() {
+			currentWord = sw
+			if errors.Is(err, errDatabaseUpdating) {
+				clearDetail()
+				showUpdating()
+				return
+			}
+			if err != nil {
+				clearDetail()
+				showError(err)
+				return
+			}
+			detailView.SetText(detail)
+			updateDetailLinks(detail)
+			sideView.SetText(side)
+			updateDetailTitle()
+		})
+	}(word, pinnedWord, splitActive)
+}
+
+// togglePin 固定或取消固定当前显示的单词，固定后选择其他单词时并排对比
+func togglePin() {
+	if pinnedWord != "" {
+		pinnedWord = ""
+		setStatus("已取消固定")
+	} else {
+		if currentWord == "" || isChinese(currentWord) {
+			setStatus("[yellow]只能固定英文单词，请先选中一个英文单词[-]")
+			return
+		}
+		pinnedWord = currentWord
+		setStatus(fmt.Sprintf("已固定 %s，选择其他单词即可对比（%s 取消固定）", tview.Escape(pinnedWord), keyLabel(actionPin)))
+	}
+	updateDetailTitle()
+	if currentWord != "" {
+		loadDetail(currentWord)
+	}
+}
+
+// expandDetail 不截断地重新显示当前单词的完整详情
+func expandDetail() {
+	if currentWord == "" || currentWord == expandedWord {
+		return
+	}
+	expandedWord = currentWord
+	loadDetail(currentWord)
+}
+
+// toggleEmphasis 切换词头的加强显示，并重新显示当前单词
+func toggleEmphasis() {
+	emphasisMode = !emphasisMode
+	if emphasisMode {
+		setStatus("词头已加强显示（" + keyLabel(actionEmphasis) + " 恢复）")
+	} else {
+		setStatus("词头已恢复默认显示")
+	}
+	if currentWord != "" {
+		loadDetail(currentWord)
+	}
+}
+
+// toggleDetailWrap 切换详情面板（以及分栏时的中文释义面板）的自动换行，设置会保存到用户数据中
+func toggleDetailWrap() {
+	wrap := detailNoWrap.Load()
+	detailNoWrap.Store(!wrap)
+	detailView.SetWrap(wrap).ScrollToBeginning()
+	sideView.SetWrap(wrap).ScrollToBeginning()
+	markStateDirty()
+	if wrap {
+		setStatus("详情已恢复自动换行")
+	} else {
+		setStatus("详情不再自动换行，长行可以在详情面板中用左右键滚动（" + keyLabel(actionWrap) + " 恢复）")
+	}
+}
+
+// togglePhoneticMode 切换只显示单词和音标的发音练习模式
+func togglePhoneticMode() {
+	phoneticMode = !phoneticMode
+	if phoneticMode {
+		setStatus("发音练习：只显示单词和音标（" + keyLabel(actionPhoneticMode) + " 恢复，" + keyLabel(actionCopyPhonetic) + " 复制音标）")
+	} else {
+		setStatus("已恢复完整释义")
+	}
+	if currentWord != "" {
+		loadDetail(currentWord)
+	}
+}
+
+// copyPhonetic 把当前英文单词的音标复制到剪贴板
+func copyPhonetic() {
+	if currentWord == "" || isChinese(currentWord) {
+		setStatus("[yellow]请先选中一个英文单词[-]")
+		return
+	}
+
+	word := currentWord
+	go func() {
+		w, err := lookupEnglishWord(word)
+		if p := splitPhonetics(w); err == nil && w.Phonetic == "" && len(p) > 0 {
+			// 只有单独的英式、美式音标时复制第一个
+			w.Phonetic = p[0].text
+		}
+		if err == nil && w.Phonetic == "" {
+			err = fmt.Errorf("%s 没有音标", word)
+		}
+		if err == nil {
+			err = copyToClipboard(w.Phonetic)
+		}
+		app.QueueUpdateDraw(func() {
+			if err != nil {
+				showError(err)
+				return
+			}
+			setStatus("已复制音标 " + tview.Escape(w.Phonetic))
+		})
+	}()
+}
+
+// updateDetailTitle 根据收藏和固定状态更新详情面板标题
+func updateDetailTitle() {
+	title := "详细信息"
+	if currentWord != "" && isFavorite(currentWord) {
+		title += " · ♥ 已收藏"
+	}
+	if pinnedWord != "" {
+		title += " · 已固定 " + tview.Escape(pinnedWord)
+	}
+	if lockedWord != "" {
+		title += " · 已锁定"
+	}
+	if len(detailLinks) > 0 {
+		title += " · ←/→ 选择单词，Enter 打开"
+	}
+	detailView.SetTitle(title)
+}
+
+// toggleCurrentFavorite 收藏或取消收藏详情面板中的单词
+func toggleCurrentFavorite() {
+	if currentWord == "" {
+		setStatus("[yellow]请先选中一个单词[-]")
+		return
+	}
+	if toggleFavorite(currentWord) {
+		setStatus("已收藏 " + tview.Escape(currentWord))
+	} else {
+		setStatus("已取消收藏 " + tview.Escape(currentWord))
+	}
+	updateDetailTitle()
+}
+
+// showRandomFavorite 随机显示一个收藏的单词，用于快速复习
+func showRandomFavorite() {
+	word := randomFavorite()
+	if word == "" {
+		setStatus("[yellow]还没有收藏的单词，按 " + keyLabel(actionFavorite) + " 收藏当前单词[-]")
+		return
+	}
+	loadDetail(word)
+	setStatus(fmt.Sprintf("随机复习：%s（共 %d 个收藏，%s 换一个）", tview.Escape(word), len(getFavorites()), keyLabel(actionRandomFavorite)))
+}
+
+// togglePersonalRanking 切换是否按查阅次数排序，并用新的排序方式重新搜索
+func togglePersonalRanking() {
+	ranking := !personalRanking.Load()
+	personalRanking.Store(ranking)
+	if !browseMode && getActiveQuery() != "" {
+		onSearchChanged(searchInput.GetText())
+	}
+	if ranking {
+		setStatus("排序：常查的单词优先")
+	} else {
+		setStatus("排序：默认顺序")
+	}
+}
+
+// toggleFamilies 切换是否按词族分组，并用新的方式重新搜索
+func toggleFamilies() {
+	group := !groupFamilies.Load()
+	groupFamilies.Store(group)
+	if !browseMode && getActiveQuery() != "" {
+		onSearchChanged(searchInput.GetText())
+	}
+	if group {
+		setStatus("结果按词族分组：派生词显示在词根之下")
+	} else {
+		setStatus("结果不分词族")
+	}
+}
+
+// playPronunciation 在后台下载（或从缓存读取）并播放当前英文单词的发音
+func playPronunciation() {
+	if config.AudioURL == "" {
+		setStatus("[yellow]未启用发音，请在 config.json 中设置 audioURL[-]")
+		return
+	}
+	if currentWord == "" || isChinese(currentWord) {
+		setStatus("[yellow]请先选中一个英文单词[-]")
+		return
+	}
+
+	word := currentWord
+	setStatus("正在播放 " + tview.Escape(word) + " 的发音...")
+	go func() {
+		file, err := fetchAudio(word)
+		if err == nil {
+			err = playAudio(file)
+		}
+		app.QueueUpdateDraw(func() {
+			if err != nil {
+				showError(err)
+				return
+			}
+			setStatus("")
+		})
+	}()
+}
+
+// clearDetail 清空详情区域的所有面板
+func clearDetail() {
+	currentWord = ""
+	detailLinks = nil
+	detailView.Clear()
+	sideView.Clear()
+}
+
+// selectListItem 处理列表项的点击或回车，record 表示是否记入搜索历史；具体行为由 keymap.enter 决定
+func selectListItem(index int, record bool) {
+	selectedWord := wordAt(index)
+	if selectedWord == "" {
+		return
+	}
+
+	switch enterAction {
+	case enterCopy:
+		copyWord(selectedWord)
+		return
+	case enterFocusDetail:
+		// 选中项变化时详情已经加载，只在还没有显示这个单词时补上
+		if selectedWord != currentWord {
+			loadDetail(selectedWord)
+		}
+		app.SetFocus(detailView)
+		return
+	}
+
+	if record {
+		addToHistory(selectedWord)
+	}
+	loadDetail(selectedWord)
+	if enterAction == enterLoadFocusDetail {
+		app.SetFocus(detailView)
+	}
+}
+
+// copyWord 把单词本身复制到剪贴板
+func copyWord(word string) {
+	go func() {
+		err := copyToClipboard(word)
+		app.QueueUpdateDraw(func() {
+			if err != nil {
+				showError(err)
+				return
+			}
+			setStatus("已复制 " + tview.Escape(word))
+		})
+	}()
+}
+
+// wordAt 返回列表第 index 行对应的单词，越界或分组标题行返回空字符串
+func wordAt(index int) string {
+	word, _ := rowWord(index)
+	return word
+}
+
+// rowWord 返回列表第 index 行对应的单词（分组标题行为空字符串），以及这一行是否在当前的 searchResults 中
+//
+// 列表项的回调只记着行号，执行前 searchResults 可能已被新的搜索换成更短的结果，
+// 所有按行号取词的地方都要经过这里，在同一次加锁中检查范围
+func rowWord(index int) (string, bool) {
+	searchMutex.Lock()
+	defer searchMutex.Unlock()
+
+	index += listOffset
+	if index < 0 || index >= len(searchResults) {
+		return "", false
+	}
+	return searchResults[index], true
+}
+
+// onListChanged 在列表选中项变化时跳过分组标题，并在列表获得焦点时显示详情
+func onListChanged(index int, mainText string, secondaryText string, shortcut rune) {
+	if index < 0 || materializing {
+		return
+	}
+
+	// 移动选中项只是浏览，不是有意的查询，这期间任何代码都不能记入历史（见 addToHistory）
+	wasChanging := listChanging
+	listChanging = true
+	defer func() { listChanging = wasChanging }()
+
+	selectedWord, ok := rowWord(index)
+	if !ok {
+		return
+	}
+
+	// 分组标题不可选中，沿移动方向跳到最近的单词
+	if selectedWord == "" {
+		dir := 1
+		if index < lastListIndex {
+			dir = -1
+		}
+		if next := nearestSelectable(index, dir); next >= 0 && next != index {
+			wordList.SetCurrentItem(next)
+		}
+		return
+	}
+	lastListIndex = index
+	showPlainRow(index)
+
+	// 只有当焦点在列表上时才响应（不添加到历史记录）
+	if app.GetFocus() == wordList {
+		loadDetail(selectedWord)
+		prefetchDetails(index)
+	}
+	shiftWindow(index)
+}
+
+// clearWordList 清空单词列表及其着色信息
+func clearWordList() {
+	wordList.Clear()
+	rowMarkup, rowPlain, plainRow = nil, nil, -1
+	initialHistoryRows = 0
+	virtualTexts = nil
+	cancelPrefetch()
+	searchMutex.Lock()
+	listOffset = 0
+	searchMutex.Unlock()
+}
+
+// showPlainRow 让选中行显示不带颜色的文本，其余行恢复颜色标记
+//
+// tview 的列表在选中行上仍使用文本自带的前景色，彩色标记会和选中背景混在一起
+func showPlainRow(index int) {
+	if len(rowMarkup) != wordList.GetItemCount() {
+		return
+	}
+	if plainRow >= 0 && plainRow < len(rowMarkup) {
+		wordList.SetItemText(plainRow, rowMarkup[plainRow], "")
+	}
+	plainRow = -1
+	if index >= 0 && index < len(rowPlain) {
+		wordList.SetItemText(index, rowPlain[index], "")
+		plainRow = index
+	}
+}
+
+// nearestSelectable 从列表第 index 行开始沿 dir 方向查找最近的非标题行，到头时改为反方向查找
+func nearestSelectable(index, dir int) int {
+	count := wordList.GetItemCount()
+	searchMutex.Lock()
+	defer searchMutex.Unlock()
+
+	for _, d := range []int{dir, -dir} {
+		for i := index; i >= 0 && i < count && listOffset+i < len(searchResults); i += d {
+			if searchResults[listOffset+i] != "" {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// firstSelectable 返回第一个不是分组标题的行，没有时返回 -1
+func firstSelectable(words []string) int {
+	for i, w := range words {
+		if w != "" {
+			return i
+		}
+	}
+	return -1
+}
+
+// buildResultRows 按匹配类型分组，返回列表每行的显示文本及其对应的单词（标题行单词为空）
+func buildResultRows(results []SearchResult) (texts []string, words []string) {
+	for i, r := range results {
+		if i == 0 || results[i-1].Match != r.Match {
+			texts = append(texts, "[gray]── "+r.Match.Label()+" ──[-]")
+			words = append(words, "")
+		}
+		marker, _ := typeMarker(r.Word)
+		text := marker + r.Word
+		if r.Root != "" {
+			// 同一词族的派生词缩进显示在词根之下，最后一个用 └
+			branch := "├"
+			if i == len(results)-1 || results[i+1].Root != r.Root {
+				branch = "└"
+			}
+			text = "[gray]" + branch + "[-] " + text
+		}
+		text += senseCountLabel(r.Senses)
+		if r.User {
+			text += " [darkcyan]（用户词表）[-]"
+		}
+		if r.Ignored {
+			text += " [gray]（已忽略，精确匹配仍显示）[-]"
+		}
+		texts = append(texts, text)
+		words = append(words, r.Word)
+	}
+	return texts, words
+}
+
+// showResultRows 用搜索结果行（见 buildResultRows）填充列表，自动选中第一个单词并显示详情（需在主线程中调用）
+func showResultRows(texts, words []string) {
+	searchMutex.Lock()
+	searchResults = words
+	searchMutex.Unlock()
+
+	clearWordList()
+	lastListIndex = 0
+	first := firstSelectable(words)
+	if len(texts) > virtualWindow {
+		// 结果很多时只加入第一个单词附近的一段，移动时再换段（见 virtuallist.go）
+		virtualTexts = texts
+		materializeWindow(max(first, 0))
+	} else {
+		for i, text := range texts {
+			addResultRow(text, words[i], i)
+		}
+	}
+
+	// 如果有搜索结果，自动选中第一个单词并显示详情
+	if first >= 0 {
+		row := first - listOffset
+		lastListIndex = row
+		wordList.SetCurrentItem(row)
+		loadDetail(words[first])
+		prefetchDetails(row)
+	} else {
+		clearDetail()
+	}
+}
+
+// showInitialWords 显示初始单词列表（搜索历史或随机单词）
+func showInitialWords() {
+	showInitialWordsAt(0)
+}
+
+// initialHistoryRows 初始列表开头显示的历史记录行数，列表显示的是搜索结果或随机单词时为 0
+var initialHistoryRows int
+
+// showInitialWordsAt 显示初始列表并选中第 selected 行（超出范围时选中最后一行）
+//
+// 和搜索一样使用版本号：随机单词查询较慢时用户可能已经开始输入，不能让初始列表覆盖新的搜索结果
+func showInitialWordsAt(selected int) {
+	version := atomic.AddInt64(&searchVersion, 1)
+
+	go func() {
+		var results []string
+		var err error
+		history := getSearchHistory()
+		if len(history) > initialHistoryCount {
+			history = history[:initialHistoryCount]
+		}
+
+		if len(history) == 0 {
+			// 没有历史记录，显示随机单词
+			results, err = getRandomWords(20)
+		} else {
+			// 显示搜索历史
+			results = history
+		}
+
+		app.QueueUpdateDraw(func() {
+			if atomic.LoadInt64(&searchVersion) != version {
+				return
+			}
+			if err != nil {
+				showError(fmt.Errorf("加载随机单词出错: %v", err))
+			}
+
+			// searchResults 和列表行在主线程中一起替换，列表项的点击回调按行号取词时总是对应这份结果
+			searchMutex.Lock()
+			searchResults = results
+			searchMutex.Unlock()
+			clearWordList()
+			lastListIndex = 0
+			initialHistoryRows = len(history)
+			for i, word := range results {
+				index := i
+				// 历史记录前加 ★，已收藏的单词后加 ♥，标记使用主题颜色
+				markup, plain := typeMarker(word)
+				markup, plain = markup+tview.Escape(word), plain+tview.Escape(word)
+				if len(history) > 0 && i < len(history) {
+					markup = colored(config.Theme.HistoryMarker, "★") + " " + markup
+					plain = "★ " + plain
+				}
+				if isFavorite(word) {
+					markup += " " + colored(config.Theme.FavoriteMarker, "♥")
+					plain += " ♥"
+				}
+				rowMarkup = append(rowMarkup, markup)
+				rowPlain = append(rowPlain, plain)
+
+				// 点击时不添加到历史记录，避免卡顿，只查询详情
+				wordList.AddItem(markup, "", 0, func() {
+					selectListItem(index, false)
+				})
+			}
+			if selected >= len(results) {
+				selected = len(results) - 1
+			}
+			if selected > 0 {
+				wordList.SetCurrentItem(selected)
+			}
+			showPlainRow(wordList.GetCurrentItem())
+		})
+	}()
+}
+
+// deleteInitialHistoryEntry 从搜索历史中删除初始列表第 index 行的单词并刷新列表，选中项停留在原来的位置；
+// 该行不是历史记录（随机推荐的单词）时只提示
+func deleteInitialHistoryEntry(index int) {
+	word := wordAt(index)
+	if word == "" || index >= initialHistoryRows {
+		setStatus("[yellow]只能删除带 ★ 的历史记录[-]")
+		return
+	}
+	removeFromHistory(word)
+	setStatus("已从历史记录中删除 " + tview.Escape(word))
+	showInitialWordsAt(index)
+}
+
+// setActiveQuery 记录当前生效的搜索词
+func setActiveQuery(query string) {
+	queryMutex.Lock()
+	activeQuery = query
+	queryMutex.Unlock()
+}
+
+// getActiveQuery 返回当前生效的搜索词，供高亮等功能在焦点离开搜索框后使用
+func getActiveQuery() string {
+	queryMutex.RLock()
+	defer queryMutex.RUnlock()
+	return activeQuery
+}
+
+// onSearchChanged 在搜索框内容变化时异步查询并刷新结果列表
+func onSearchChanged(text string) {
+	searchText := normalizeQuery(text)
+	setActiveQuery(searchText)
+	clearWordList()
+	clearDetail()
+	setStatus("")
+
+	// 取消之前的定时器
+	if inputTimer != nil {
+		inputTimer.Stop()
+		inputTimer = nil
+	}
+
+	// 每次输入都递增版本号
+	currentVersion := atomic.AddInt64(&searchVersion, 1)
+
+	// 筛选只作用于上一次的结果，重新搜索时关闭
+	if filterOpen {
+		closeResultFilter()
+	}
+	resultTexts, resultWords, resultExact = nil, nil, nil
+	resetResultScope(nil)
+
+	if searchText == "" {
+		searchMutex.Lock()
+		searchResults = []string{}
+		searchMutex.Unlock()
+		browsePrefix = ""
+		leftPanel.SetTitle("单词列表")
+		// 显示初始单词列表（历史或随机）
+		showInitialWords()
+		return
+	}
+
+	// 浏览模式下输入的内容作为前缀，按词频翻阅
+	if browseMode {
+		showBrowsePage(searchText, 0)
+		return
+	}
+
+	// 在新的 goroutine 中异步查询
+	go func(query string, version int64) {
+		results, err := search(query)
+
+		// 检查是否是最新版本，如果不是则放弃更新
+		if atomic.LoadInt64(&searchVersion) != version {
+			return
+		}
+
+		addSenseCounts(results)
+		texts, words := buildResultRows(results)
+
+		// 在主线程中更新UI
+		app.QueueUpdateDraw(func() {
+			// 再次检查版本，确保UI更新时也是最新的
+			if atomic.LoadInt64(&searchVersion) != version {
+				return
+			}
+			if errors.Is(err, errDatabaseUpdating) {
+				showUpdating()
+				return
+			}
+			if errors.Is(err, errQueryTimeout) {
+				clearWordList()
+				setStatus(fmt.Sprintf("[yellow]查询超时（超过 %v），请输入更具体的关键词[-]", queryTimeout))
+				return
+			}
+			if err != nil {
+				showError(fmt.Errorf("搜索出错: %v", err))
+			}
+
+			listVersion = version
+			resultTexts, resultWords, resultExact = texts, words, exactMatches(results)
+			resetResultScope(results)
+			showResultRows(texts, words)
+
+			// 输入的是变形词或少了标点时提示显示的是哪个单词的结果
+			if len(results) > 0 && (results[0].Match == MatchLemma || results[0].Match == MatchSpelling) {
+				setStatus(fmt.Sprintf("显示 %s 的结果", tview.Escape(results[0].Word)))
+			}
+		})
+	}(searchText, currentVersion)
+
+	// autoFocusDelay（5秒）后自动将焦点切换到单词列表，并将搜索词添加到历史
+	// 重要：这个定时器在每次输入时都会被重置，只有停止输入5秒后才会触发
+	// 列表必须已经显示这次搜索的结果：查询较慢时列表还是空的，不能把上一次搜索选中的单词记进去
+	inputTimer = time.AfterFunc(autoFocusDelay, func() {
+		app.QueueUpdateDraw(func() {
+			if app.GetFocus() == searchInput && getActiveQuery() != "" &&
+				atomic.LoadInt64(&searchVersion) == currentVersion && listVersion == currentVersion {
+				// 添加当前选中的词到历史记录（与点击共用同一入口，短时间内重复记录会被合并）
+				if word := wordAt(wordList.GetCurrentItem()); word != "" {
+					addToHistory(word)
+				}
+
+				// 切换焦点到单词列表
+				app.SetFocus(wordList)
+			}
+		})
+	})
+}
+
+// handleGlobalKey 处理全局快捷键，绑定了动作的按键交给 runKeyAction
+func handleGlobalKey(event *tcell.EventKey) *tcell.EventKey {
+	if historyDropdownOpen && app.GetFocus() == searchInput {
+		if result, handled := handleHistoryDropdownKey(event); handled {
+			return result
+		}
+	}
+	if filterOpen && app.GetFocus() == resultFilter && event.Key() == tcell.KeyEsc {
+		// 筛选框中 Esc 关闭筛选，而不是退出程序
+		toggleResultFilter()
+		return nil
+	}
+	if action, ok := keyBindings[event.Key()]; ok {
+		runKeyAction(action)
+		return nil
+	} else if event.Key() == tcell.KeyUp || event.Key() == tcell.KeyDown {
+		// 无论焦点在哪里，按上下键时切换到单词列表，并让单词列表处理这个按键事件
+		if app.GetFocus() != wordList {
+			app.SetFocus(wordList)
+		}
+		return event
+	} else if browseMode && (event.Key() == tcell.KeyPgDn || event.Key() == tcell.KeyPgUp) && browsePrefix != "" {
+		// 浏览模式下 PgDn/PgUp 翻页
+		if event.Key() == tcell.KeyPgDn && wordList.GetItemCount() == browsePageSize {
+			showBrowsePage(browsePrefix, browsePage+1)
+		} else if event.Key() == tcell.KeyPgUp && browsePage > 0 {
+			showBrowsePage(browsePrefix, browsePage-1)
+		}
+		return nil
+	} else if app.GetFocus() == wordList && initialHistoryRows > 0 &&
+		(event.Key() == tcell.KeyDelete || (event.Key() == tcell.KeyRune && event.Rune() == 'd')) {
+		// 列表显示最近历史时，Delete 或 d 删除选中的记录
+		deleteInitialHistoryEntry(wordList.GetCurrentItem())
+		return nil
+	} else if app.GetFocus() == resultFilter {
+		// 筛选框自己处理输入的字符
+		return event
+	} else if vimMode && event.Key() == tcell.KeyRune && app.GetFocus() != searchInput {
+		return handleVimKey(event)
+	} else if event.Rune() != 0 && app.GetFocus() != searchInput {
+		// 当焦点不在搜索框时，敲击键盘自动切换到搜索框并清空内容
+		searchInput.SetText("")
+		app.SetFocus(searchInput)
+		// 让搜索框处理这个字符
+		return event
+	}
+	return event
+}
+
+// runKeyAction 执行按键绑定的动作，动作名见 keymap.go
+func runKeyAction(action string) {
+	switch action {
+	case actionQuit:
+		app.Stop()
+	case actionFocusNext:
+		// 在搜索框、单词列表、详情面板间循环切换焦点
+		if app.GetFocus() == searchInput {
+			app.SetFocus(wordList)
+		} else if app.GetFocus() == wordList {
+			app.SetFocus(detailView)
+		} else {
+			app.SetFocus(searchInput)
+		}
+	case actionBrowse:
+		toggleBrowseMode()
+	case actionSplit:
+		toggleSplitLayout()
+	case actionPin:
+		togglePin()
+	case actionPersonalRanking:
+		togglePersonalRanking()
+	case actionFamilies:
+		toggleFamilies()
+	case actionPronounce:
+		playPronunciation()
+	case actionFavorite:
+		toggleCurrentFavorite()
+	case actionRandomFavorite:
+		showRandomFavorite()
+	case actionExpand:
+		expandDetail()
+	case actionHistory:
+		showHistoryView()
+	case actionEmphasis:
+		toggleEmphasis()
+	case actionPhoneticMode:
+		togglePhoneticMode()
+	case actionCopyPhonetic:
+		copyPhonetic()
+	case actionQuiz:
+		showQuizView()
+	case actionBack:
+		goBack()
+	case actionReload:
+		reloadDatabases()
+	case actionWrap:
+		toggleDetailWrap()
+	case actionLock:
+		toggleDetailLock()
+	case actionFilter:
+		toggleResultFilter()
+	case actionLeaderboard:
+		showLeaderboardView()
+	case actionCopyHTML:
+		copyDetailHTML()
+	case actionSurprise:
+		surpriseMe()
+	case actionNextSection:
+		jumpSection(1)
+	case actionPrevSection:
+		jumpSection(-1)
+	case actionShare:
+		copyShareText()
+	case actionScope:
+		cycleResultScope()
+	}
+}
+
+// toggleBrowseMode 在搜索模式和按首字母浏览模式之间切换
+func toggleBrowseMode() {
+	browseMode = !browseMode
+	browsePrefix = ""
+	browsePage = 0
+	if browseMode {
+		searchInput.SetLabel("浏览: ").SetPlaceholder("输入首字母按词频翻阅，PgDn/PgUp 翻页...")
+	} else {
+		searchInput.SetLabel("搜索: ").SetPlaceholder("输入中文或英文...")
+	}
+	leftPanel.SetTitle("单词列表")
+	searchInput.SetText("")
+	app.SetFocus(searchInput)
+}
+
+// showBrowsePage 异步加载以 prefix 开头的第 page 页单词
+func showBrowsePage(prefix string, page int) {
+	version := atomic.AddInt64(&searchVersion, 1)
+
+	go func() {
+		results, err := getBrowseWords(prefix, page)
+
+		if atomic.LoadInt64(&searchVersion) != version {
+			return
+		}
+
+		app.QueueUpdateDraw(func() {
+			if atomic.LoadInt64(&searchVersion) != version {
+				return
+			}
+
+			if err != nil {
+				showError(fmt.Errorf("浏览出错: %v", err))
+				return
+			}
+
+			browsePrefix = prefix
+			browsePage = page
+			leftPanel.SetTitle(fmt.Sprintf("浏览 %s · 第%d页", prefix, page+1))
+
+			searchMutex.Lock()
+			searchResults = results
+			searchMutex.Unlock()
+			clearWordList()
+			lastListIndex = 0
+			for i, word := range results {
+				index := i
+				wordList.AddItem(word, "", 0, func() {
+					selectListItem(index, true)
+				})
+			}
+			if len(results) > 0 {
+				wordList.SetCurrentItem(0)
+				loadDetail(results[0])
+			}
+		})
+	}()
+}
+
+// handleVimKey 在 vim 模式下处理焦点不在搜索框时的字符按键
+func handleVimKey(event *tcell.EventKey) *tcell.EventKey {
+	r := event.Rune()
+	if r != 'g' {
+		pendingG = false
+	}
+
+	switch r {
+	case 'j', 'k':
+		// 转换为方向键交给单词列表处理，从而复用分组标题的跳过逻辑
+		app.SetFocus(wordList)
+		key := tcell.KeyDown
+		if r == 'k' {
+			key = tcell.KeyUp
+		}
+		return tcell.NewEventKey(key, 0, tcell.ModNone)
+	case 'g':
+		if pendingG {
+			pendingG = false
+			app.SetFocus(wordList)
+			if first := nearestSelectable(scrollWindowTo(0), 1); first >= 0 {
+				selectRow(first)
+			}
+		} else {
+			pendingG = true
+		}
+	case 'G':
+		app.SetFocus(wordList)
+		searchMutex.Lock()
+		total := len(searchResults)
+		searchMutex.Unlock()
+		if last := nearestSelectable(scrollWindowTo(total-1), -1); last >= 0 {
+			selectRow(last)
+		}
+	case '/':
+		searchInput.SetText("")
+		app.SetFocus(searchInput)
+	}
+	return nil
+}
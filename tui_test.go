@@ -0,0 +1,306 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// testScreen startTestUI 创建的模拟终端，测试通过它输入按键
+var testScreen tcell.SimulationScreen
+
+// startTestUI 用测试词典和模拟终端运行交互界面，测试结束时退出
+func startTestUI(t *testing.T) {
+	t.Helper()
+	useFixtureDatabases(t)
+	resetHistory(t)
+	keyBindings, _ = Keymap{}.bindings()
+	enterAction = enterLoad
+
+	app = tview.NewApplication()
+	testScreen = tcell.NewSimulationScreen("UTF-8")
+	if err := testScreen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	testScreen.SetSize(120, 30)
+	app.SetScreen(testScreen)
+	root := newMainLayout()
+
+	done := make(chan error, 1)
+	go func() { done <- app.SetRoot(root, true).Run() }()
+	t.Cleanup(func() {
+		app.Stop()
+		if err := <-done; err != nil {
+			t.Error(err)
+		}
+		personalRanking.Store(false)
+		groupFamilies.Store(false)
+		emphasisMode, phoneticMode = false, false
+	})
+	onMain(t, func() {})
+}
+
+// onMain 在界面的主线程中执行 fn 并等待它完成
+func onMain(t *testing.T, fn func()) {
+	t.Helper()
+	done := make(chan struct{})
+	app.QueueUpdateDraw(func() {
+		fn()
+		close(done)
+	})
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("界面 5 秒内没有响应")
+	}
+}
+
+// typeKeys 在模拟终端中依次输入文字，每个字符之间稍作停顿
+func typeKeys(text string) {
+	for _, r := range text {
+		testScreen.InjectKey(tcell.KeyRune, r, tcell.ModNone)
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// pressKey 在模拟终端中按下一个特殊按键
+func pressKey(key tcell.Key) {
+	testScreen.InjectKey(key, 0, tcell.ModNone)
+	time.Sleep(5 * time.Millisecond)
+}
+
+// TestConcurrentSearchResultAccess 在快速输入、切换排序和显示方式的同时从其他协程按行号取词和搜索，
+// 用 go test -race 运行时检查 searchResults 和各个开关没有数据竞争
+func TestConcurrentSearchResultAccess(t *testing.T) {
+	startTestUI(t)
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				for row := -1; row < 30; row++ {
+					rowWord(row)
+				}
+				if _, err := search("app"); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 5; i++ {
+		typeKeys("app")
+		pressKey(tcell.KeyF5) // 按查阅次数排序
+		typeKeys("l")
+		pressKey(tcell.KeyCtrlG) // 按词族分组
+		pressKey(tcell.KeyF11)   // 加强显示词头
+		for j := 0; j < 4; j++ {
+			pressKey(tcell.KeyBackspace2)
+		}
+		typeKeys("nation")
+		pressKey(tcell.KeyF12) // 发音练习
+		pressKey(tcell.KeyTab)
+		pressKey(tcell.KeyDown)
+		pressKey(tcell.KeyDown)
+		pressKey(tcell.KeyUp)
+		onMain(t, func() { app.SetFocus(searchInput) })
+		for j := 0; j < 6; j++ {
+			pressKey(tcell.KeyBackspace2)
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	// 最后一次输入的结果显示出来后，列表中的行与 searchResults 一致
+	typeKeys("dog")
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var count int
+		var first string
+		onMain(t, func() {
+			count = wordList.GetItemCount()
+			first = wordAt(1)
+		})
+		if first == "dog" && count > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("搜索 dog 后列表第 1 行为 %q", first)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// TestStaleRowSelect 列表建好后 searchResults 被换成更短的结果，再选择原来的行时不会越界，也不会记入历史
+func TestStaleRowSelect(t *testing.T) {
+	startTestUI(t)
+
+	results, err := searchEnglish("apple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	texts, words := buildResultRows(results)
+	if len(words) < 5 {
+		t.Fatalf("apple 的结果行太少: %v", words)
+	}
+	stale := len(words) - 1
+	onMain(t, func() { showResultRows(texts, words) })
+
+	// 新的搜索已经替换了 searchResults，列表中还是原来的行
+	searchMutex.Lock()
+	searchResults = []string{"", "dog"}
+	searchMutex.Unlock()
+
+	onMain(t, func() {
+		defer func() {
+			if r := recover(); r != nil {
+				t.Errorf("选择第 %d 行时出错: %v", stale, r)
+			}
+		}()
+		wordList.SetCurrentItem(stale)
+		// 与在列表中按 Enter 相同，执行这一行 AddItem 时设置的回调
+		wordList.InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), func(tview.Primitive) {})
+	})
+	if history := getSearchHistory(); len(history) != 0 {
+		t.Errorf("选择已不存在的行后搜索历史为 %v，应为空", history)
+	}
+}
+
+// waitFor 在主线程中反复检查 cond，5 秒内不成立时以 what 报错
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var ok bool
+		onMain(t, func() { ok = cond() })
+		if ok {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("等待%s超时", what)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// TestArrowNavigationSkipsHistory 用方向键在列表中移动只显示详情，按 Enter 才记入历史
+func TestArrowNavigationSkipsHistory(t *testing.T) {
+	startTestUI(t)
+
+	typeKeys("app")
+	waitFor(t, "搜索 app 的结果", func() bool {
+		return wordList.GetItemCount() > 3 && strings.HasPrefix(strings.ToLower(wordAt(1)), "app")
+	})
+	pressKey(tcell.KeyTab)
+	waitFor(t, "焦点切换到列表", func() bool { return app.GetFocus() == wordList })
+
+	for _, key := range []tcell.Key{tcell.KeyDown, tcell.KeyDown, tcell.KeyUp} {
+		pressKey(key)
+	}
+	var selected string
+	waitFor(t, "详情显示选中的单词", func() bool {
+		selected = wordAt(wordList.GetCurrentItem())
+		return selected != "" && currentWord == selected
+	})
+	if history := getSearchHistory(); len(history) != 0 {
+		t.Fatalf("用方向键移动后搜索历史为 %v，应为空", history)
+	}
+
+	pressKey(tcell.KeyEnter)
+	waitFor(t, "按 Enter 后记入历史", func() bool { return len(getSearchHistory()) > 0 })
+	if history := getSearchHistory(); len(history) != 1 || history[0] != selected {
+		t.Errorf("按 Enter 后搜索历史为 %v，应为 [%s]", history, selected)
+	}
+	if count := getLookupCount(selected); count != 1 {
+		t.Errorf("%s 的查阅次数为 %d，应为 1", selected, count)
+	}
+}
+
+// TestAddToHistoryWhileListChanging 移动选中项期间经由其他代码调用 addToHistory 也不会记录
+func TestAddToHistoryWhileListChanging(t *testing.T) {
+	resetHistory(t)
+	listChanging = true
+	addToHistory("apple")
+	listChanging = false
+	if history := getSearchHistory(); len(history) != 0 {
+		t.Errorf("移动选中项期间记入了历史: %v", history)
+	}
+	addToHistory("apple")
+	if history := getSearchHistory(); len(history) != 1 {
+		t.Errorf("搜索历史为 %v，应为 [apple]", history)
+	}
+}
+
+// TestAutoFocusAndSelectRecordOnce 输入后的自动聚焦定时器和在列表中选择（点击或 Enter）先后记录同一个单词时只算一次，
+// 不论哪一个先发生
+func TestAutoFocusAndSelectRecordOnce(t *testing.T) {
+	saved := autoFocusDelay
+	autoFocusDelay = 300 * time.Millisecond
+	t.Cleanup(func() { autoFocusDelay = saved })
+
+	// selectRow 执行第 1 行（dog）在 AddItem 时设置的回调，与点击或按 Enter 相同，不改变焦点
+	selectRow := func() {
+		wordList.SetCurrentItem(1)
+		wordList.InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), func(tview.Primitive) {})
+	}
+	check := func(t *testing.T, when string) {
+		t.Helper()
+		if history := getSearchHistory(); !slices.Equal(history, []string{"dog", "apple"}) {
+			t.Errorf("%s搜索历史为 %v，应为 [dog apple]", when, history)
+		}
+		if count := getLookupCount("dog"); count != 1 {
+			t.Errorf("%s dog 的查阅次数为 %d，应为 1", when, count)
+		}
+	}
+
+	for _, tt := range []struct {
+		name       string
+		timerFirst bool
+	}{{"定时器先触发", true}, {"先选择", false}} {
+		t.Run(tt.name, func(t *testing.T) {
+			startTestUI(t)
+			// 之前查过的单词，检查记录 dog 之后的顺序
+			addToHistory("apple")
+
+			typeKeys("dog")
+			waitFor(t, "搜索 dog 的结果", func() bool {
+				return listVersion == atomic.LoadInt64(&searchVersion) && wordAt(1) == "dog"
+			})
+			if tt.timerFirst {
+				waitFor(t, "定时器切换焦点", func() bool { return app.GetFocus() == wordList })
+				check(t, "定时器触发后")
+				onMain(t, selectRow)
+				check(t, "定时器触发后再选择")
+				return
+			}
+
+			var early bool
+			onMain(t, func() {
+				if early = app.GetFocus() != searchInput; !early {
+					selectRow()
+				}
+			})
+			if early {
+				t.Fatal("定时器在选择之前就触发了")
+			}
+			check(t, "选择后")
+			waitFor(t, "定时器切换焦点", func() bool { return app.GetFocus() == wordList })
+			check(t, "选择后定时器触发")
+		})
+	}
+}
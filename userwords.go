@@ -0,0 +1,201 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// userWordSource 用户词表中的词条在详情和 HTTP 接口中显示的来源
+const userWordSource = "用户词表"
+
+var (
+	userWords      []Word          // 用户词表中的词条，保持文件中的顺序
+	userWordIndex  map[string]Word // 单词 → 词条，用于查询详情
+	userWordsMutex sync.RWMutex    // 保护 userWords 和 userWordIndex，重新加载时会整体替换
+)
+
+// userWordJSON JSON 格式用户词表中的一条记录
+type userWordJSON struct {
+	Word        string `json:"word"`
+	Phonetic    string `json:"phonetic"`
+	Translation string `json:"translation"`
+	Definition  string `json:"definition"`
+}
+
+// loadUserWords 读取用户词表，替换当前内存中的词条；文件不存在时清空词表并返回 nil，读取失败时保留原来的词条
+//
+// .json 文件是 userWordJSON 的数组，其他文件按 CSV 读取，列依次为 word、phonetic、translation、definition，
+// 第一行是 word 开头的表头时跳过，后面的列可以省略。同一个单词出现多次时以最后一次为准
+func loadUserWords(path string) error {
+	var words []Word
+	if path != "" {
+		var err error
+		words, err = readUserWords(path)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("无法读取用户词表 %s: %v", path, err)
+		}
+	}
+
+	index := make(map[string]Word, len(words))
+	var unique []Word
+	for _, w := range words {
+		if _, exists := index[w.Word]; !exists {
+			unique = append(unique, w)
+		}
+		index[w.Word] = w
+	}
+	for i, w := range unique {
+		unique[i] = index[w.Word]
+	}
+
+	userWordsMutex.Lock()
+	userWords, userWordIndex = unique, index
+	userWordsMutex.Unlock()
+	if len(unique) > 0 {
+		logInfof("已读取用户词表 %s，共 %d 个词条", path, len(unique))
+	}
+	return nil
+}
+
+// readUserWords 按扩展名解析用户词表文件，忽略单词为空的记录
+func readUserWords(path string) ([]Word, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var words []Word
+	add := func(word, phonetic, translation, definition string) {
+		word = normalizeQuery(word)
+		if word == "" {
+			return
+		}
+		words = append(words, Word{
+			Word:        word,
+			Phonetic:    strings.TrimSpace(phonetic),
+			Translation: strings.TrimSpace(translation),
+			Definition:  strings.TrimSpace(definition),
+			Source:      userWordSource,
+		})
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		var entries []userWordJSON
+		if err := json.NewDecoder(f).Decode(&entries); err != nil {
+			return nil, fmt.Errorf("格式错误: %v", err)
+		}
+		for _, e := range entries {
+			add(e.Word, e.Phonetic, e.Translation, e.Definition)
+		}
+		return words, nil
+	}
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	for line := 1; ; line++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if line == 1 && strings.EqualFold(strings.TrimSpace(record[0]), "word") {
+			continue
+		}
+		fields := make([]string, 4)
+		copy(fields, record)
+		add(fields[0], fields[1], fields[2], fields[3])
+	}
+	return words, nil
+}
+
+// lookupUserWord 返回用户词表中的词条
+func lookupUserWord(word string) (Word, bool) {
+	userWordsMutex.RLock()
+	defer userWordsMutex.RUnlock()
+	w, ok := userWordIndex[word]
+	return w, ok
+}
+
+// isUserWord 判断 word 是否来自用户词表
+func isUserWord(word string) bool {
+	_, ok := lookupUserWord(word)
+	return ok
+}
+
+// mergeUserWords 把用户词表中与 query 匹配的词条合并到搜索结果中，标记为 User
+//
+// 英文查询按不区分大小写的精确、前缀和包含匹配，分别放在对应分组的最前面；词库中的同名词条被用户词条取代。
+// 中文查询在用户词条的中文释义中查找，归入「释义中提到」分组。合并后的结果仍不超过 searchLimit，超出时优先保留用户词条
+func mergeUserWords(query string, results []SearchResult) []SearchResult {
+	userWordsMutex.RLock()
+	words := userWords
+	userWordsMutex.RUnlock()
+	if len(words) == 0 {
+		return results
+	}
+
+	var exact, prefix, contains, mentioned []SearchResult
+	lower := strings.ToLower(query)
+	for _, w := range words {
+		word := strings.ToLower(w.Word)
+		switch {
+		case isChinese(query):
+			if strings.Contains(w.Translation, query) {
+				mentioned = append(mentioned, SearchResult{Word: w.Word, Match: MatchCrossLanguage, User: true})
+			}
+		case word == lower:
+			exact = append(exact, SearchResult{Word: w.Word, Match: MatchExact, User: true})
+		case strings.HasPrefix(word, lower):
+			prefix = append(prefix, SearchResult{Word: w.Word, Match: MatchPrefix, User: true})
+		case len([]rune(lower)) >= containsMinLength && strings.Contains(word, lower):
+			contains = append(contains, SearchResult{Word: w.Word, Match: MatchContains, User: true})
+		}
+	}
+	if len(exact)+len(prefix)+len(contains)+len(mentioned) == 0 {
+		return results
+	}
+
+	// 去掉与用户词条同名的词库结果；超出 searchLimit 时截掉词库结果末尾的部分，保证用户词条都能显示
+	var rest []SearchResult
+	for _, r := range results {
+		if !isUserWord(r.Word) {
+			rest = append(rest, r)
+		}
+	}
+	if room := searchLimit - len(exact) - len(prefix) - len(contains) - len(mentioned); len(rest) > room {
+		if room < 0 {
+			room = 0
+		}
+		rest = rest[:room]
+	}
+
+	// 精确匹配在最前；前缀匹配接在词库的精确匹配、原形和其他写法之后；包含匹配接在词库的前缀匹配之后
+	head := 0
+	for head < len(rest) && (rest[head].Match == MatchExact || rest[head].Match == MatchLemma || rest[head].Match == MatchSpelling) {
+		head++
+	}
+	mid := head
+	for mid < len(rest) && rest[mid].Match == MatchPrefix {
+		mid++
+	}
+	merged := append([]SearchResult{}, exact...)
+	merged = append(merged, rest[:head]...)
+	merged = append(merged, prefix...)
+	merged = append(merged, rest[head:mid]...)
+	merged = append(merged, contains...)
+	merged = append(merged, rest[mid:]...)
+	merged = append(merged, mentioned...)
+	if len(merged) > searchLimit {
+		merged = merged[:searchLimit]
+	}
+	return merged
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"github.com/gdamore/tcell/v2"
+)
+
+// 结果很多时列表只加入选中项附近的一段行，移动到这段的边缘时再换成新位置附近的一段
+const (
+	virtualWindow = 300 // 列表中同时存在的行数，结果不超过这个数时全部加入
+	virtualMargin = 30  // 选中项距离这段的首尾少于这么多行时换段
+)
+
+var (
+	virtualTexts  []string // 分段显示时全部结果行的文本，为空表示列表包含了全部行
+	listOffset    int      // 列表第 0 行在 searchResults 中的位置，由 searchMutex 保护
+	materializing bool     // 正在替换列表中的行，期间忽略选中项的变化
+)
+
+// addResultRow 把一行结果加入列表，row 是它在列表中的位置；分组标题行不响应选择
+func addResultRow(text, word string, row int) {
+	if word == "" {
+		wordList.AddItem(text, "", 0, nil)
+		return
+	}
+	wordList.AddItem(text, "", 0, func() {
+		// 添加到历史记录并加载详细信息
+		selectListItem(row, true)
+	})
+}
+
+// materializeWindow 把列表换成第 center 个结果附近的 virtualWindow 行并选中它（需在主线程中调用）
+//
+// 列表中第 i 行对应 searchResults[listOffset+i]，wordAt 等按列表行号查询的函数都会加上这个偏移
+func materializeWindow(center int) {
+	total := len(virtualTexts)
+	start := center - virtualWindow/2
+	if start > total-virtualWindow {
+		start = total - virtualWindow
+	}
+	if start < 0 {
+		start = 0
+	}
+	end := start + virtualWindow
+	if end > total {
+		end = total
+	}
+
+	searchMutex.Lock()
+	listOffset = start
+	words := searchResults
+	searchMutex.Unlock()
+	if end > len(words) {
+		// virtualTexts 和 searchResults 总是一起替换，这里只是防止两者长度不一致时越界
+		end = len(words)
+	}
+
+	materializing = true
+	wordList.Clear()
+	for i := start; i < end; i++ {
+		addResultRow(virtualTexts[i], words[i], i-start)
+	}
+	lastListIndex = center - start
+	wordList.SetCurrentItem(center - start)
+	materializing = false
+}
+
+// shiftWindow 选中项接近列表中这一段的首尾、而前后还有没加入的结果时，换成以它为中心的一段
+func shiftWindow(row int) {
+	if len(virtualTexts) == 0 {
+		return
+	}
+	count := wordList.GetItemCount()
+	nearStart := row < virtualMargin && listOffset > 0
+	nearEnd := row >= count-virtualMargin && listOffset+count < len(virtualTexts)
+	if nearStart || nearEnd {
+		materializeWindow(listOffset + row)
+	}
+}
+
+// scrollWindowTo 分段显示时确保第 index 个结果在列表中（跳到首尾时使用），返回它在列表中的行号
+func scrollWindowTo(index int) int {
+	if len(virtualTexts) > 0 && (index < listOffset || index >= listOffset+wordList.GetItemCount()) {
+		materializeWindow(index)
+	}
+	return index - listOffset
+}
+
+// handleVirtualListKey 分段显示时让 Home/End 跳到全部结果的首尾，而不只是列表中这一段的首尾
+func handleVirtualListKey(event *tcell.EventKey) *tcell.EventKey {
+	if len(virtualTexts) == 0 {
+		return event
+	}
+	searchMutex.Lock()
+	total := len(searchResults)
+	searchMutex.Unlock()
+
+	switch event.Key() {
+	case tcell.KeyHome:
+		if first := nearestSelectable(scrollWindowTo(0), 1); first >= 0 {
+			selectRow(first)
+		}
+		return nil
+	case tcell.KeyEnd:
+		if last := nearestSelectable(scrollWindowTo(total-1), -1); last >= 0 {
+			selectRow(last)
+		}
+		return nil
+	}
+	return event
+}
+
+// selectRow 选中列表第 row 行；换段后该行可能已经是选中项，此时列表不会通知变化，直接按选中处理
+func selectRow(row int) {
+	if row == wordList.GetCurrentItem() {
+		onListChanged(row, "", "", 0)
+		return
+	}
+	wordList.SetCurrentItem(row)
+}
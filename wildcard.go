@@ -0,0 +1,50 @@
+package main
+
+import (
+	"strings"
+)
+
+// likeEscaper 转义 LIKE 模式中的通配符 % 和 _ 以及转义字符本身，配合 ESCAPE '\' 使用
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike 返回可以安全拼进 LIKE 模式的字面文本，如 100% → 100\%
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// parseWildcard 解析英文查询开头和结尾的 *：pre* 为前缀搜索，*tion 为后缀搜索，*ab* 为包含搜索
+//
+// 返回去掉 * 后的词干和两端是否有 *；没有 * 或去掉后为空时 ok 为 false。中间的 * 按普通字符处理
+func parseWildcard(keyword string) (stem string, leading, trailing, ok bool) {
+	leading = strings.HasPrefix(keyword, "*")
+	trailing = strings.HasSuffix(keyword, "*")
+	stem = strings.Trim(keyword, "*")
+	return stem, leading, trailing, (leading || trailing) && stem != ""
+}
+
+// searchWildcard 按 * 的位置把查询转换为 LIKE 模式并查找，词干中的 % 和 _ 按字面匹配
+//
+// 结果归入前缀匹配、后缀匹配或包含匹配一个分组；以 * 开头时有片段索引则只检查含有词干中最少见片段的单词
+func searchWildcard(stem string, leading, trailing bool) ([]SearchResult, error) {
+	pattern := escapeLike(stem)
+	match := MatchPrefix
+	switch {
+	case leading && trailing:
+		pattern, match = "%"+pattern+"%", MatchContains
+	case leading:
+		pattern, match = "%"+pattern, MatchSuffix
+	default:
+		pattern += "%"
+	}
+
+	filter := properNounFilter(config.HideProperNounsInSearch)
+	query := `SELECT word FROM words WHERE word LIKE ? ESCAPE '\'` + filter + ` LIMIT ?`
+	args := []interface{}{pattern, searchLimit}
+	if trigrams := wordTrigrams(stem); leading && hasTrigramIndex && len(trigrams) > 0 {
+		rarest, trigramArgs := rarestTrigram(trigrams)
+		query = `SELECT word FROM word_trigrams t JOIN words ON words.id = t.word_id
+			WHERE t.trigram = ` + rarest + ` AND word LIKE ? ESCAPE '\'` + filter + ` LIMIT ?`
+		args = append(trigramArgs, args...)
+	}
+	return collectMatches(englishDB, match, nil, make(map[string]bool), query, args...)
+}
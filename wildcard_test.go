@@ -0,0 +1,149 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseWildcard(t *testing.T) {
+	tests := []struct {
+		keyword           string
+		stem              string
+		leading, trailing bool
+		ok                bool
+	}{
+		{"*tion", "tion", true, false, true},
+		{"pre*", "pre", false, true, true},
+		{"*struct*", "struct", true, true, true},
+		{"**tion", "tion", true, false, true},
+		// 中间的 * 按普通字符处理
+		{"a*b", "a*b", false, false, false},
+		{"*a*b", "a*b", true, false, true},
+		// 没有 * 或只有 *
+		{"apple", "apple", false, false, false},
+		{"*", "", true, true, false},
+		{"**", "", true, true, false},
+		// 其他 LIKE 通配符不是通配符语法
+		{"100%", "100%", false, false, false},
+		{"a_b", "a_b", false, false, false},
+		{"100%*", "100%", false, true, true},
+	}
+	for _, tt := range tests {
+		stem, leading, trailing, ok := parseWildcard(tt.keyword)
+		if stem != tt.stem || leading != tt.leading || trailing != tt.trailing || ok != tt.ok {
+			t.Errorf("parseWildcard(%q) = %q, %v, %v, %v，应为 %q, %v, %v, %v",
+				tt.keyword, stem, leading, trailing, ok, tt.stem, tt.leading, tt.trailing, tt.ok)
+		}
+	}
+}
+
+func TestSearchEnglishWildcards(t *testing.T) {
+	tests := []struct {
+		query string
+		match MatchType
+		want  []string
+	}{
+		{"*tion", MatchSuffix, []string{"action", "construction", "nation"}},
+		{"nation*", MatchPrefix, []string{"nation", "national", "nationality"}},
+		{"*struct*", MatchContains, []string{"construct", "construction", "struct", "structure"}},
+		{"*zzz", MatchSuffix, nil},
+	}
+	useFixtureDatabases(t)
+	for _, index := range []bool{true, false} {
+		withTrigramIndex(index, func() {
+			for _, tt := range tests {
+				results, err := searchEnglish(tt.query)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got := wordsOf(results)
+				slices.Sort(got)
+				if !slices.Equal(got, tt.want) {
+					t.Errorf("片段索引 %v 时 searchEnglish(%q) = %v，应为 %v", index, tt.query, got, tt.want)
+				}
+				for _, r := range results {
+					if r.Match != tt.match {
+						t.Errorf("searchEnglish(%q) 中 %s 为%s，应为%s", tt.query, r.Word, r.Match.Label(), tt.match.Label())
+					}
+				}
+			}
+		})
+	}
+
+	// 关闭通配符时 * 按普通字符查找
+	config.Wildcards = false
+	results, err := searchEnglish("nation*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 0 {
+		t.Errorf("关闭通配符时 searchEnglish(nation*) = %v，应没有结果", wordsOf(results))
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	tests := []struct {
+		text, want string
+	}{
+		{"apple", "apple"},
+		{"100%", `100\%`},
+		{"a_b", `a\_b`},
+		{`back\slash`, `back\\slash`},
+		{`50%_\`, `50\%\_\\`},
+		{"*tion", "*tion"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := escapeLike(tt.text); got != tt.want {
+			t.Errorf("escapeLike(%q) = %q，应为 %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSearchEnglishLikeWildcardsLiteral(t *testing.T) {
+	useFixtureDatabases(t)
+
+	// % 和 _ 按字面匹配：100% 不会找到 100、1000，a_b 不会找到 alba、arbor
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"100%", nil},
+		{"a_b", nil},
+		{"%", nil},
+		{"___", nil},
+		{"10_", nil},
+	}
+	check := func(indexes ...bool) {
+		t.Helper()
+		for _, tt := range tests {
+			for _, index := range indexes {
+				withTrigramIndex(index, func() {
+					results, err := searchEnglish(tt.query)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if got := wordsOf(results); !slices.Equal(got, tt.want) {
+						t.Errorf("片段索引 %v 时 searchEnglish(%q) = %v，应为 %v", index, tt.query, got, tt.want)
+					}
+				})
+			}
+		}
+	}
+	check(true, false)
+
+	// 词库中有带 % 和 _ 的词条时只找到它们本身（直接插入的词条没有片段索引，只检查全表扫描）
+	if _, err := englishDB.Exec(`INSERT INTO words (word, translation) VALUES ('100%', '百分之百'), ('a_b', '测试')`); err != nil {
+		t.Fatal(err)
+	}
+	tests = []struct {
+		query string
+		want  []string
+	}{
+		{"100%", []string{"100%"}},
+		{"a_b", []string{"a_b"}},
+		{"00%", []string{"100%"}},
+		{"a_", []string{"a_b"}},
+	}
+	check(false)
+}
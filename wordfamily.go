@@ -0,0 +1,90 @@
+package main
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// groupFamilies 为 true 时把同一词族的单词（nation、national、nationality）集中显示在词根之下，
+// 界面中切换时搜索协程可能正在读取
+var groupFamilies atomic.Bool
+
+const minFamilyRoot = 3 // 作为词根的单词至少要有的字母数，避免 an、in 之类的短词吸收大量无关单词
+
+// familySuffixes 判断派生词时认可的词尾，派生词去掉词根后的部分必须能完整拆分成这些词尾（如 al + ity）
+var familySuffixes = []string{
+	"s", "es", "ed", "d", "ing", "er", "est", "ly", "y", "ies", "ier", "iest", "ily",
+	"al", "ial", "ity", "ness", "ment", "ion", "tion", "ation", "ist", "ism", "ize", "ise",
+	"ful", "less", "able", "ible", "ive", "ous", "ic", "ical", "ance", "ence", "ant", "ent",
+	"ship", "hood", "ure", "ary", "ory", "en", "ish",
+}
+
+// groupByFamily 把派生词移到同一结果列表中最先出现的词根之后，并记下它们的词根
+//
+// 派生词沿用词根的匹配方式，列表中显示在词根的分组下；没有找到词根的单词保持原来的位置
+func groupByFamily(results []SearchResult) []SearchResult {
+	var roots []int               // 作为词根的结果在 clusters 中的下标，按出现顺序
+	var clusters [][]SearchResult // 每个词根及其派生词
+	for _, r := range results {
+		joined := false
+		if !strings.ContainsAny(r.Word, " -'") {
+			for _, c := range roots {
+				root := clusters[c][0]
+				if derivedFrom(r.Word, root.Word) {
+					r.Root = root.Word
+					r.Match = root.Match
+					clusters[c] = append(clusters[c], r)
+					joined = true
+					break
+				}
+			}
+		}
+		if !joined {
+			clusters = append(clusters, []SearchResult{r})
+			if len(r.Word) >= minFamilyRoot && lowerWordRegex.MatchString(r.Word) {
+				roots = append(roots, len(clusters)-1)
+			}
+		}
+	}
+
+	grouped := make([]SearchResult, 0, len(results))
+	for _, c := range clusters {
+		grouped = append(grouped, c...)
+	}
+	return grouped
+}
+
+// derivedFrom 判断 word 是否由 root 加上词尾构成，词根末尾的 e、y 和重复的辅音按常见拼写规则处理
+// （create → creation、happy → happiness、run → running）
+func derivedFrom(word, root string) bool {
+	word = strings.ToLower(word)
+	if len(root) < minFamilyRoot || len(word) <= len(root) {
+		return false
+	}
+	stems := []string{root, root + root[len(root)-1:]}
+	switch root[len(root)-1] {
+	case 'e':
+		stems = append(stems, root[:len(root)-1])
+	case 'y':
+		stems = append(stems, root[:len(root)-1]+"i")
+	}
+	for _, stem := range stems {
+		if rest, ok := strings.CutPrefix(word, stem); ok && splitsIntoSuffixes(rest) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitsIntoSuffixes 判断 rest 能否完整拆分成 familySuffixes 中的词尾（rest 不能为空）
+func splitsIntoSuffixes(rest string) bool {
+	if rest == "" {
+		return false
+	}
+	for _, s := range familySuffixes {
+		if after, ok := strings.CutPrefix(rest, s); ok && (after == "" || splitsIntoSuffixes(after)) {
+			return true
+		}
+	}
+	return false
+}
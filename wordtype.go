@@ -0,0 +1,55 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// 列表中单词类型标记的分类
+const (
+	wordTypeWord         = "word"         // 普通单词，不加标记
+	wordTypePhrase       = "phrase"       // 含空格的词组，如 give up
+	wordTypeAbbreviation = "abbreviation" // 全大写的短缩写，如 NASA、U.S.
+)
+
+// 列表中各类型使用的标记字符
+const (
+	phraseMarker       = "◇"
+	abbreviationMarker = "Ⓐ"
+)
+
+// abbreviationRegex 匹配缩写：2-6 个大写字母，可以夹杂数字和点（如 CD、MP3、U.S.）
+var abbreviationRegex = regexp.MustCompile(`^[A-Z][A-Z0-9.&]{1,7}$`)
+
+// wordType 只根据拼写判断单词的类型，不查询数据库：含空格为词组，全大写且较短为缩写
+func wordType(word string) string {
+	if strings.Contains(word, " ") {
+		return wordTypePhrase
+	}
+	if abbreviationRegex.MatchString(word) {
+		letters := 0
+		for _, r := range word {
+			if r >= 'A' && r <= 'Z' {
+				letters++
+			}
+		}
+		if letters >= 2 && letters <= 6 {
+			return wordTypeAbbreviation
+		}
+	}
+	return wordTypeWord
+}
+
+// typeMarker 返回列表中放在单词前面的类型标记（带主题颜色）及其纯文本形式，普通单词或关闭 typeMarkers 时返回空字符串
+func typeMarker(word string) (markup, plain string) {
+	if !config.TypeMarkers {
+		return "", ""
+	}
+	switch wordType(word) {
+	case wordTypePhrase:
+		return colored(config.Theme.PhraseMarker, phraseMarker) + " ", phraseMarker + " "
+	case wordTypeAbbreviation:
+		return colored(config.Theme.AbbreviationMarker, abbreviationMarker) + " ", abbreviationMarker + " "
+	}
+	return "", ""
+}